@@ -1,9 +1,14 @@
 package ast
 
 type AtRootStmt struct {
-	Token    *Token
-	Block    *DeclBlock
-	Selector *ComplexSelector
+	Token     *Token
+	Block     *DeclBlock
+	Selectors *ComplexSelectorList
+	Query     *AtRootQuery
+
+	// SelectorTemplate is the selector text containing interpolation, it's
+	// parsed into Selectors once evaluated by the runtime
+	SelectorTemplate Expr
 }
 
 func (stm AtRootStmt) CanBeStmt() {}
@@ -12,8 +17,57 @@ func (stm AtRootStmt) String() string {
 	return stm.Token.String()
 }
 
+// Excludes reports if the rules of the given kind ("rule", "media", ...)
+// are escaped by the @at-root, by default only the style rules are.
+func (stm AtRootStmt) Excludes(name string) bool {
+	if stm.Query == nil {
+		return name == "rule"
+	}
+
+	return stm.Query.Excludes(name)
+}
+
 func NewAtRootStmtWithToken(tok *Token) *AtRootStmt {
 	return &AtRootStmt{
 		Token: tok,
 	}
 }
+
+/*
+AtRootQuery presents the `(with: ...)` and `(without: ...)` queries:
+
+	@at-root (without: media) { ... }
+	@at-root (with: rule) { ... }
+*/
+type AtRootQuery struct {
+	With  bool
+	Names []string
+}
+
+func (q AtRootQuery) Excludes(name string) bool {
+	for _, n := range q.Names {
+		if n == "all" {
+			return !q.With
+		}
+
+		if n == name {
+			return !q.With
+		}
+	}
+
+	return q.With
+}
+
+func (q AtRootQuery) String() (out string) {
+	if q.With {
+		out = "(with:"
+	} else {
+		out = "(without:"
+	}
+
+	for _, n := range q.Names {
+		out += " " + n
+	}
+
+	return out + ")"
+}
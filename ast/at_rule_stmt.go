@@ -0,0 +1,54 @@
+package ast
+
+import "strings"
+
+/*
+AtRuleStmt is an at-rule unknown to Sass, it's written to the output as it
+is once its prelude is evaluated:
+
+	@supports (display: grid) { ... }
+	@custom-rule #{$name};
+*/
+type AtRuleStmt struct {
+	Token *Token
+
+	// Name is the name of the rule without "@"
+	Name string
+
+	// Prelude is the text between the name and the block, nil when empty
+	Prelude Expr
+
+	// Block is nil when the rule has no block: `@foo bar;`
+	Block *DeclBlock
+}
+
+func (stm AtRuleStmt) CanBeStmt() {}
+
+func (stm AtRuleStmt) String() string {
+	if stm.Prelude == nil {
+		return "@" + stm.Name
+	}
+
+	return "@" + stm.Name + " " + stm.Prelude.String()
+}
+
+// IsKeyframes reports if the rule is @keyframes or one of its vendor
+// prefixed versions, e.g. @-webkit-keyframes
+func (stm AtRuleStmt) IsKeyframes() bool {
+	name := strings.ToLower(stm.Name)
+
+	if strings.HasPrefix(name, "-") {
+		if idx := strings.Index(name[1:], "-"); idx >= 0 {
+			name = name[idx+2:]
+		}
+	}
+
+	return name == "keyframes"
+}
+
+func NewAtRuleStmtWithToken(tok *Token) *AtRuleStmt {
+	return &AtRuleStmt{
+		Token: tok,
+		Name:  strings.TrimPrefix(tok.Str, "@"),
+	}
+}
@@ -7,19 +7,23 @@ type CallArgument struct {
 }
 
 func (arg CallArgument) String() string {
+	return arg.StringWithPrecision(DefaultPrecision)
+}
+
+func (arg CallArgument) StringWithPrecision(precision int) string {
 	switch {
 	case arg.Name != nil:
 		var v = "<no value>"
 
 		if arg.Value != nil {
-			v = arg.Value.String()
+			v = StringWithPrecision(arg.Value, precision)
 		}
 
 		return arg.Name.String() + ": " + v
 	case arg.VariableLength:
-		return arg.Value.String() + "..."
+		return StringWithPrecision(arg.Value, precision) + "..."
 	default:
-		return arg.Value.String()
+		return StringWithPrecision(arg.Value, precision)
 	}
 }
 
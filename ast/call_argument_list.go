@@ -7,6 +7,10 @@ type CallArgumentList struct {
 }
 
 func (arg CallArgumentList) String() string {
+	return arg.StringWithPrecision(DefaultPrecision)
+}
+
+func (arg CallArgumentList) StringWithPrecision(precision int) string {
 	var b bytes.Buffer
 
 	idx := 0
@@ -16,7 +20,7 @@ func (arg CallArgumentList) String() string {
 		}
 		idx++
 
-		b.WriteString(a.String())
+		b.WriteString(a.StringWithPrecision(precision))
 	}
 
 	return b.String()
@@ -1,64 +1,493 @@
 package ast
 
-import "strconv"
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
 
-type Hex string
+/*
+Color is the value of all the colors. The colors of the legacy spaces, rgb
+and hsl, keep both their rgb and hsl channels so that a color gives back
+the channels it was created with:
 
-func (hex Hex) String() string {
-	return string(hex)
+	R, G, B = 0~255
+	H = 0~360, S, L = 0~100
+	A = 0~1
+
+The colors of the other spaces keep their channels in Coords, e.g. the
+lightness, the chroma and the hue of oklch(), they are not clamped to a
+gamut.
+
+Format is the text the color was written with, e.g. `red` or `#FFF`, the
+color is printed as written as long as it's not modified. The computed
+colors have no format.
+*/
+type Color struct {
+	R, G, B float64
+	H, S, L float64
+	A       float64
+	Space   *ColorSpace
+	Coords  [3]float64
+
+	// Missing are the channels given as `none`, the last one is the alpha
+	// channel, they're zero in the coordinates
+	Missing [4]bool
+
+	Format string
+	Token  *Token
 }
 
-type Color interface {
-	CanBeColor()
+func (c *Color) CanBeNode() {}
+
+func (c *Color) Boolean() bool {
+	return true
 }
 
-type HexColor struct {
-	Hex   Hex
-	R     uint32
-	G     uint32
-	B     uint32
-	Token *Token
+// NewRGBAColor returns a computed color, the channels are clamped to
+// their bounds
+func NewRGBAColor(r, g, b, a float64, token *Token) *Color {
+	c := &Color{
+		R:     clampChannel(r, 255),
+		G:     clampChannel(g, 255),
+		B:     clampChannel(b, 255),
+		A:     clampChannel(a, 1),
+		Space: ColorSpaces["rgb"],
+		Token: token,
+	}
+
+	c.H, c.S, c.L = RGBToHSL(c.R, c.G, c.B)
+	c.S, c.L = c.S*100, c.L*100
+	return c
 }
 
-func (self HexColor) CanBeNode() {}
+// NewHSLAColor returns a computed color, the hue wraps around and the
+// other channels are clamped to their bounds
+func NewHSLAColor(h, s, l, a float64, token *Token) *Color {
+	h = math.Mod(h, 360)
 
-func (self HexColor) CanBeColor() {}
+	if h < 0 {
+		h += 360
+	}
 
-func (self HexColor) Boolean() bool {
-	return true
+	c := &Color{
+		H:     h,
+		S:     clampChannel(s, 100),
+		L:     clampChannel(l, 100),
+		A:     clampChannel(a, 1),
+		Space: ColorSpaces["hsl"],
+		Token: token,
+	}
+
+	c.R, c.G, c.B = HSLToRGB(c.H, c.S/100, c.L/100)
+	return c
 }
 
-func (self HexColor) String() string {
-	return "#" + string(self.Hex)
+/*
+NewSpaceColor returns a computed color of the space, the lightness is
+clamped and the hue wraps around:
+
+	NewSpaceColor(ColorSpaces["oklch"], [3]float64{0.5, 0.1, 120}, 1, nil) => oklch(50% 0.1 120deg)
+*/
+func NewSpaceColor(space *ColorSpace, coords [3]float64, a float64, token *Token) *Color {
+	switch space.Name {
+	case "rgb":
+		return NewRGBAColor(coords[0], coords[1], coords[2], a, token)
+	case "hsl":
+		return NewHSLAColor(coords[0], coords[1], coords[2], a, token)
+	}
+
+	for idx, ch := range space.Channels {
+		switch {
+		case ch.Hue:
+			coords[idx] = math.Mod(coords[idx], 360)
+
+			if coords[idx] < 0 {
+				coords[idx] += 360
+			}
+		case ch.Name == "lightness":
+			coords[idx] = clampChannel(coords[idx], ch.Max)
+		case ch.Name == "chroma":
+			coords[idx] = math.Max(0, coords[idx])
+		}
+	}
+
+	return &Color{A: clampChannel(a, 1), Space: space, Coords: coords, Token: token}
 }
 
-func NewHexColorFromToken(token *Token) *HexColor {
+func NewHexColorFromToken(token *Token) *Color {
 	return NewHexColor(token.Str, token)
 }
 
-func NewHexColor(hex string, token *Token) *HexColor {
-	var r, g, b, _ = HexToRGBA(hex)
-	return &HexColor{Hex(hex), r, g, b, token}
+// NewHexColor returns the color of the hex code, it's printed as written
+func NewHexColor(hex string, token *Token) *Color {
+	r, g, b, a := HexToRGBA(hex)
+	c := NewRGBAColor(r, g, b, a, token)
+	c.Format = hex
+	return c
+}
+
+// NewColorFromKeyword returns the color of the keyword, the keywords are
+// case insensitive and printed as written: `Red` => `Red`
+func NewColorFromKeyword(name string, token *Token) (*Color, bool) {
+	hex, ok := ColorKeywords[strings.ToLower(name)]
+
+	if !ok {
+		return nil, false
+	}
+
+	c := NewHexColor(hex, token)
+	c.Format = name
+	return c, true
+}
+
+// WithAlpha returns a computed copy of the color with a new alpha channel
+func (c *Color) WithAlpha(a float64) *Color {
+	out := *c
+	out.A = clampChannel(a, 1)
+	out.Format = ""
+	return &out
 }
 
-// HexToRGB converts an Hex string to a RGB triple.
-func HexToRGBA(h string) (uint32, uint32, uint32, float32) {
-	if len(h) > 0 && h[0] == '#' {
-		h = h[1:]
+// Channels returns the channels of the color in its space
+func (c *Color) Channels() [3]float64 {
+	switch c.Space.Name {
+	case "rgb":
+		return [3]float64{c.R, c.G, c.B}
+	case "hsl":
+		return [3]float64{c.H, c.S, c.L}
 	}
-	if len(h) == 3 {
-		// rebuild hex string
-		h = h[:1] + h[:1] + h[1:2] + h[1:2] + h[2:] + h[2:]
+
+	return c.Coords
+}
+
+// convert returns the channels of the color in another space
+func (c *Color) convert(space *ColorSpace) [3]float64 {
+	return convertCoords(c.Space, space, c.Channels())
+}
+
+func convertCoords(from, to *ColorSpace, coords [3]float64) [3]float64 {
+	if from == to {
+		return coords
 	}
-	if len(h) == 6 {
-		if rgb, err := strconv.ParseUint(string(h), 16, 32); err == nil {
-			return uint32(rgb >> 16), uint32((rgb >> 8) & 0xFF), uint32(rgb & 0xFF), 0
+
+	return to.fromXYZ(from.toXYZ(coords))
+}
+
+/*
+ToSpace returns the color converted to the space, the colors converted to
+a legacy space are mapped to its gamut instead of having their channels
+clamped one by one:
+
+	color.to-space(oklch(70% 0.4 30deg), rgb) => #ff5843
+*/
+func (c *Color) ToSpace(space *ColorSpace) *Color {
+	if c.Space == space {
+		return c
+	}
+
+	// the hue of the gray colors is kept between rgb and hsl
+	if c.Space.Legacy && space.Legacy {
+		if space.Name == "hsl" {
+			return NewHSLAColor(c.H, c.S, c.L, c.A, nil)
 		}
+
+		return NewRGBAColor(c.R, c.G, c.B, c.A, nil)
 	}
-	if len(h) == 8 {
-		if rgba, err := strconv.ParseUint(string(h), 16, 32); err == nil {
-			return uint32(rgba >> 24), uint32(rgba >> 16 & 0xFF), uint32((rgba >> 8) & 0xFF), float32(rgba&0xFF) / 255
+
+	src := c
+
+	if space.Legacy {
+		src = c.ToGamut(space, "local-minde")
+	}
+
+	return NewSpaceColor(space, src.convert(space), c.A, nil)
+}
+
+// gamutSpace returns the space whose gamut bounds the space, the legacy
+// spaces are bounded by srgb
+func gamutSpace(space *ColorSpace) *ColorSpace {
+	if space.Legacy {
+		return ColorSpaces["srgb"]
+	}
+
+	return space
+}
+
+func coordsInGamut(space *ColorSpace, coords [3]float64) bool {
+	for idx, ch := range space.Channels {
+		if FuzzyLessThan(coords[idx], ch.Min) || FuzzyLessThan(ch.Max, coords[idx]) {
+			return false
 		}
 	}
-	return 0, 0, 0, 0
+
+	return true
+}
+
+func clipCoords(space *ColorSpace, coords [3]float64) [3]float64 {
+	for idx, ch := range space.Channels {
+		coords[idx] = math.Max(ch.Min, math.Min(ch.Max, coords[idx]))
+	}
+
+	return coords
+}
+
+// IsInGamut tells whether the color is within the gamut of the space, the
+// spaces without a gamut contain all the colors
+func (c *Color) IsInGamut(space *ColorSpace) bool {
+	if !space.Bounded {
+		return true
+	}
+
+	gamut := gamutSpace(space)
+	return coordsInGamut(gamut, c.convert(gamut))
+}
+
+/*
+ToGamut returns the color mapped to the gamut of the space, the color
+stays in its own space. The method is "clip", which clamps the channels,
+or "local-minde", which reduces the chroma in oklch like the css gamut
+mapping algorithm:
+
+	https://www.w3.org/TR/css-color-4/#css-gamut-mapping
+*/
+func (c *Color) ToGamut(space *ColorSpace, method string) *Color {
+	if c.IsInGamut(space) {
+		return c
+	}
+
+	gamut := gamutSpace(space)
+
+	var mapped [3]float64
+
+	if method == "clip" {
+		mapped = clipCoords(gamut, c.convert(gamut))
+	} else {
+		mapped = localMinde(c.convert(ColorSpaces["oklch"]), gamut)
+	}
+
+	return NewSpaceColor(c.Space, convertCoords(gamut, c.Space, mapped), c.A, nil)
+}
+
+// localMinde maps the oklch channels to the gamut, the channels of the
+// gamut space are returned
+func localMinde(origin [3]float64, gamut *ColorSpace) [3]float64 {
+	const jnd, epsilon = 0.02, 0.0001
+
+	oklch := ColorSpaces["oklch"]
+
+	if origin[0] >= 1 {
+		return convertCoords(oklch, gamut, [3]float64{1, 0, 0})
+	}
+
+	if origin[0] <= 0 {
+		return convertCoords(oklch, gamut, [3]float64{0, 0, 0})
+	}
+
+	clip := func(lch [3]float64) [3]float64 {
+		return clipCoords(gamut, convertCoords(oklch, gamut, lch))
+	}
+
+	// deltaEOK is the distance of the colors in oklab
+	deltaEOK := func(clipped, lch [3]float64) float64 {
+		a := convertCoords(gamut, ColorSpaces["oklab"], clipped)
+		b := polarToRectangular(lch)
+		return math.Sqrt(math.Pow(a[0]-b[0], 2) + math.Pow(a[1]-b[1], 2) + math.Pow(a[2]-b[2], 2))
+	}
+
+	current := origin
+	clipped := clip(current)
+
+	if deltaEOK(clipped, current) < jnd {
+		return clipped
+	}
+
+	min, max := 0.0, origin[1]
+	minInGamut := true
+
+	for max-min > epsilon {
+		chroma := (min + max) / 2
+		current[1] = chroma
+
+		if minInGamut && coordsInGamut(gamut, convertCoords(oklch, gamut, current)) {
+			min = chroma
+			continue
+		}
+
+		clipped = clip(current)
+		e := deltaEOK(clipped, current)
+
+		if e >= jnd {
+			max = chroma
+			continue
+		}
+
+		if jnd-e < epsilon {
+			return clipped
+		}
+
+		minInGamut = false
+		min = chroma
+	}
+
+	return clipped
+}
+
+// RGB returns the rgb channels rounded to integers
+func (c *Color) RGB() (r, g, b int) {
+	if !c.Space.Legacy {
+		return c.ToSpace(ColorSpaces["rgb"]).RGB()
+	}
+
+	return roundChannel(c.R), roundChannel(c.G), roundChannel(c.B)
+}
+
+// Hex returns the #rrggbb code of the color, the alpha channel is ignored
+func (c *Color) Hex() string {
+	r, g, b := c.RGB()
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// Name returns the keyword of an opaque color, or an empty string
+func (c *Color) Name() string {
+	if !FuzzyEqual(c.A, 1) {
+		return ""
+	}
+
+	return ColorNames[c.Hex()]
+}
+
+/*
+String returns the color as written, the computed colors are printed like
+sass does:
+
+	rgb(255, 0, 0) => red
+	rgb(1, 2, 3) => #010203
+	rgba(1, 2, 3, 0.5) => rgba(1, 2, 3, 0.5)
+*/
+func (c *Color) String() string {
+	return c.StringWithPrecision(DefaultPrecision)
+}
+
+// StringWithPrecision returns the color, the alpha channel and the channels
+// of the non legacy spaces have the given number of digits after the
+// decimal point at most
+func (c *Color) StringWithPrecision(precision int) string {
+	if c.Format != "" {
+		return c.Format
+	}
+
+	if !c.Space.Legacy {
+		return c.spaceString(precision)
+	}
+
+	if !FuzzyEqual(c.A, 1) {
+		r, g, b := c.RGB()
+		return fmt.Sprintf("rgba(%d, %d, %d, %s)", r, g, b, FormatNumberWithPrecision(c.A, precision))
+	}
+
+	if name := c.Name(); name != "" {
+		return name
+	}
+
+	return c.Hex()
+}
+
+/*
+spaceString returns the css function of the colors of the non legacy
+spaces, the lightness is printed in percents:
+
+	oklch(50% 0.1 120deg)
+	color(display-p3 1 0 0 / 0.5)
+	lab(50% none 10 / none)
+*/
+func (c *Color) spaceString(precision int) string {
+	name := "color"
+
+	var parts []string
+
+	switch c.Space.Name {
+	case "lab", "lch", "oklab", "oklch":
+		name = c.Space.Name
+		parts = append(parts, FormatNumberWithPrecision(c.Coords[0]*100/c.Space.Channels[0].Max, precision)+"%")
+
+		for idx, ch := range c.Space.Channels[1:] {
+			str := FormatNumberWithPrecision(c.Coords[idx+1], precision)
+
+			if ch.Hue {
+				str += "deg"
+			}
+
+			parts = append(parts, str)
+		}
+	default:
+		parts = append(parts, c.Space.Name)
+
+		for _, v := range c.Coords {
+			parts = append(parts, FormatNumberWithPrecision(v, precision))
+		}
+	}
+
+	// the function name is the first part of color()
+	offset := len(parts) - 3
+
+	for idx := range c.Coords {
+		if c.Missing[idx] {
+			parts[offset+idx] = "none"
+		}
+	}
+
+	if c.Missing[3] {
+		parts = append(parts, "/", "none")
+	} else if !FuzzyEqual(c.A, 1) {
+		parts = append(parts, "/", FormatNumberWithPrecision(c.A, precision))
+	}
+
+	return name + "(" + strings.Join(parts, " ") + ")"
+}
+
+func clampChannel(x, max float64) float64 {
+	return math.Max(0, math.Min(max, x))
+}
+
+// roundChannel rounds the channel half up, 127.49999999999 is 127.5
+func roundChannel(x float64) int {
+	if FuzzyLessThan(x-math.Floor(x), 0.5) {
+		return int(math.Floor(x))
+	}
+
+	return int(math.Ceil(x))
+}
+
+/*
+HexToRGBA converts a hex code to the rgb channels and the alpha channel,
+the codes have 3, 4, 6 or 8 digits:
+
+	#f00 => 255, 0, 0, 1
+	#ff000080 => 255, 0, 0, 0.5019607843
+*/
+func HexToRGBA(h string) (r, g, b, a float64) {
+	h = strings.TrimPrefix(h, "#")
+
+	if len(h) == 3 || len(h) == 4 {
+		// rebuild hex string
+		var long string
+		for _, digit := range h {
+			long += string(digit) + string(digit)
+		}
+		h = long
+	}
+
+	if len(h) == 6 {
+		h += "ff"
+	}
+
+	rgba, err := strconv.ParseUint(h, 16, 32)
+
+	if len(h) != 8 || err != nil {
+		return 0, 0, 0, 0
+	}
+
+	return float64(rgba >> 24), float64(rgba >> 16 & 0xFF), float64(rgba >> 8 & 0xFF), float64(rgba&0xFF) / 255
 }
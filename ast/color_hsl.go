@@ -5,71 +5,20 @@ http://stackoverflow.com/questions/2353211/hsl-to-rgb-color-conversion
 */
 
 import (
-	"fmt"
 	"math"
 )
 
-type HSLColor struct {
-	H     float64
-	S     float64
-	L     float64
-	Token *Token
-}
-
-func (self HSLColor) CanBeColor() {}
-func (self HSLColor) CanBeNode()  {}
-func (self HSLColor) HSLAColor() *HSLAColor {
-	return NewHSLAColor(self.H, self.S, self.L, 0, nil)
-
-}
-func (c HSLColor) RGBAColor() *RGBAColor {
-	var r, g, b = HSLToRGB(c.H, c.S, c.L)
-	return NewRGBAColor(uint32(r), uint32(g), uint32(b), 1, nil)
-}
-func (c HSLColor) RGBColor() *RGBColor {
-	var r, g, b = HSLToRGB(c.H, c.S, c.L)
-	return NewRGBColor(uint32(r), uint32(g), uint32(b), nil)
-}
-
-func (self HSLColor) String() string {
-	return fmt.Sprintf("hsl(%G, %G%%, %G%%)", self.H, self.S*100, self.L*100)
-}
-
-func NewHSLColor(h, s, v float64, token *Token) *HSLColor {
-	return &HSLColor{h, s, v, token}
-}
-
-type HSLAColor struct {
-	H     float64
-	S     float64
-	L     float64
-	A     float64
-	Token *Token
-}
-
-func (self HSLAColor) CanBeColor() {}
-func (self HSLAColor) CanBeNode()  {}
-func (self HSLAColor) String() string {
-	return fmt.Sprintf("hsl(%G, %G, %G, %G)", self.H, self.S, self.L, self.A)
-}
-
-func (self HSLAColor) Boolean() bool {
-	return true
-}
-
-func NewHSLAColor(h, s, v, a float64, token *Token) *HSLAColor {
-	return &HSLAColor{h, s, v, a, token}
-}
-
 /*
-h = 0~360
-s = 0~1
-l = 0~1
+HSLToRGB converts the hsl channels to the rgb channels, which are not
+rounded:
+
+	h = 0~360
+	s = 0~1
+	l = 0~1
+	r, g, b = 0~255
 */
-func HSLToRGB(h, s, l float64) (r, g, b uint32) {
-	if h > 0 {
-		h = h / 360
-	}
+func HSLToRGB(h, s, l float64) (r, g, b float64) {
+	h = h / 360
 	var fR, fG, fB float64
 	if s == 0 {
 		fR, fG, fB = l, l, l
@@ -85,10 +34,7 @@ func HSLToRGB(h, s, l float64) (r, g, b uint32) {
 		fG = ConvertHUE(p, q, h)
 		fB = ConvertHUE(p, q, h-1.0/3)
 	}
-	r = uint32((fR * 255) + 0.5)
-	g = uint32((fG * 255) + 0.5)
-	b = uint32((fB * 255) + 0.5)
-	return
+	return fR * 255, fG * 255, fB * 255
 }
 
 func ConvertHUE(p, q, t float64) float64 {
@@ -110,10 +56,14 @@ func ConvertHUE(p, q, t float64) float64 {
 	return p
 }
 
-func RGBToHSL(r, g, b uint32) (h, s, l float64) {
-	fR := float64(r) / 255
-	fG := float64(g) / 255
-	fB := float64(b) / 255
+/*
+RGBToHSL converts the rgb channels (0~255) to the hue in degrees, the
+saturation and the lightness (0~1)
+*/
+func RGBToHSL(r, g, b float64) (h, s, l float64) {
+	fR := r / 255
+	fG := g / 255
+	fB := b / 255
 	max := math.Max(math.Max(fR, fG), fB)
 	min := math.Min(math.Min(fR, fG), fB)
 	l = (max + min) / 2
@@ -139,7 +89,7 @@ func RGBToHSL(r, g, b uint32) (h, s, l float64) {
 		case fB:
 			h = (fR-fG)/d + 4
 		}
-		h /= 6
+		h *= 60
 	}
 	return
 }
@@ -1,41 +1,7 @@
 package ast
 
-import "fmt"
 import "math"
 
-type HSVColor struct {
-	H     float64
-	S     float64
-	V     float64
-	Token *Token
-}
-
-func (self HSVColor) CanBeColor() {}
-func (self HSVColor) CanBeNode()  {}
-
-func (self HSVColor) Boolean() bool {
-	return true
-}
-
-func (c HSVColor) RGBAColor() *RGBAColor {
-	r, g, b := HSVToRGB(c.H, c.S, c.V)
-	return NewRGBAColor(uint32(r), uint32(g), uint32(b), 1, nil)
-}
-
-func (c HSVColor) RGBColor() *RGBColor {
-	r, g, b := HSVToRGB(c.H, c.S, c.V)
-	return NewRGBColor(uint32(r), uint32(g), uint32(b), nil)
-}
-
-// hsv() is not supported in CSS3, we need to convert it to hex color
-func (self HSVColor) String() string {
-	return fmt.Sprintf("hsv(%G, %G, %G)", self.H, self.S, self.V)
-}
-
-func NewHSVColor(h, s, v float64, token *Token) *HSVColor {
-	return &HSVColor{h, s, v, token}
-}
-
 func RGBToHSV(ir, ig, ib uint32) (h, s, v float64) {
 	r := float64(ir) / 255
 	g := float64(ig) / 255
@@ -90,7 +56,6 @@ func HSVToRGB(h, s, v float64) (r, g, b uint32) {
 	case 5:
 		fR, fG, fB = v, p, q
 	}
-	fmt.Printf("%G %G %G\n", fR, fG, fB)
 	r = uint32((fR * 255) + 0.5)
 	g = uint32((fG * 255) + 0.5)
 	b = uint32((fB * 255) + 0.5)
@@ -27,14 +27,16 @@ var ColorKeywords = map[string]string{
 	"blue":    "#0000ff",
 	"teal":    "#008080",
 	"aqua":    "#00ffff",
-	// "CSS": "Level": "2": ("Revision": "1":)	 	"orange":	"#ffa500",
-	// "CSS": "Color": "Module": "Level": "3":	 	"aliceblue":	"#f0f8ff",
+	// CSS Level 2 (Revision 1)
+	"orange": "#ffa500",
+	// CSS Color Module Level 3
+	"aliceblue":            "#f0f8ff",
 	"antiquewhite":         "#faebd7",
 	"aquamarine":           "#7fffd4",
 	"azure":                "#f0ffff",
 	"beige":                "#f5f5dc",
 	"bisque":               "#ffe4c4",
-	"blanchedalmond":       "#ffe4c4",
+	"blanchedalmond":       "#ffebcd",
 	"blueviolet":           "#8a2be2",
 	"brown":                "#a52a2a",
 	"burlywood":            "#deb887",
@@ -45,6 +47,7 @@ var ColorKeywords = map[string]string{
 	"cornflowerblue":       "#6495ed",
 	"cornsilk":             "#fff8dc",
 	"crimson":              "#dc143c",
+	"cyan":                 "#00ffff",
 	"darkblue":             "#00008b",
 	"darkcyan":             "#008b8b",
 	"darkgoldenrod":        "#b8860b",
@@ -105,6 +108,7 @@ var ColorKeywords = map[string]string{
 	"lightyellow":          "#ffffe0",
 	"limegreen":            "#32cd32",
 	"linen":                "#faf0e6",
+	"magenta":              "#ff00ff",
 	"mediumaquamarine":     "#66cdaa",
 	"mediumblue":           "#0000cd",
 	"mediumorchid":         "#ba55d3",
@@ -159,3 +163,18 @@ var ColorKeywords = map[string]string{
 	// CSS Color Module Level 4
 	"rebeccapurple": "#663399",
 }
+
+/*
+ColorNames is the keyword of the colors by their #rrggbb code, the color
+is printed by its keyword once computed: `mix(white, black)` => `gray`.
+The first name in alphabetical order is taken, gray is preferred to grey.
+*/
+var ColorNames = map[string]string{}
+
+func init() {
+	for name, hex := range ColorKeywords {
+		if other, ok := ColorNames[hex]; !ok || name < other {
+			ColorNames[hex] = name
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package ast
+
+/*
+The color spaces of CSS Color Level 4, the conversions go through xyz-d65
+and follow the sample code of the specification:
+
+	https://www.w3.org/TR/css-color-4/#color-conversion-code
+*/
+
+import (
+	"math"
+)
+
+/*
+ColorChannel is a channel of a color space. Min and Max are the bounds of
+the gamut for the bounded spaces, they are the values of 0% and 100% for
+the other ones:
+
+	oklch(50% 0.1 120deg) => lightness 0.5, 50% of 1
+*/
+type ColorChannel struct {
+	Name     string
+	Min, Max float64
+	Hue      bool
+}
+
+type ColorSpace struct {
+	Name     string
+	Channels [3]ColorChannel
+
+	// Legacy is true for rgb and hsl, the colors of the legacy spaces are
+	// printed as hex codes, keywords or rgba()
+	Legacy bool
+
+	// Bounded is true when the space has a gamut
+	Bounded bool
+
+	toXYZ   func(c [3]float64) [3]float64
+	fromXYZ func(c [3]float64) [3]float64
+}
+
+// Polar tells whether the space has a hue channel
+func (space *ColorSpace) Polar() bool {
+	for _, ch := range space.Channels {
+		if ch.Hue {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ChannelIndex returns the index of the channel by its name
+func (space *ColorSpace) ChannelIndex(name string) (int, bool) {
+	for idx, ch := range space.Channels {
+		if ch.Name == name {
+			return idx, true
+		}
+	}
+
+	return -1, false
+}
+
+func (space *ColorSpace) String() string {
+	return space.Name
+}
+
+var (
+	rgbChannels = [3]ColorChannel{{"red", 0, 1, false}, {"green", 0, 1, false}, {"blue", 0, 1, false}}
+	xyzChannels = [3]ColorChannel{{"x", 0, 1, false}, {"y", 0, 1, false}, {"z", 0, 1, false}}
+)
+
+// ColorSpaces are the color spaces by their names
+var ColorSpaces = map[string]*ColorSpace{
+	"rgb": {
+		Name:     "rgb",
+		Channels: [3]ColorChannel{{"red", 0, 255, false}, {"green", 0, 255, false}, {"blue", 0, 255, false}},
+		Legacy:   true,
+		Bounded:  true,
+		toXYZ: func(c [3]float64) [3]float64 {
+			return srgbToXYZ([3]float64{c[0] / 255, c[1] / 255, c[2] / 255})
+		},
+		fromXYZ: func(c [3]float64) [3]float64 {
+			rgb := xyzToSRGB(c)
+			return [3]float64{rgb[0] * 255, rgb[1] * 255, rgb[2] * 255}
+		},
+	},
+	"hsl": {
+		Name:     "hsl",
+		Channels: [3]ColorChannel{{"hue", 0, 360, true}, {"saturation", 0, 100, false}, {"lightness", 0, 100, false}},
+		Legacy:   true,
+		Bounded:  true,
+		toXYZ: func(c [3]float64) [3]float64 {
+			r, g, b := HSLToRGB(c[0], c[1]/100, c[2]/100)
+			return srgbToXYZ([3]float64{r / 255, g / 255, b / 255})
+		},
+		fromXYZ: func(c [3]float64) [3]float64 {
+			rgb := xyzToSRGB(c)
+			h, s, l := RGBToHSL(rgb[0]*255, rgb[1]*255, rgb[2]*255)
+			return [3]float64{h, s * 100, l * 100}
+		},
+	},
+	"srgb": {
+		Name:     "srgb",
+		Channels: rgbChannels,
+		Bounded:  true,
+		toXYZ:    srgbToXYZ,
+		fromXYZ:  xyzToSRGB,
+	},
+	"srgb-linear": {
+		Name:     "srgb-linear",
+		Channels: rgbChannels,
+		Bounded:  true,
+		toXYZ: func(c [3]float64) [3]float64 {
+			return multiplyMatrix(linearSRGBToXYZ, c)
+		},
+		fromXYZ: func(c [3]float64) [3]float64 {
+			return multiplyMatrix(xyzToLinearSRGB, c)
+		},
+	},
+	"display-p3": {
+		Name:     "display-p3",
+		Channels: rgbChannels,
+		Bounded:  true,
+		toXYZ: func(c [3]float64) [3]float64 {
+			return multiplyMatrix(linearP3ToXYZ, linearize(c))
+		},
+		fromXYZ: func(c [3]float64) [3]float64 {
+			return gammaEncode(multiplyMatrix(xyzToLinearP3, c))
+		},
+	},
+	"xyz": {
+		Name:     "xyz",
+		Channels: xyzChannels,
+		toXYZ:    func(c [3]float64) [3]float64 { return c },
+		fromXYZ:  func(c [3]float64) [3]float64 { return c },
+	},
+	"xyz-d50": {
+		Name:     "xyz-d50",
+		Channels: xyzChannels,
+		toXYZ: func(c [3]float64) [3]float64 {
+			return multiplyMatrix(d50ToD65, c)
+		},
+		fromXYZ: func(c [3]float64) [3]float64 {
+			return multiplyMatrix(d65ToD50, c)
+		},
+	},
+	"lab": {
+		Name:     "lab",
+		Channels: [3]ColorChannel{{"lightness", 0, 100, false}, {"a", -125, 125, false}, {"b", -125, 125, false}},
+		toXYZ:    labToXYZ,
+		fromXYZ:  xyzToLab,
+	},
+	"lch": {
+		Name:     "lch",
+		Channels: [3]ColorChannel{{"lightness", 0, 100, false}, {"chroma", 0, 150, false}, {"hue", 0, 360, true}},
+		toXYZ: func(c [3]float64) [3]float64 {
+			return labToXYZ(polarToRectangular(c))
+		},
+		fromXYZ: func(c [3]float64) [3]float64 {
+			return rectangularToPolar(xyzToLab(c))
+		},
+	},
+	"oklab": {
+		Name:     "oklab",
+		Channels: [3]ColorChannel{{"lightness", 0, 1, false}, {"a", -0.4, 0.4, false}, {"b", -0.4, 0.4, false}},
+		toXYZ:    oklabToXYZ,
+		fromXYZ:  xyzToOklab,
+	},
+	"oklch": {
+		Name:     "oklch",
+		Channels: [3]ColorChannel{{"lightness", 0, 1, false}, {"chroma", 0, 0.4, false}, {"hue", 0, 360, true}},
+		toXYZ: func(c [3]float64) [3]float64 {
+			return oklabToXYZ(polarToRectangular(c))
+		},
+		fromXYZ: func(c [3]float64) [3]float64 {
+			return rectangularToPolar(xyzToOklab(c))
+		},
+	},
+}
+
+// LookupColorSpace returns the space by its name, xyz-d65 is xyz
+func LookupColorSpace(name string) (*ColorSpace, bool) {
+	if name == "xyz-d65" {
+		name = "xyz"
+	}
+
+	space, ok := ColorSpaces[name]
+	return space, ok
+}
+
+type matrix [3][3]float64
+
+func multiplyMatrix(m matrix, c [3]float64) [3]float64 {
+	return [3]float64{
+		m[0][0]*c[0] + m[0][1]*c[1] + m[0][2]*c[2],
+		m[1][0]*c[0] + m[1][1]*c[1] + m[1][2]*c[2],
+		m[2][0]*c[0] + m[2][1]*c[1] + m[2][2]*c[2],
+	}
+}
+
+var (
+	linearSRGBToXYZ = matrix{
+		{506752.0 / 1228815, 87881.0 / 245763, 12673.0 / 70218},
+		{87098.0 / 409605, 175762.0 / 245763, 12673.0 / 175545},
+		{7918.0 / 409605, 87881.0 / 737289, 1001167.0 / 1053270},
+	}
+	xyzToLinearSRGB = matrix{
+		{12831.0 / 3959, -329.0 / 214, -1974.0 / 3959},
+		{-851781.0 / 878810, 1648619.0 / 878810, 36519.0 / 878810},
+		{705.0 / 12673, -2585.0 / 12673, 705.0 / 667},
+	}
+	linearP3ToXYZ = matrix{
+		{608311.0 / 1250200, 189793.0 / 714400, 198249.0 / 1000160},
+		{35783.0 / 156275, 247089.0 / 357200, 198249.0 / 2500400},
+		{0, 32229.0 / 714400, 5220557.0 / 5000800},
+	}
+	xyzToLinearP3 = matrix{
+		{446124.0 / 178915, -333277.0 / 357830, -72051.0 / 178915},
+		{-14852.0 / 17905, 63121.0 / 35810, 423.0 / 17905},
+		{11844.0 / 330415, -50337.0 / 660830, 316169.0 / 330415},
+	}
+	d65ToD50 = matrix{
+		{1.0479297925449969, 0.022946870601609652, -0.05019226628920524},
+		{0.02962780877005599, 0.9904344267538799, -0.017073799063418826},
+		{-0.009243040646204504, 0.015055191490298152, 0.7518742814281371},
+	}
+	d50ToD65 = matrix{
+		{0.955473421488075, -0.02309845494876471, 0.06325924320057072},
+		{-0.0283697093338637, 1.0099953980813041, 0.021041441191917323},
+		{0.012314014864481998, -0.020507649298898964, 1.330365926242124},
+	}
+	xyzToLMS = matrix{
+		{0.8190224379967030, 0.3619062600528904, -0.1288737815209879},
+		{0.0329836539323885, 0.9292868615863434, 0.0361446663506424},
+		{0.0481771893596242, 0.2642395317527308, 0.6335478284694309},
+	}
+	lmsToXYZ = matrix{
+		{1.2268798758459243, -0.5578149944602171, 0.2813910456659647},
+		{-0.0405757452148008, 1.1122868032803170, -0.0717110580655164},
+		{-0.0763729366746601, -0.4214933324022432, 1.5869240198367816},
+	}
+	lmsToOklab = matrix{
+		{0.2104542683093140, 0.7936177747023054, -0.0040720430116193},
+		{1.9779985324311684, -2.4285922420485799, 0.4505937096174110},
+		{0.0259040424655478, 0.7827717124575296, -0.8086757549230774},
+	}
+	oklabToLMS = matrix{
+		{1, 0.3963377773761749, 0.2158037573099136},
+		{1, -0.1055613458156586, -0.0638541728258133},
+		{1, -0.0894841775298119, -1.2914855480194092},
+	}
+
+	// the white point of lab
+	d50White = [3]float64{0.3457 / 0.3585, 1, (1 - 0.3457 - 0.3585) / 0.3585}
+)
+
+// linearize removes the gamma encoding of srgb and display-p3, the sign
+// is kept for the values out of the gamut
+func linearize(c [3]float64) [3]float64 {
+	var out [3]float64
+
+	for idx, v := range c {
+		abs := math.Abs(v)
+
+		if abs <= 0.04045 {
+			out[idx] = v / 12.92
+		} else {
+			out[idx] = math.Copysign(math.Pow((abs+0.055)/1.055, 2.4), v)
+		}
+	}
+
+	return out
+}
+
+func gammaEncode(c [3]float64) [3]float64 {
+	var out [3]float64
+
+	for idx, v := range c {
+		abs := math.Abs(v)
+
+		if abs > 0.0031308 {
+			out[idx] = math.Copysign(1.055*math.Pow(abs, 1/2.4)-0.055, v)
+		} else {
+			out[idx] = 12.92 * v
+		}
+	}
+
+	return out
+}
+
+func srgbToXYZ(c [3]float64) [3]float64 {
+	return multiplyMatrix(linearSRGBToXYZ, linearize(c))
+}
+
+func xyzToSRGB(c [3]float64) [3]float64 {
+	return gammaEncode(multiplyMatrix(xyzToLinearSRGB, c))
+}
+
+const (
+	labEpsilon = 216.0 / 24389
+	labKappa   = 24389.0 / 27
+)
+
+func xyzToLab(c [3]float64) [3]float64 {
+	d50 := multiplyMatrix(d65ToD50, c)
+
+	var f [3]float64
+
+	for idx, v := range d50 {
+		v = v / d50White[idx]
+
+		if v > labEpsilon {
+			f[idx] = math.Cbrt(v)
+		} else {
+			f[idx] = (labKappa*v + 16) / 116
+		}
+	}
+
+	return [3]float64{116*f[1] - 16, 500 * (f[0] - f[1]), 200 * (f[1] - f[2])}
+}
+
+func labToXYZ(c [3]float64) [3]float64 {
+	f1 := (c[0] + 16) / 116
+	f0 := c[1]/500 + f1
+	f2 := f1 - c[2]/200
+
+	xyz := [3]float64{(116*f0 - 16) / labKappa, c[0] / labKappa, (116*f2 - 16) / labKappa}
+
+	if math.Pow(f0, 3) > labEpsilon {
+		xyz[0] = math.Pow(f0, 3)
+	}
+
+	if c[0] > labKappa*labEpsilon {
+		xyz[1] = math.Pow(f1, 3)
+	}
+
+	if math.Pow(f2, 3) > labEpsilon {
+		xyz[2] = math.Pow(f2, 3)
+	}
+
+	for idx := range xyz {
+		xyz[idx] *= d50White[idx]
+	}
+
+	return multiplyMatrix(d50ToD65, xyz)
+}
+
+func xyzToOklab(c [3]float64) [3]float64 {
+	lms := multiplyMatrix(xyzToLMS, c)
+
+	for idx, v := range lms {
+		lms[idx] = math.Cbrt(v)
+	}
+
+	return multiplyMatrix(lmsToOklab, lms)
+}
+
+func oklabToXYZ(c [3]float64) [3]float64 {
+	lms := multiplyMatrix(oklabToLMS, c)
+
+	for idx, v := range lms {
+		lms[idx] = v * v * v
+	}
+
+	return multiplyMatrix(lmsToXYZ, lms)
+}
+
+// rectangularToPolar converts lab to lch, the hue of a gray color is 0
+func rectangularToPolar(c [3]float64) [3]float64 {
+	chroma := math.Hypot(c[1], c[2])
+	hue := math.Atan2(c[2], c[1]) * 180 / math.Pi
+
+	if hue < 0 {
+		hue += 360
+	}
+
+	if FuzzyEqual(chroma, 0) {
+		hue = 0
+	}
+
+	return [3]float64{c[0], chroma, hue}
+}
+
+func polarToRectangular(c [3]float64) [3]float64 {
+	hue := c[2] * math.Pi / 180
+	return [3]float64{c[0], c[1] * math.Cos(hue), c[1] * math.Sin(hue)}
+}
@@ -1,5 +1,6 @@
 package ast
 
+import "math"
 import "testing"
 import "github.com/stretchr/testify/assert"
 
@@ -11,53 +12,95 @@ func TestNewRGBAColor(t *testing.T) {
 func TestNewRGBAColor2(t *testing.T) {
 	var c = NewRGBAColor(10, 20, 30, 0.22, nil)
 	assert.Equal(t, "rgba(10, 20, 30, 0.22)", c.String())
-	assert.Equal(t, Hex("#0A141E"), c.Hex())
+	assert.Equal(t, "#0a141e", c.Hex())
 }
 
-func TestNewRGBColor(t *testing.T) {
-	var c = NewRGBColor(10, 20, 30, nil)
-	assert.Equal(t, "rgb(10, 20, 30)", c.String())
-	assert.Equal(t, Hex("#0A141E"), c.Hex())
+func TestNewRGBAColorOpaque(t *testing.T) {
+	assert.Equal(t, "#0a141e", NewRGBAColor(10, 20, 30, 1, nil).String())
+	assert.Equal(t, "red", NewRGBAColor(255, 0, 0, 1, nil).String())
+	assert.Equal(t, "gray", NewRGBAColor(127.5, 127.5, 127.5, 1, nil).String())
 }
 
 func TestNewHexColor(t *testing.T) {
 	var c = NewHexColor("#ffffff", nil)
-	assert.Equal(t, uint32(255), c.R)
-	assert.Equal(t, uint32(255), c.G)
-	assert.Equal(t, uint32(255), c.B)
-	assert.Equal(t, c.Hex, Hex("#ffffff"))
+	assert.Equal(t, 255.0, c.R)
+	assert.Equal(t, 255.0, c.G)
+	assert.Equal(t, 255.0, c.B)
+	assert.Equal(t, 1.0, c.A)
+	assert.Equal(t, "#ffffff", c.String())
+
+	c = NewHexColor("#FFF", nil)
+	assert.Equal(t, "#FFF", c.String())
+	assert.Equal(t, "white", c.WithAlpha(1).String())
+}
+
+func TestNewColorFromKeyword(t *testing.T) {
+	c, ok := NewColorFromKeyword("Red", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "Red", c.String())
+	assert.Equal(t, "#ff0000", c.Hex())
+
+	_, ok = NewColorFromKeyword("bold", nil)
+	assert.False(t, ok)
+}
+
+func TestColorNamesPreferGray(t *testing.T) {
+	assert.Equal(t, "gray", ColorNames["#808080"])
+	assert.Equal(t, "aqua", ColorNames["#00ffff"])
+	assert.Equal(t, "fuchsia", ColorNames["#ff00ff"])
 }
 
 func TestHex6CharToRGBA(t *testing.T) {
 	var r, g, b, a = HexToRGBA("#FF2030")
-	assert.Equal(t, uint32(255), r)
-	assert.Equal(t, uint32(32), g)
-	assert.Equal(t, uint32(48), b)
-	assert.Equal(t, float32(0.0), a)
+	assert.Equal(t, 255.0, r)
+	assert.Equal(t, 32.0, g)
+	assert.Equal(t, 48.0, b)
+	assert.Equal(t, 1.0, a)
+}
+
+func TestHex8CharToRGBA(t *testing.T) {
+	var r, g, b, a = HexToRGBA("#FF203080")
+	assert.Equal(t, 255.0, r)
+	assert.Equal(t, 32.0, g)
+	assert.Equal(t, 48.0, b)
+	assert.InDelta(t, 0.5, a, 0.01)
+}
+
+func TestHex4CharToRGBA(t *testing.T) {
+	var r, g, b, a = HexToRGBA("#f008")
+	assert.Equal(t, 255.0, r)
+	assert.Equal(t, 0.0, g)
+	assert.Equal(t, 0.0, b)
+	assert.InDelta(t, 0.53, a, 0.01)
 }
 
 func TestHSLColorRedToRGB(t *testing.T) {
 	// hsl(0,  100%,50%) = red
-	var c = NewHSLColor(0, 1, 0.5, nil)
-	assert.NotNil(t, c)
-	assert.Equal(t, "rgb(255, 0, 0)", c.RGBColor().String())
+	var c = NewHSLAColor(0, 100, 50, 1, nil)
+	assert.Equal(t, "red", c.String())
 }
 
 func TestHSLColorGreenToRGB(t *testing.T) {
-	// hsl(120,100%,50%) = green
-	var c = NewHSLColor(120, 1, 0.5, nil)
-	assert.NotNil(t, c)
-	assert.Equal(t, "rgb(0, 255, 0)", c.RGBColor().String())
+	// hsl(120,100%,50%) = lime
+	var c = NewHSLAColor(120, 100, 50, 1, nil)
+	assert.Equal(t, "lime", c.String())
 }
 
-func TestHSVColorRedToRGB(t *testing.T) {
-	var c = NewHSVColor(0, 1, 1, nil)
-	assert.Equal(t, "rgb(255, 0, 0)", c.RGBColor().String())
+func TestHSLColorKeepsItsChannels(t *testing.T) {
+	// the hue of a gray color is lost once converted to rgb
+	var c = NewHSLAColor(-60, 0, 40, 1, nil)
+	assert.Equal(t, 300.0, c.H)
+	assert.Equal(t, 0.0, c.S)
+	assert.Equal(t, 40.0, c.L)
+	assert.Equal(t, "#666666", c.String())
+
+	var rgb = NewRGBAColor(c.R, c.G, c.B, c.A, nil)
+	assert.Equal(t, c.Hex(), rgb.Hex())
 }
 
 func TestHSVColorGreenToRGB(t *testing.T) {
-	var c = NewHSVColor(120, 0.5, 1, nil)
-	assert.Equal(t, "rgb(128, 255, 128)", c.RGBColor().String())
+	var r, g, b = HSVToRGB(120, 0.5, 1)
+	assert.Equal(t, []uint32{128, 255, 128}, []uint32{r, g, b})
 
 	var h, s, v = RGBToHSV(0, 255, 0)
 	assert.Equal(t, 120.0, h)
@@ -65,11 +108,42 @@ func TestHSVColorGreenToRGB(t *testing.T) {
 	assert.Equal(t, 1.0, v)
 }
 
-func TestHex8CharToRGBA(t *testing.T) {
-	var r, g, b, a = HexToRGBA("#FF203040")
-	assert.Equal(t, uint32(255), r)
-	assert.Equal(t, uint32(32), g)
-	assert.Equal(t, uint32(48), b)
-	// float32 is not precise, hence use NotEqual here.
-	assert.NotEqual(t, float32(0.0), a)
+func TestColorToSpace(t *testing.T) {
+	red := NewRGBAColor(255, 0, 0, 1, nil)
+
+	oklch := red.ToSpace(ColorSpaces["oklch"])
+	assert.Equal(t, "oklch(62.7955363921% 0.2576833038 29.2338802796deg)", oklch.String())
+	assert.Equal(t, "red", oklch.ToSpace(ColorSpaces["rgb"]).String())
+
+	lab := red.ToSpace(ColorSpaces["lab"])
+	assert.InDelta(t, 54.29, lab.Coords[0], 0.01)
+	assert.InDelta(t, 80.80, lab.Coords[1], 0.01)
+	assert.InDelta(t, 69.89, lab.Coords[2], 0.01)
+	assert.Equal(t, "#ff0000", lab.Hex())
+}
+
+func TestColorSpaceString(t *testing.T) {
+	c := NewSpaceColor(ColorSpaces["display-p3"], [3]float64{1, 0.5, 0}, 0.5, nil)
+	assert.Equal(t, "color(display-p3 1 0.5 0 / 0.5)", c.String())
+
+	c = NewSpaceColor(ColorSpaces["oklch"], [3]float64{1.5, -0.1, -90}, 1, nil)
+	assert.Equal(t, "oklch(100% 0 270deg)", c.String())
+
+	c = NewSpaceColor(ColorSpaces["display-p3"], [3]float64{1, 0, 0}, 0, nil)
+	c.Missing = [4]bool{false, true, false, true}
+	assert.Equal(t, "color(display-p3 1 none 0 / none)", c.String())
+}
+
+func TestColorToGamut(t *testing.T) {
+	c := NewSpaceColor(ColorSpaces["oklch"], [3]float64{0.7, 0.4, 30}, 1, nil)
+	assert.False(t, c.IsInGamut(ColorSpaces["rgb"]))
+	assert.True(t, c.IsInGamut(ColorSpaces["oklch"]))
+
+	mapped := c.ToGamut(ColorSpaces["rgb"], "local-minde")
+	assert.Equal(t, ColorSpaces["oklch"], mapped.Space)
+	assert.True(t, mapped.IsInGamut(ColorSpaces["rgb"]))
+	assert.Equal(t, 30.0, math.Round(mapped.Coords[2]))
+
+	clipped := c.ToGamut(ColorSpaces["srgb"], "clip")
+	assert.Equal(t, "red", clipped.ToSpace(ColorSpaces["rgb"]).String())
 }
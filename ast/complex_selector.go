@@ -98,6 +98,12 @@ func JoinSelectors(parent, child *ComplexSelector) (*ComplexSelector, error) {
 		// if we have found a parent selector we should:
 		// - make a copy of parent selector
 		parentCopy := parent.Clone()
+		// - add the suffix of `&--suffix` to the last selector of the parent
+		if suffix := (*sel.CompoundSelector)[0].(*ParentSelector).Suffix; suffix != "" {
+			if err := appendSelectorSuffix(parentCopy.ComplexSelectorItems[len(parentCopy.ComplexSelectorItems)-1].CompoundSelector, suffix); err != nil {
+				return nil, err
+			}
+		}
 		// - append remaining compound selector items to the last child of parent
 		parentCopy.ComplexSelectorItems[0].Combinator = sel.Combinator
 		parentCopy.ComplexSelectorItems[len(parentCopy.ComplexSelectorItems)-1].CompoundSelector.Append((*sel.CompoundSelector)[1:]...)
@@ -155,3 +161,28 @@ func JoinSelectors(parent, child *ComplexSelector) (*ComplexSelector, error) {
 		ComplexSelectorItems: outItems,
 	}, nil
 }
+
+// appendSelectorSuffix appends the suffix to the name of the last simple
+// selector of the compound selector, e.g. `.a` and `--b` give `.a--b`
+func appendSelectorSuffix(compound *CompoundSelector, suffix string) error {
+	idx := len(*compound) - 1
+
+	if idx < 0 {
+		return fmt.Errorf("Parent \"%s\" is incompatible with this selector.", compound)
+	}
+
+	switch t := (*compound)[idx].(type) {
+	case *TypeSelector:
+		(*compound)[idx] = NewTypeSelector(t.Type + suffix)
+	case *ClassSelector:
+		(*compound)[idx] = NewClassSelector(t.ClassName + suffix)
+	case *IdSelector:
+		(*compound)[idx] = NewIdSelector(t.Id + suffix)
+	case *PlaceholderSelector:
+		(*compound)[idx] = NewPlaceholderSelector(t.Name + suffix)
+	default:
+		return fmt.Errorf("Parent \"%s\" is incompatible with this selector.", compound)
+	}
+
+	return nil
+}
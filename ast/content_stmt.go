@@ -1,7 +1,8 @@
 package ast
 
 type ContentStmt struct {
-	Token *Token
+	Token     *Token
+	Arguments *CallArgumentList // @content($a, $b), if any
 }
 
 func (stm ContentStmt) CanBeStmt() {}
@@ -11,5 +12,5 @@ func (stm ContentStmt) String() string {
 }
 
 func NewContentStmtWithToken(tok *Token) *ContentStmt {
-	return &ContentStmt{tok}
+	return &ContentStmt{Token: tok}
 }
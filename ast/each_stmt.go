@@ -0,0 +1,32 @@
+package ast
+
+import "strings"
+
+/*
+EachStmt iterates over the items of a list or the key/value pairs of a map.
+
+	@each $item in $list {  }
+
+	@each $key, $value in $map {  }
+*/
+type EachStmt struct {
+	Variables []*Variable
+	Expr      Expr
+	Block     *DeclBlock
+}
+
+func (stm EachStmt) CanBeStmt() {}
+
+func (stm EachStmt) String() string {
+	var names []string
+	for _, v := range stm.Variables {
+		names = append(names, v.String())
+	}
+	return "@each " + strings.Join(names, ", ") + " in " + stm.Expr.String() + " {  }\n"
+}
+
+func NewEachStmt(variables []*Variable) *EachStmt {
+	return &EachStmt{
+		Variables: variables,
+	}
+}
@@ -0,0 +1,89 @@
+package ast
+
+/*
+Equal tells whether two values are equal in the sense of sass `==`,
+quoted and unquoted strings with the same text are equal while numbers
+must have compatible units:
+
+	"a" == a
+	1px != 1
+	1in == 96px
+	red == #ff0000
+*/
+func Equal(av Value, bv Value) bool {
+	switch a := av.(type) {
+	case *Number:
+		b, ok := bv.(*Number)
+
+		if !ok || a.IsUnitless() != b.IsUnitless() {
+			return false
+		}
+
+		value, ok := b.ValueIn(a)
+		return ok && FuzzyEqual(a.Value, value)
+
+	case *String:
+		b, ok := bv.(*String)
+		return ok && a.Value == b.Value
+
+	case *Color:
+		b, ok := bv.(*Color)
+
+		if !ok || !FuzzyEqual(a.A, b.A) {
+			return false
+		}
+
+		if a.Space.Legacy && b.Space.Legacy {
+			return a.Hex() == b.Hex()
+		}
+
+		// the colors of the other spaces are equal in the same space only
+		ca, cb := a.Channels(), b.Channels()
+		return a.Space == b.Space && FuzzyEqual(ca[0], cb[0]) && FuzzyEqual(ca[1], cb[1]) && FuzzyEqual(ca[2], cb[2])
+
+	case *Boolean:
+		b, ok := bv.(*Boolean)
+		return ok && a.Value == b.Value
+
+	case *Null:
+		_, ok := bv.(*Null)
+		return ok
+
+	case *List:
+		b, ok := bv.(*List)
+
+		if !ok || a.Bracketed != b.Bracketed || len(a.Exprs) != len(b.Exprs) {
+			return false
+		}
+
+		if len(a.Exprs) > 1 && a.Separator != b.Separator {
+			return false
+		}
+
+		for idx := range a.Exprs {
+			if !Equal(a.Exprs[idx], b.Exprs[idx]) {
+				return false
+			}
+		}
+
+		return true
+
+	case *Map:
+		// the order of the items doesn't matter
+		b, ok := bv.(*Map)
+
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+
+		for _, item := range a.Items {
+			if val := b.Get(item.Key); val == nil || !Equal(item.Value, val) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return av.String() == bv.String()
+}
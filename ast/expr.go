@@ -53,43 +53,6 @@ func (self BinaryExpr) IsSimpleExpr() bool {
 	return ok1 || ok2
 }
 
-/*
-*
-The the divide expression will only be evaluated in the following 3 conditions:
-
- 1. If the value, or any part of it, is stored in a variable or returned by a function.
- 2. If the value is surrounded by parentheses.
- 3. If the value is used as part of another arithmetic expression.
-
-This method needs to be called on the top caller to prevent unexpected result.
-
-@see http://sass-lang.com/documentation/file.SASS_REFERENCE.html#division-and-slash
-*/
-func (self *BinaryExpr) IsCssSlash() bool {
-	if self.Op.Type == T_DIV {
-		_, aok := self.Left.(*Number)
-		_, bok := self.Right.(*Number)
-
-		// it's not grouped, we should inflate it as string
-		if aok && bok && !self.Grouped {
-			return true
-		}
-	}
-
-	// we also can't divide if any part of the division is a string
-	if self.Op.Type == T_DIV {
-		_, aok := self.Left.(*String)
-		_, bok := self.Right.(*String)
-
-		// it's not grouped, we should inflate it as string
-		if aok || bok {
-			return true
-		}
-	}
-	// otherwise we can divide the value
-	return false
-}
-
 func NewBinaryExpr(op *Op, left Expr, right Expr, grouped bool) *BinaryExpr {
 	return &BinaryExpr{op, left, right, grouped}
 }
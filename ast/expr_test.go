@@ -9,3 +9,13 @@ func TestBinaryExprString(t *testing.T) {
 	var expr = NewBinaryExpr(NewOp(T_DIV), num1, num2, false)
 	assert.Equal(t, "2px/3px", expr.String())
 }
+
+func TestListStringBrackets(t *testing.T) {
+	var l = NewSpaceSepList()
+	l.Append(NewString(0, "a", nil))
+	l.Append(NewString(0, "b", nil))
+	assert.Equal(t, "a b", l.String())
+
+	l.Bracketed = true
+	assert.Equal(t, "[a b]", l.String())
+}
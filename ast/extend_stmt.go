@@ -1,7 +1,13 @@
 package ast
 
 type ExtendStmt struct {
+	Token     *Token
 	Selectors *ComplexSelectorList
+	Optional  bool // @extend .foo !optional;
+
+	// SelectorTemplate is the selector text containing interpolation, see
+	// RuleSet
+	SelectorTemplate Expr
 }
 
 func (stm ExtendStmt) CanBeStmt()     {}
@@ -10,3 +16,7 @@ func (stm ExtendStmt) String() string { return "@extend" }
 func NewExtendStmt() *ExtendStmt {
 	return &ExtendStmt{}
 }
+
+func NewExtendStmtWithToken(tok *Token) *ExtendStmt {
+	return &ExtendStmt{Token: tok}
+}
@@ -0,0 +1,54 @@
+package ast
+
+import "strings"
+
+/*
+For re-exporting the members of a module:
+
+@forward "src/list";                       // => list.$gap
+@forward "src/list" as list-*;             // => list.$list-gap
+@forward "src/list" hide list-reset, $gap; // => everything but list-reset and $gap
+@forward "src/list" show list-*;           // => only the members starting with list-
+*/
+type ForwardStmt struct {
+	SourceFileName string
+	Path           *String
+
+	// Prefix is added to the names of all the forwarded members
+	Prefix string
+
+	// Show and Hide are the member names, variables start with '$'.
+	// Names ending with '*' match all the members with that prefix.
+	Show []string
+	Hide []string
+}
+
+func NewForwardStmt(sourceFileName string) *ForwardStmt {
+	return &ForwardStmt{
+		SourceFileName: sourceFileName,
+	}
+}
+
+func (self ForwardStmt) CanBeStmt() {}
+
+func (self ForwardStmt) String() string {
+	var b strings.Builder
+	b.WriteString("@forward ")
+	b.WriteString(self.Path.String())
+
+	if self.Prefix != "" {
+		b.WriteString(" as " + self.Prefix + "*")
+	}
+
+	if len(self.Show) > 0 {
+		b.WriteString(" show " + strings.Join(self.Show, ", "))
+	}
+
+	if len(self.Hide) > 0 {
+		b.WriteString(" hide " + strings.Join(self.Hide, ", "))
+	}
+
+	b.WriteString(";")
+
+	return b.String()
+}
@@ -1,11 +1,18 @@
 package ast
 
+import "strings"
+
 type Function struct {
 	Ident        *Token
 	ArgumentList *ArgumentList
 	Block        *Block
 }
 
+// sass spec assumes that fn_name and fn-name mean the same
+func (f Function) NormalizedName() string {
+	return strings.ReplaceAll(f.Ident.Str, "-", "_")
+}
+
 func (f Function) CanBeStmt()     {}
 func (f Function) String() string { return "Function.String() is unimplemented." }
 
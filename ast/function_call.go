@@ -1,13 +1,24 @@
 package ast
 
+import "strings"
+
 type FunctionCall struct {
 	Ident     *Token
 	Arguments *CallArgumentList
 }
 
+// sass spec assumes that fn_name and fn-name mean the same
+func (self FunctionCall) NormalizedName() string {
+	return strings.ReplaceAll(self.Ident.Str, "-", "_")
+}
+
 func (self FunctionCall) CanBeNode() {}
 func (self FunctionCall) String() (out string) {
-	return self.Ident.Str + "(" + self.Arguments.String() + ")"
+	return self.StringWithPrecision(DefaultPrecision)
+}
+
+func (self FunctionCall) StringWithPrecision(precision int) (out string) {
+	return self.Ident.Str + "(" + self.Arguments.StringWithPrecision(precision) + ")"
 }
 
 func NewFunctionCallWithToken(token *Token) *FunctionCall {
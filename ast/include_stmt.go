@@ -7,6 +7,10 @@ type IncludeStmt struct {
 	MixinIdent   *Token // mixin identitfier
 	ArgumentList *CallArgumentList
 	ContentBlock *DeclBlock // if any
+
+	// ContentArguments is the argument prototype declared with
+	// `@include foo using ($a, $b) { ... }`
+	ContentArguments *ArgumentList
 }
 
 // sass spec assumes that $var_name and $var-name mean the same
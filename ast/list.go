@@ -5,6 +5,16 @@ import "strings"
 type List struct {
 	Separator string
 	Exprs     []Expr
+
+	// Bracketed is set for the lists written with square brackets,
+	// e.g. `[full-start]`, the brackets are kept in the output
+	Bracketed bool
+
+	// Keywords are set for the lists of the variable length arguments,
+	// they hold the named arguments that are not declared by the
+	// function, e.g. `$b: 2` for `@function f($args...)` called as
+	// `f(1, $b: 2)`
+	Keywords *Map
 }
 
 /*
@@ -19,11 +29,22 @@ func (self List) GetValueType() ValueType {
 }
 
 func (list List) String() string {
+	return list.StringWithPrecision(DefaultPrecision)
+}
+
+// StringWithPrecision returns the list, the numbers are written with the
+// given number of digits after the decimal point at most
+func (list List) StringWithPrecision(precision int) string {
 	var exprstrs []string
 	for _, expr := range list.Exprs {
-		exprstrs = append(exprstrs, expr.String())
+		exprstrs = append(exprstrs, StringWithPrecision(expr, precision))
 	}
-	return "[" + strings.Join(exprstrs, list.Separator) + "]"
+
+	if list.Bracketed {
+		return "[" + strings.Join(exprstrs, list.Separator) + "]"
+	}
+
+	return strings.Join(exprstrs, list.Separator)
 }
 
 func (list *List) Len() int {
@@ -37,13 +58,13 @@ func (list *List) Append(expr Expr) {
 
 // By the default, the separator is space
 func NewList(sep string) *List {
-	return &List{sep, []Expr{}}
+	return &List{Separator: sep, Exprs: []Expr{}}
 }
 
 func NewSpaceSepList() *List {
-	return &List{" ", []Expr{}}
+	return &List{Separator: " ", Exprs: []Expr{}}
 }
 
 func NewCommaSepList() *List {
-	return &List{", ", []Expr{}}
+	return &List{Separator: ", ", Exprs: []Expr{}}
 }
@@ -1,26 +1,68 @@
 package ast
 
+import "strings"
+
 type MapItem struct {
 	Key   Expr
 	Value Expr
 }
 
+// Map keeps its items in insertion order, the keys are compared by value
+// so `1px` and `"1px"` are different keys while `a` and `"a"` are the same
 type Map struct {
 	Items []*MapItem
-	Map   map[string]Expr
 }
 
+// Set replaces the value of the key or appends a new item when the key
+// is not in the map yet
 func (self *Map) Set(key Expr, val Expr) {
-	var item = &MapItem{key, val}
-	self.Items = append(self.Items, item)
+	if item := self.item(key); item != nil {
+		item.Value = val
+		return
+	}
 
-	// XXX: fixme later.
-	self.Map[key.String()] = val
+	self.Items = append(self.Items, &MapItem{key, val})
 }
 
 func (self *Map) Get(key Expr) Expr {
-	if val, ok := self.Map[key.String()]; ok {
-		return val
+	if item := self.item(key); item != nil {
+		return item.Value
+	}
+	return nil
+}
+
+func (self *Map) Has(key Expr) bool {
+	return self.item(key) != nil
+}
+
+func (self *Map) Delete(key Expr) {
+	for idx, item := range self.Items {
+		if Equal(item.Key, key) {
+			self.Items = append(self.Items[:idx:idx], self.Items[idx+1:]...)
+			return
+		}
+	}
+}
+
+func (self *Map) Len() int {
+	return len(self.Items)
+}
+
+// Copy returns a shallow copy of the map, the items can be changed
+// without touching the original map
+func (self *Map) Copy() *Map {
+	var m = NewMap()
+	for _, item := range self.Items {
+		m.Items = append(m.Items, &MapItem{item.Key, item.Value})
+	}
+	return m
+}
+
+func (self *Map) item(key Expr) *MapItem {
+	for _, item := range self.Items {
+		if Equal(item.Key, key) {
+			return item
+		}
 	}
 	return nil
 }
@@ -30,12 +72,15 @@ func (self Map) GetValueType() ValueType {
 }
 
 func (self Map) String() string {
-	return "{map}"
+	var items []string
+	for _, item := range self.Items {
+		items = append(items, item.Key.String()+": "+item.Value.String())
+	}
+	return "(" + strings.Join(items, ", ") + ")"
 }
 
 func NewMap() *Map {
 	return &Map{
 		Items: []*MapItem{},
-		Map:   map[string]Expr{},
 	}
 }
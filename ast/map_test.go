@@ -0,0 +1,47 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestMapKeysByValue(t *testing.T) {
+	var m = NewMap()
+	m.Set(NewNumber(1, NewUnit(T_UNIT_PX, nil), nil), NewString(0, "a", nil))
+	m.Set(NewString('"', "1px", nil), NewString(0, "b", nil))
+	m.Set(NewString(0, "c", nil), NewString(0, "c", nil))
+	m.Set(NewString('"', "c", nil), NewString(0, "d", nil))
+
+	assert.Equal(t, 3, m.Len())
+	assert.Equal(t, "a", m.Get(NewNumber(1, NewUnit(T_UNIT_PX, nil), nil)).String())
+	assert.Equal(t, "b", m.Get(NewString(0, "1px", nil)).String())
+	assert.Equal(t, "d", m.Get(NewString(0, "c", nil)).String())
+	assert.Nil(t, m.Get(NewNumber(1, nil, nil)))
+}
+
+func TestMapKeepsInsertionOrder(t *testing.T) {
+	var m = NewMap()
+	for _, key := range []string{"z", "a", "m"} {
+		m.Set(NewString(0, key, nil), NewNumber(1, nil, nil))
+	}
+
+	m.Set(NewString(0, "a", nil), NewNumber(2, nil, nil))
+	m.Delete(NewString(0, "z", nil))
+
+	assert.Equal(t, "(a: 2, m: 1)", m.String())
+}
+
+func TestEqualLists(t *testing.T) {
+	var a = NewCommaSepList()
+	a.Append(NewString(0, "a", nil))
+	a.Append(NewString(0, "b", nil))
+
+	var b = NewSpaceSepList()
+	b.Append(NewString(0, "a", nil))
+	b.Append(NewString(0, "b", nil))
+
+	assert.True(t, Equal(a, a))
+	assert.False(t, Equal(a, b))
+
+	b.Separator = ", "
+	b.Bracketed = true
+	assert.False(t, Equal(a, b))
+}
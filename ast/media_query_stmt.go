@@ -24,6 +24,11 @@ func NewMediaQueryStmt() *MediaQueryStmt {
 }
 
 func (stm MediaQueryStmt) String() (out string) {
+	// the query of `@media { ... }` is empty
+	if stm.MediaQueryList == nil || len(stm.MediaQueryList.List) == 0 {
+		return ""
+	}
+
 	for _, mediaQuery := range stm.MediaQueryList.List {
 		out += ", " + mediaQuery.String()
 	}
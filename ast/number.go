@@ -1,16 +1,223 @@
 package ast
 
-import "strconv"
+import (
+	"math"
+	"strconv"
+	"strings"
+)
 
+/*
+DefaultPrecision is the number of digits written after the decimal point of
+the numbers, the numbers closer than 10^-(DefaultPrecision+1) are considered
+equal:
+
+	1.1041666666666667 => 1.1041666667
+	0.30000000000000004 == 0.3
+*/
+const DefaultPrecision = 10
+
+var epsilon = Epsilon(DefaultPrecision)
+
+// Epsilon returns the difference under which the numbers are equal with the
+// given number of digits after the decimal point
+func Epsilon(precision int) float64 {
+	return math.Pow(10, -float64(precision)-1)
+}
+
+// FuzzyEqual tells whether the numbers are equal within the precision
+func FuzzyEqual(a, b float64) bool {
+	return math.Abs(a-b) < epsilon
+}
+
+// FuzzyLessThan tells whether a is less than b and not fuzzy equal to b
+func FuzzyLessThan(a, b float64) bool {
+	return a < b && !FuzzyEqual(a, b)
+}
+
+// FuzzyLessThanOrEqual tells whether a is less than or fuzzy equal to b
+func FuzzyLessThanOrEqual(a, b float64) bool {
+	return a < b || FuzzyEqual(a, b)
+}
+
+/*
+FuzzyInt returns the integer the value is fuzzy equal to, ok is false when
+the value isn't an integer:
+
+	2.00000000001 => 2, true
+	2.5 => 0, false
+*/
+func FuzzyInt(v float64) (int, bool) {
+	rounded := math.Round(v)
+
+	if !FuzzyEqual(v, rounded) {
+		return 0, false
+	}
+
+	return int(rounded), true
+}
+
+/*
+FormatNumber returns the value with DefaultPrecision digits after the
+decimal point at most, without trailing zeros and without exponent:
+
+	0.00001 => 0.00001
+	1e21 => 1000000000000000000000
+	1.1041666666666667 => 1.1041666667
+*/
+func FormatNumber(v float64) string {
+	return FormatNumberWithPrecision(v, DefaultPrecision)
+}
+
+// FormatNumberWithPrecision returns the value with the given number of
+// digits after the decimal point at most, like FormatNumber
+func FormatNumberWithPrecision(v float64, precision int) string {
+	if math.IsNaN(v) {
+		return "NaN"
+	}
+
+	if math.IsInf(v, 0) {
+		if v < 0 {
+			return "-Infinity"
+		}
+		return "Infinity"
+	}
+
+	if rounded := math.Round(v); math.Abs(v-rounded) < Epsilon(precision) {
+		// avoid -0
+		if rounded == 0 {
+			rounded = 0
+		}
+		return strconv.FormatFloat(rounded, 'f', -1, 64)
+	}
+
+	out := strconv.FormatFloat(v, 'f', precision, 64)
+
+	if strings.Contains(out, ".") {
+		out = strings.TrimRight(out, "0")
+		out = strings.TrimSuffix(out, ".")
+	}
+
+	if out == "-0" {
+		return "0"
+	}
+
+	return out
+}
+
+// PrecisionStringer is implemented by the values containing numbers, which
+// are written with a given number of digits after the decimal point
+type PrecisionStringer interface {
+	StringWithPrecision(precision int) string
+}
+
+// StringWithPrecision returns the text of the expression, its numbers have
+// the given number of digits after the decimal point at most
+func StringWithPrecision(expr Expr, precision int) string {
+	if s, ok := expr.(PrecisionStringer); ok {
+		return s.StringWithPrecision(precision)
+	}
+
+	return expr.String()
+}
+
+/*
+Number is a number with an optional compound unit, the unit is the product
+of the numerator units divided by the product of the denominator units:
+
+	1px => [px] / []
+	1px*px/em => [px px] / [em]
+*/
 type Number struct {
-	Value  float64
-	double bool
-	Unit   *Unit
-	Token  *Token
+	Value        float64
+	double       bool
+	Numerators   []*Unit
+	Denominators []*Unit
+	Token        *Token
+
+	// Slash is set for the numbers written as a slash separated value,
+	// e.g. `font: 12px/1.5`, the number is printed as it was written
+	Slash *Slash
+}
+
+// Slash holds the operands of a slash separated number
+type Slash struct {
+	Left  *Number
+	Right *Number
 }
 
 func NewNumber(num float64, unit *Unit, token *Token) *Number {
-	return &Number{num, false, unit, token}
+	if unit == nil {
+		return &Number{Value: num, Token: token}
+	}
+	return &Number{Value: num, Numerators: []*Unit{unit}, Token: token}
+}
+
+/*
+NewNumberWithUnits returns a number with the compound unit, the compatible
+units of the numerators and the denominators are cancelled:
+
+	NewNumberWithUnits(2, [px px], [px]) => 2px
+	NewNumberWithUnits(1, [in], [px]) => 96
+*/
+func NewNumberWithUnits(num float64, numerators []*Unit, denominators []*Unit) *Number {
+	var nums []*Unit
+	var dens = append([]*Unit{}, denominators...)
+
+	for _, unit := range numerators {
+		cancelled := false
+
+		for idx, den := range dens {
+			if factor, ok := UnitConversionFactor(unit, den); ok {
+				num *= factor
+				dens = append(dens[:idx], dens[idx+1:]...)
+				cancelled = true
+				break
+			}
+		}
+
+		if !cancelled {
+			nums = append(nums, unit)
+		}
+	}
+
+	if len(dens) == 0 {
+		dens = nil
+	}
+
+	return &Number{Value: num, Numerators: nums, Denominators: dens}
+}
+
+/*
+WithValue returns a number with the same unit as num
+*/
+func (num *Number) WithValue(value float64) *Number {
+	return &Number{Value: value, Numerators: num.Numerators, Denominators: num.Denominators}
+}
+
+/*
+WithSlash returns a copy of the number printed as the slash separated
+operands:
+
+	NewNumber(4, px, nil).WithSlash(8px, 2) => 8px/2
+*/
+func (num *Number) WithSlash(left, right *Number) *Number {
+	slashed := *num
+	slashed.Slash = &Slash{Left: left, Right: right}
+	return &slashed
+}
+
+/*
+WithoutSlash returns the number as the result of the division of its
+slash separated operands
+*/
+func (num *Number) WithoutSlash() *Number {
+	if num.Slash == nil {
+		return num
+	}
+
+	divided := *num
+	divided.Slash = nil
+	return &divided
 }
 
 /*
@@ -27,15 +234,107 @@ func (num *Number) IsDouble() bool {
 	return num.double
 }
 
+/*
+IsUnitless tells whether the number has no unit at all
+*/
+func (num *Number) IsUnitless() bool {
+	return len(num.Numerators) == 0 && len(num.Denominators) == 0
+}
+
+/*
+HasUnit tells whether the unit of the number is the simple unit of the type
+*/
+func (num *Number) HasUnit(unitType TokenType) bool {
+	return len(num.Numerators) == 1 && len(num.Denominators) == 0 && num.Numerators[0].Type == unitType
+}
+
+/*
+IsCompound tells whether the unit of the number isn't a simple unit, such
+numbers are not valid CSS values:
+
+	1px*px, 1px/em, 1px^-1
+*/
+func (num *Number) IsCompound() bool {
+	return len(num.Numerators) > 1 || len(num.Denominators) > 0
+}
+
+/*
+UnitString returns the unit of the number like sass does, an empty string
+is returned for numbers without unit:
+
+	px, px*px, px/em, px^-1, (px*em)^-1
+*/
+func (num *Number) UnitString() string {
+	nums := joinUnits(num.Numerators)
+	dens := joinUnits(num.Denominators)
+
+	switch {
+	case dens == "":
+		return nums
+	case nums != "":
+		return nums + "/" + dens
+	case len(num.Denominators) == 1:
+		return dens + "^-1"
+	}
+
+	return "(" + dens + ")^-1"
+}
+
+func joinUnits(units []*Unit) string {
+	names := make([]string, len(units))
+
+	for idx, unit := range units {
+		names[idx] = unit.String()
+	}
+
+	return strings.Join(names, "*")
+}
+
+/*
+ValueIn returns the value of the number converted to the unit of other, ok
+is false when the units are incompatible. Numbers without unit are
+compatible with any number:
+
+	1in in 1px => 96
+	1px/s in 1px/ms => 0.001
+*/
+func (num *Number) ValueIn(other *Number) (value float64, ok bool) {
+	if num.IsUnitless() || other.IsUnitless() {
+		return num.Value, true
+	}
+
+	factor, ok := UnitsConversionFactor(num.Numerators, other.Numerators)
+
+	if !ok {
+		return 0, false
+	}
+
+	denFactor, ok := UnitsConversionFactor(num.Denominators, other.Denominators)
+
+	if !ok {
+		return 0, false
+	}
+
+	return num.Value * factor / denFactor, true
+}
+
 func (self Number) GetValueType() ValueType {
 	return NumberValue
 }
 
 func (self Number) String() (out string) {
-	out += strconv.FormatFloat(self.Value, 'G', -1, 64)
-	if self.Unit != nil {
-		out += self.Unit.String()
+	return self.StringWithPrecision(DefaultPrecision)
+}
+
+// StringWithPrecision returns the number with the given number of digits
+// after the decimal point at most
+func (self Number) StringWithPrecision(precision int) (out string) {
+	if self.Slash != nil {
+		return self.Slash.Left.StringWithPrecision(precision) + "/" + self.Slash.Right.StringWithPrecision(precision)
 	}
+
+	out += FormatNumberWithPrecision(self.Value, precision)
+	out += self.UnitString()
 	return out
 }
 
@@ -43,7 +342,14 @@ func (num Number) Double() float64 {
 	return num.Value
 }
 
+/*
+Integer returns the value as an integer, the values fuzzy equal to an
+integer are rounded while the others are truncated
+*/
 func (num Number) Integer() int {
+	if i, ok := FuzzyInt(num.Value); ok {
+		return i
+	}
 	return int(num.Value)
 }
 
@@ -0,0 +1,37 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestFormatNumber(t *testing.T) {
+	assert.Equal(t, "0.00001", FormatNumber(0.00001))
+	assert.Equal(t, "1000000000000000000000", FormatNumber(1e21))
+	assert.Equal(t, "1.1041666667", FormatNumber(1.1041666666666667))
+	assert.Equal(t, "0.3", FormatNumber(0.1+0.2))
+	assert.Equal(t, "0", FormatNumber(-0.00000000001))
+	assert.Equal(t, "-1.5", FormatNumber(-1.5))
+	assert.Equal(t, "30", FormatNumber(30))
+}
+
+func TestFormatNumberWithPrecision(t *testing.T) {
+	assert.Equal(t, "0.333", FormatNumberWithPrecision(1.0/3, 3))
+	assert.Equal(t, "2", FormatNumberWithPrecision(1.99999, 3))
+	assert.Equal(t, "0.333px", NewNumber(1.0/3, NewUnit(T_UNIT_PX, nil), nil).StringWithPrecision(3))
+	assert.Equal(t, "1", FormatNumberWithPrecision(1.00001, 3))
+
+	list := &List{Separator: " ", Exprs: []Expr{NewNumber(1.0/3, nil, nil), NewNumber(2, nil, nil)}}
+	assert.Equal(t, "0.333 2", StringWithPrecision(list, 3))
+}
+
+func TestFuzzyInt(t *testing.T) {
+	i, ok := FuzzyInt(2.00000000000001)
+	assert.True(t, ok)
+	assert.Equal(t, 2, i)
+
+	_, ok = FuzzyInt(2.5)
+	assert.False(t, ok)
+
+	assert.Equal(t, 3, NewNumber(2.99999999999999, nil, nil).Integer())
+	assert.True(t, FuzzyLessThanOrEqual(0.1+0.2, 0.3))
+	assert.False(t, FuzzyLessThan(0.3, 0.1+0.2))
+}
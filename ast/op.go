@@ -29,6 +29,8 @@ func OpTokenName(tokType TokenType) string {
 		return ")"
 	case T_NOP:
 		return ""
+	case T_LOGICAL_AND:
+		return "and"
 	}
 	panic("Unsupported token type")
 	///XXX return ""
@@ -1,16 +1,21 @@
 package ast
 
+import "strings"
+
 /*
 This is a SCSS only selector
 */
 type ParentSelector struct {
-	Token *Token
+	// Suffix is appended to the parent selector, e.g. `--active` for
+	// `&--active`
+	Suffix string
+	Token  *Token
 }
 
 func (self ParentSelector) String() string {
-	return "&"
+	return "&" + self.Suffix
 }
 
 func NewParentSelectorWithToken(token *Token) *ParentSelector {
-	return &ParentSelector{token}
+	return &ParentSelector{strings.TrimPrefix(token.Str, "&"), token}
 }
@@ -16,6 +16,12 @@ type Property struct {
 		`padding: 3px 3px;`
 	*/
 	Values []Expr
+
+	// Block contains the nested properties, which are prefixed with the
+	// name of the property:
+	//
+	//	font: { family: x; } => font-family: x;
+	Block *DeclBlock
 }
 
 /*
@@ -46,6 +52,10 @@ type PropertyName struct {
 	// If there is an interpolation in the property name
 	Interpolation bool
 	Token         *Token
+
+	// Template is the name containing interpolation, it's evaluated by the
+	// runtime
+	Template Expr
 }
 
 func (self PropertyName) String() string {
@@ -53,9 +63,9 @@ func (self PropertyName) String() string {
 }
 
 func NewPropertyName(tok *Token) *PropertyName {
-	return &PropertyName{tok.Str, tok.ContainsInterpolation, tok}
+	return &PropertyName{Name: tok.Str, Interpolation: tok.ContainsInterpolation, Token: tok}
 }
 
 func NewProperty(nameTok *Token) *Property {
-	return &Property{NewPropertyName(nameTok), []Expr{}}
+	return &Property{Name: NewPropertyName(nameTok), Values: []Expr{}}
 }
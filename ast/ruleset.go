@@ -4,7 +4,12 @@ import "fmt"
 
 type RuleSet struct {
 	Selectors *ComplexSelectorList
-	Block     *DeclBlock
+
+	// SelectorTemplate is the selector text containing interpolation, it's
+	// parsed into Selectors once evaluated by the runtime
+	SelectorTemplate Expr
+
+	Block *DeclBlock
 }
 
 func NewRuleSet() *RuleSet {
@@ -1,5 +1,7 @@
 package ast
 
+import "strings"
+
 /*
 *
 @see http://www.w3.org/TR/CSS21/grammar.html
@@ -67,6 +69,26 @@ func NewClassSelector(className string) *ClassSelector {
 	return &ClassSelector{className, nil}
 }
 
+/*
+PlaceholderSelector is a SCSS only selector, it's only used with @extend and
+never emitted by itself
+*/
+type PlaceholderSelector struct {
+	Name  string
+	Token *Token
+}
+
+func (self PlaceholderSelector) String() string    { return self.Name }
+func (self PlaceholderSelector) CSSString() string { return self.Name }
+
+func NewPlaceholderSelectorWithToken(token *Token) *PlaceholderSelector {
+	return &PlaceholderSelector{token.Str, token}
+}
+
+func NewPlaceholderSelector(name string) *PlaceholderSelector {
+	return &PlaceholderSelector{name, nil}
+}
+
 type AttributeSelector struct {
 	Name    *Token
 	Match   *Token
@@ -116,8 +138,14 @@ type PseudoSelector struct {
 func (self PseudoSelector) String() (out string)    { return ":" + self.PseudoClass }
 func (self PseudoSelector) CSSString() (out string) { return ":" + self.PseudoClass }
 
+// the token contains the leading colon, e.g. ':hover' or '::before'
 func NewPseudoSelectorWithToken(token *Token) *PseudoSelector {
-	return &PseudoSelector{token.Str, token}
+	return &PseudoSelector{strings.TrimPrefix(token.Str, ":"), token}
+}
+
+// IsElement reports if the selector is a pseudo element, e.g. '::before'
+func (self PseudoSelector) IsElement() bool {
+	return strings.HasPrefix(self.PseudoClass, ":")
 }
 
 /*
@@ -139,5 +167,5 @@ func (self FunctionalPseudoSelector) String() (out string) {
 func (self FunctionalPseudoSelector) CSSString() string { return self.String() }
 
 func NewFunctionalPseudoSelectorWithToken(token *Token) *FunctionalPseudoSelector {
-	return &FunctionalPseudoSelector{token.Str, "", token}
+	return &FunctionalPseudoSelector{strings.TrimPrefix(token.Str, ":"), "", token}
 }
@@ -38,6 +38,8 @@ var KeywordList = []KeywordToken{
 	{"@else", T_ELSE},
 	{"@if", T_IF},
 	{"@import", T_IMPORT},
+	{"@use", T_USE},
+	{"@forward", T_FORWARD},
 	{"@media", T_MEDIA},
 	{"@page", T_PAGE},
 	{"@return", T_RETURN},
@@ -136,11 +138,12 @@ func (tok Token) IsSelectorCombinator() bool {
 func (tok Token) IsSelector() bool {
 	switch tok.Type {
 	case T_TYPE_SELECTOR, T_UNIVERSAL_SELECTOR, T_ID_SELECTOR,
-		T_CLASS_SELECTOR, T_PARENT_SELECTOR,
+		T_CLASS_SELECTOR, T_PARENT_SELECTOR, T_PLACEHOLDER_SELECTOR,
 		T_ADJACENT_SIBLING_COMBINATOR, T_GENERAL_SIBLING_COMBINATOR,
 		T_CHILD_COMBINATOR, T_DESCENDANT_COMBINATOR,
 		T_PSEUDO_SELECTOR,
 		T_FUNCTIONAL_PSEUDO,
+		T_INTERPOLATION_SELECTOR,
 		T_BRACKET_OPEN: // '[' is the first token of attribute selector.
 		return true
 	}
@@ -224,9 +227,10 @@ const (
 	T_CLASS_SELECTOR
 	T_TYPE_SELECTOR
 	T_UNIVERSAL_SELECTOR
-	T_PARENT_SELECTOR   // SASS parent selector
-	T_PSEUDO_SELECTOR   // :hover, :visited , ...
-	T_FUNCTIONAL_PSEUDO // lang(...), nth(...)
+	T_PARENT_SELECTOR      // SASS parent selector
+	T_PSEUDO_SELECTOR      // :hover, :visited , ...
+	T_FUNCTIONAL_PSEUDO    // lang(...), nth(...)
+	T_PLACEHOLDER_SELECTOR // SASS placeholder selector: %foo
 
 	/*
 		An interpolation selector token presents one or two more selector strings,
@@ -329,6 +333,8 @@ const (
 	T_VARIABLE_LENGTH_ARGUMENTS // for '...'
 
 	T_IMPORT
+	T_USE
+	T_FORWARD
 	T_AT_RULE
 
 	T_CHARSET
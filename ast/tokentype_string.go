@@ -33,127 +33,130 @@ func _() {
 	_ = x[T_PARENT_SELECTOR-22]
 	_ = x[T_PSEUDO_SELECTOR-23]
 	_ = x[T_FUNCTIONAL_PSEUDO-24]
-	_ = x[T_INTERPOLATION_SELECTOR-25]
-	_ = x[T_LITERAL_CONCAT-26]
-	_ = x[T_CONCAT-27]
-	_ = x[T_MS_PROGID-28]
-	_ = x[T_DESCENDANT_COMBINATOR-29]
-	_ = x[T_CHILD_COMBINATOR-30]
-	_ = x[T_ADJACENT_SIBLING_COMBINATOR-31]
-	_ = x[T_GENERAL_SIBLING_COMBINATOR-32]
-	_ = x[T_UNICODE_RANGE-33]
-	_ = x[T_IF-34]
-	_ = x[T_ELSE-35]
-	_ = x[T_ELSE_IF-36]
-	_ = x[T_INCLUDE-37]
-	_ = x[T_EACH-38]
-	_ = x[T_WHEN-39]
-	_ = x[T_MIXIN-40]
-	_ = x[T_EXTEND-41]
-	_ = x[T_FUNCTION-42]
-	_ = x[T_AT_ROOT-43]
-	_ = x[T_WARN-44]
-	_ = x[T_ERROR-45]
-	_ = x[T_DEBUG-46]
-	_ = x[T_FOR-47]
-	_ = x[T_FOR_FROM-48]
-	_ = x[T_FOR_THROUGH-49]
-	_ = x[T_FOR_TO-50]
-	_ = x[T_FOR_IN-51]
-	_ = x[T_WHILE-52]
-	_ = x[T_RETURN-53]
-	_ = x[T_RANGE-54]
-	_ = x[T_CONTENT-55]
-	_ = x[T_FLAG_GLOBAL-56]
-	_ = x[T_FLAG_DEFAULT-57]
-	_ = x[T_FLAG_IMPORTANT-58]
-	_ = x[T_FLAG_OPTIONAL-59]
-	_ = x[T_FONT_FACE-60]
-	_ = x[T_NAMESPACE-61]
-	_ = x[T_LOGICAL_NOT-62]
-	_ = x[T_LOGICAL_OR-63]
-	_ = x[T_LOGICAL_AND-64]
-	_ = x[T_LOGICAL_XOR-65]
-	_ = x[T_NOP-66]
-	_ = x[T_PLUS-67]
-	_ = x[T_DIV-68]
-	_ = x[T_MUL-69]
-	_ = x[T_MINUS-70]
-	_ = x[T_MOD-71]
-	_ = x[T_BRACE_OPEN-72]
-	_ = x[T_BRACE_CLOSE-73]
-	_ = x[T_LANG_CODE-74]
-	_ = x[T_BRACKET_OPEN-75]
-	_ = x[T_ATTRIBUTE_NAME-76]
-	_ = x[T_BRACKET_CLOSE-77]
-	_ = x[T_EQUAL-78]
-	_ = x[T_UNEQUAL-79]
-	_ = x[T_GT-80]
-	_ = x[T_LT-81]
-	_ = x[T_GE-82]
-	_ = x[T_LE-83]
-	_ = x[T_ASSIGN-84]
-	_ = x[T_ATTR_EQUAL-85]
-	_ = x[T_INCLUDE_MATCH-86]
-	_ = x[T_PREFIX_MATCH-87]
-	_ = x[T_DASH_MATCH-88]
-	_ = x[T_SUFFIX_MATCH-89]
-	_ = x[T_SUBSTRING_MATCH-90]
-	_ = x[T_VARIABLE-91]
-	_ = x[T_VARIABLE_LENGTH_ARGUMENTS-92]
-	_ = x[T_IMPORT-93]
-	_ = x[T_AT_RULE-94]
-	_ = x[T_CHARSET-95]
-	_ = x[T_QQ_STRING-96]
-	_ = x[T_Q_STRING-97]
-	_ = x[T_UNQUOTE_STRING-98]
-	_ = x[T_PAREN_OPEN-99]
-	_ = x[T_PAREN_CLOSE-100]
-	_ = x[T_FLAG_CONSTANT-101]
-	_ = x[T_INTEGER-102]
-	_ = x[T_FLOAT-103]
-	_ = x[T_CDOPEN-104]
-	_ = x[T_CDCLOSE-105]
-	_ = x[T_UNIT_NONE-106]
-	_ = x[T_UNIT_OTHERS-107]
-	_ = x[T_UNIT_PERCENT-108]
-	_ = x[T_UNIT_SECOND-109]
-	_ = x[T_UNIT_MILLISECOND-110]
-	_ = x[T_UNIT_EM-111]
-	_ = x[T_UNIT_EX-112]
-	_ = x[T_UNIT_CH-113]
-	_ = x[T_UNIT_REM-114]
-	_ = x[T_UNIT_CM-115]
-	_ = x[T_UNIT_IN-116]
-	_ = x[T_UNIT_MM-117]
-	_ = x[T_UNIT_PC-118]
-	_ = x[T_UNIT_PT-119]
-	_ = x[T_UNIT_PX-120]
-	_ = x[T_UNIT_VH-121]
-	_ = x[T_UNIT_VW-122]
-	_ = x[T_UNIT_VMIN-123]
-	_ = x[T_UNIT_VMAX-124]
-	_ = x[T_UNIT_HZ-125]
-	_ = x[T_UNIT_KHZ-126]
-	_ = x[T_UNIT_DPI-127]
-	_ = x[T_UNIT_DPCM-128]
-	_ = x[T_UNIT_DPPX-129]
-	_ = x[T_UNIT_DEG-130]
-	_ = x[T_UNIT_GRAD-131]
-	_ = x[T_UNIT_RAD-132]
-	_ = x[T_UNIT_TURN-133]
-	_ = x[T_PROPERTY_NAME_TOKEN-134]
-	_ = x[T_PROPERTY_VALUE-135]
-	_ = x[T_HEX_COLOR-136]
-	_ = x[T_COLON-137]
-	_ = x[T_INTERPOLATION_START-138]
-	_ = x[T_INTERPOLATION_INNER-139]
-	_ = x[T_INTERPOLATION_END-140]
+	_ = x[T_PLACEHOLDER_SELECTOR-25]
+	_ = x[T_INTERPOLATION_SELECTOR-26]
+	_ = x[T_LITERAL_CONCAT-27]
+	_ = x[T_CONCAT-28]
+	_ = x[T_MS_PROGID-29]
+	_ = x[T_DESCENDANT_COMBINATOR-30]
+	_ = x[T_CHILD_COMBINATOR-31]
+	_ = x[T_ADJACENT_SIBLING_COMBINATOR-32]
+	_ = x[T_GENERAL_SIBLING_COMBINATOR-33]
+	_ = x[T_UNICODE_RANGE-34]
+	_ = x[T_IF-35]
+	_ = x[T_ELSE-36]
+	_ = x[T_ELSE_IF-37]
+	_ = x[T_INCLUDE-38]
+	_ = x[T_EACH-39]
+	_ = x[T_WHEN-40]
+	_ = x[T_MIXIN-41]
+	_ = x[T_EXTEND-42]
+	_ = x[T_FUNCTION-43]
+	_ = x[T_AT_ROOT-44]
+	_ = x[T_WARN-45]
+	_ = x[T_ERROR-46]
+	_ = x[T_DEBUG-47]
+	_ = x[T_FOR-48]
+	_ = x[T_FOR_FROM-49]
+	_ = x[T_FOR_THROUGH-50]
+	_ = x[T_FOR_TO-51]
+	_ = x[T_FOR_IN-52]
+	_ = x[T_WHILE-53]
+	_ = x[T_RETURN-54]
+	_ = x[T_RANGE-55]
+	_ = x[T_CONTENT-56]
+	_ = x[T_FLAG_GLOBAL-57]
+	_ = x[T_FLAG_DEFAULT-58]
+	_ = x[T_FLAG_IMPORTANT-59]
+	_ = x[T_FLAG_OPTIONAL-60]
+	_ = x[T_FONT_FACE-61]
+	_ = x[T_NAMESPACE-62]
+	_ = x[T_LOGICAL_NOT-63]
+	_ = x[T_LOGICAL_OR-64]
+	_ = x[T_LOGICAL_AND-65]
+	_ = x[T_LOGICAL_XOR-66]
+	_ = x[T_NOP-67]
+	_ = x[T_PLUS-68]
+	_ = x[T_DIV-69]
+	_ = x[T_MUL-70]
+	_ = x[T_MINUS-71]
+	_ = x[T_MOD-72]
+	_ = x[T_BRACE_OPEN-73]
+	_ = x[T_BRACE_CLOSE-74]
+	_ = x[T_LANG_CODE-75]
+	_ = x[T_BRACKET_OPEN-76]
+	_ = x[T_ATTRIBUTE_NAME-77]
+	_ = x[T_BRACKET_CLOSE-78]
+	_ = x[T_EQUAL-79]
+	_ = x[T_UNEQUAL-80]
+	_ = x[T_GT-81]
+	_ = x[T_LT-82]
+	_ = x[T_GE-83]
+	_ = x[T_LE-84]
+	_ = x[T_ASSIGN-85]
+	_ = x[T_ATTR_EQUAL-86]
+	_ = x[T_INCLUDE_MATCH-87]
+	_ = x[T_PREFIX_MATCH-88]
+	_ = x[T_DASH_MATCH-89]
+	_ = x[T_SUFFIX_MATCH-90]
+	_ = x[T_SUBSTRING_MATCH-91]
+	_ = x[T_VARIABLE-92]
+	_ = x[T_VARIABLE_LENGTH_ARGUMENTS-93]
+	_ = x[T_IMPORT-94]
+	_ = x[T_USE-95]
+	_ = x[T_FORWARD-96]
+	_ = x[T_AT_RULE-97]
+	_ = x[T_CHARSET-98]
+	_ = x[T_QQ_STRING-99]
+	_ = x[T_Q_STRING-100]
+	_ = x[T_UNQUOTE_STRING-101]
+	_ = x[T_PAREN_OPEN-102]
+	_ = x[T_PAREN_CLOSE-103]
+	_ = x[T_FLAG_CONSTANT-104]
+	_ = x[T_INTEGER-105]
+	_ = x[T_FLOAT-106]
+	_ = x[T_CDOPEN-107]
+	_ = x[T_CDCLOSE-108]
+	_ = x[T_UNIT_NONE-109]
+	_ = x[T_UNIT_OTHERS-110]
+	_ = x[T_UNIT_PERCENT-111]
+	_ = x[T_UNIT_SECOND-112]
+	_ = x[T_UNIT_MILLISECOND-113]
+	_ = x[T_UNIT_EM-114]
+	_ = x[T_UNIT_EX-115]
+	_ = x[T_UNIT_CH-116]
+	_ = x[T_UNIT_REM-117]
+	_ = x[T_UNIT_CM-118]
+	_ = x[T_UNIT_IN-119]
+	_ = x[T_UNIT_MM-120]
+	_ = x[T_UNIT_PC-121]
+	_ = x[T_UNIT_PT-122]
+	_ = x[T_UNIT_PX-123]
+	_ = x[T_UNIT_VH-124]
+	_ = x[T_UNIT_VW-125]
+	_ = x[T_UNIT_VMIN-126]
+	_ = x[T_UNIT_VMAX-127]
+	_ = x[T_UNIT_HZ-128]
+	_ = x[T_UNIT_KHZ-129]
+	_ = x[T_UNIT_DPI-130]
+	_ = x[T_UNIT_DPCM-131]
+	_ = x[T_UNIT_DPPX-132]
+	_ = x[T_UNIT_DEG-133]
+	_ = x[T_UNIT_GRAD-134]
+	_ = x[T_UNIT_RAD-135]
+	_ = x[T_UNIT_TURN-136]
+	_ = x[T_PROPERTY_NAME_TOKEN-137]
+	_ = x[T_PROPERTY_VALUE-138]
+	_ = x[T_HEX_COLOR-139]
+	_ = x[T_COLON-140]
+	_ = x[T_INTERPOLATION_START-141]
+	_ = x[T_INTERPOLATION_INNER-142]
+	_ = x[T_INTERPOLATION_END-143]
 }
 
-const _TokenType_name = "T_SPACET_COMMENT_LINET_COMMENT_BLOCKT_SEMICOLONT_COMMAT_IDENTT_URLT_MEDIAT_PAGET_TRUET_FALSET_NULLT_ONLYT_ODDT_EVENT_NT_MS_PARAM_NAMET_FUNCTION_NAMET_ID_SELECTORT_CLASS_SELECTORT_TYPE_SELECTORT_UNIVERSAL_SELECTORT_PARENT_SELECTORT_PSEUDO_SELECTORT_FUNCTIONAL_PSEUDOT_INTERPOLATION_SELECTORT_LITERAL_CONCATT_CONCATT_MS_PROGIDT_DESCENDANT_COMBINATORT_CHILD_COMBINATORT_ADJACENT_SIBLING_COMBINATORT_GENERAL_SIBLING_COMBINATORT_UNICODE_RANGET_IFT_ELSET_ELSE_IFT_INCLUDET_EACHT_WHENT_MIXINT_EXTENDT_FUNCTIONT_AT_ROOTT_WARNT_ERRORT_DEBUGT_FORT_FOR_FROMT_FOR_THROUGHT_FOR_TOT_FOR_INT_WHILET_RETURNT_RANGET_CONTENTT_FLAG_GLOBALT_FLAG_DEFAULTT_FLAG_IMPORTANTT_FLAG_OPTIONALT_FONT_FACET_NAMESPACET_LOGICAL_NOTT_LOGICAL_ORT_LOGICAL_ANDT_LOGICAL_XORT_NOPT_PLUST_DIVT_MULT_MINUST_MODT_BRACE_OPENT_BRACE_CLOSET_LANG_CODET_BRACKET_OPENT_ATTRIBUTE_NAMET_BRACKET_CLOSET_EQUALT_UNEQUALT_GTT_LTT_GET_LET_ASSIGNT_ATTR_EQUALT_INCLUDE_MATCHT_PREFIX_MATCHT_DASH_MATCHT_SUFFIX_MATCHT_SUBSTRING_MATCHT_VARIABLET_VARIABLE_LENGTH_ARGUMENTST_IMPORTT_AT_RULET_CHARSETT_QQ_STRINGT_Q_STRINGT_UNQUOTE_STRINGT_PAREN_OPENT_PAREN_CLOSET_FLAG_CONSTANTT_INTEGERT_FLOATT_CDOPENT_CDCLOSET_UNIT_NONET_UNIT_OTHERST_UNIT_PERCENTT_UNIT_SECONDT_UNIT_MILLISECONDT_UNIT_EMT_UNIT_EXT_UNIT_CHT_UNIT_REMT_UNIT_CMT_UNIT_INT_UNIT_MMT_UNIT_PCT_UNIT_PTT_UNIT_PXT_UNIT_VHT_UNIT_VWT_UNIT_VMINT_UNIT_VMAXT_UNIT_HZT_UNIT_KHZT_UNIT_DPIT_UNIT_DPCMT_UNIT_DPPXT_UNIT_DEGT_UNIT_GRADT_UNIT_RADT_UNIT_TURNT_PROPERTY_NAME_TOKENT_PROPERTY_VALUET_HEX_COLORT_COLONT_INTERPOLATION_STARTT_INTERPOLATION_INNERT_INTERPOLATION_END"
+const _TokenType_name = "T_SPACET_COMMENT_LINET_COMMENT_BLOCKT_SEMICOLONT_COMMAT_IDENTT_URLT_MEDIAT_PAGET_TRUET_FALSET_NULLT_ONLYT_ODDT_EVENT_NT_MS_PARAM_NAMET_FUNCTION_NAMET_ID_SELECTORT_CLASS_SELECTORT_TYPE_SELECTORT_UNIVERSAL_SELECTORT_PARENT_SELECTORT_PSEUDO_SELECTORT_FUNCTIONAL_PSEUDOT_PLACEHOLDER_SELECTORT_INTERPOLATION_SELECTORT_LITERAL_CONCATT_CONCATT_MS_PROGIDT_DESCENDANT_COMBINATORT_CHILD_COMBINATORT_ADJACENT_SIBLING_COMBINATORT_GENERAL_SIBLING_COMBINATORT_UNICODE_RANGET_IFT_ELSET_ELSE_IFT_INCLUDET_EACHT_WHENT_MIXINT_EXTENDT_FUNCTIONT_AT_ROOTT_WARNT_ERRORT_DEBUGT_FORT_FOR_FROMT_FOR_THROUGHT_FOR_TOT_FOR_INT_WHILET_RETURNT_RANGET_CONTENTT_FLAG_GLOBALT_FLAG_DEFAULTT_FLAG_IMPORTANTT_FLAG_OPTIONALT_FONT_FACET_NAMESPACET_LOGICAL_NOTT_LOGICAL_ORT_LOGICAL_ANDT_LOGICAL_XORT_NOPT_PLUST_DIVT_MULT_MINUST_MODT_BRACE_OPENT_BRACE_CLOSET_LANG_CODET_BRACKET_OPENT_ATTRIBUTE_NAMET_BRACKET_CLOSET_EQUALT_UNEQUALT_GTT_LTT_GET_LET_ASSIGNT_ATTR_EQUALT_INCLUDE_MATCHT_PREFIX_MATCHT_DASH_MATCHT_SUFFIX_MATCHT_SUBSTRING_MATCHT_VARIABLET_VARIABLE_LENGTH_ARGUMENTST_IMPORTT_USET_FORWARDT_AT_RULET_CHARSETT_QQ_STRINGT_Q_STRINGT_UNQUOTE_STRINGT_PAREN_OPENT_PAREN_CLOSET_FLAG_CONSTANTT_INTEGERT_FLOATT_CDOPENT_CDCLOSET_UNIT_NONET_UNIT_OTHERST_UNIT_PERCENTT_UNIT_SECONDT_UNIT_MILLISECONDT_UNIT_EMT_UNIT_EXT_UNIT_CHT_UNIT_REMT_UNIT_CMT_UNIT_INT_UNIT_MMT_UNIT_PCT_UNIT_PTT_UNIT_PXT_UNIT_VHT_UNIT_VWT_UNIT_VMINT_UNIT_VMAXT_UNIT_HZT_UNIT_KHZT_UNIT_DPIT_UNIT_DPCMT_UNIT_DPPXT_UNIT_DEGT_UNIT_GRADT_UNIT_RADT_UNIT_TURNT_PROPERTY_NAME_TOKENT_PROPERTY_VALUET_HEX_COLORT_COLONT_INTERPOLATION_STARTT_INTERPOLATION_INNERT_INTERPOLATION_END"
 
-var _TokenType_index = [...]uint16{0, 7, 21, 36, 47, 54, 61, 66, 73, 79, 85, 92, 98, 104, 109, 115, 118, 133, 148, 161, 177, 192, 212, 229, 246, 265, 289, 305, 313, 324, 347, 365, 394, 422, 437, 441, 447, 456, 465, 471, 477, 484, 492, 502, 511, 517, 524, 531, 536, 546, 559, 567, 575, 582, 590, 597, 606, 619, 633, 649, 664, 675, 686, 699, 711, 724, 737, 742, 748, 753, 758, 765, 770, 782, 795, 806, 820, 836, 851, 858, 867, 871, 875, 879, 883, 891, 903, 918, 932, 944, 958, 975, 985, 1012, 1020, 1029, 1038, 1049, 1059, 1075, 1087, 1100, 1115, 1124, 1131, 1139, 1148, 1159, 1172, 1186, 1199, 1217, 1226, 1235, 1244, 1254, 1263, 1272, 1281, 1290, 1299, 1308, 1317, 1326, 1337, 1348, 1357, 1367, 1377, 1388, 1399, 1409, 1420, 1430, 1441, 1462, 1478, 1489, 1496, 1517, 1538, 1557}
+var _TokenType_index = [...]uint16{0, 7, 21, 36, 47, 54, 61, 66, 73, 79, 85, 92, 98, 104, 109, 115, 118, 133, 148, 161, 177, 192, 212, 229, 246, 265, 287, 311, 327, 335, 346, 369, 387, 416, 444, 459, 463, 469, 478, 487, 493, 499, 506, 514, 524, 533, 539, 546, 553, 558, 568, 581, 589, 597, 604, 612, 619, 628, 641, 655, 671, 686, 697, 708, 721, 733, 746, 759, 764, 770, 775, 780, 787, 792, 804, 817, 828, 842, 858, 873, 880, 889, 893, 897, 901, 905, 913, 925, 940, 954, 966, 980, 997, 1007, 1034, 1042, 1047, 1056, 1065, 1074, 1085, 1095, 1111, 1123, 1136, 1151, 1160, 1167, 1175, 1184, 1195, 1208, 1222, 1235, 1253, 1262, 1271, 1280, 1290, 1299, 1308, 1317, 1326, 1335, 1344, 1353, 1362, 1373, 1384, 1393, 1403, 1413, 1424, 1435, 1445, 1456, 1466, 1477, 1498, 1514, 1525, 1532, 1553, 1574, 1593}
 
 func (i TokenType) String() string {
 	if i < 0 || i >= TokenType(len(_TokenType_index)-1) {
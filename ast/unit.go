@@ -1,6 +1,9 @@
 package ast
 
-import "strings"
+import (
+	"math"
+	"strings"
+)
 
 type Unit struct {
 	Type  TokenType
@@ -19,6 +22,126 @@ func (unit Unit) String() string {
 	if unit.Token != nil {
 		return unit.Token.Str
 	}
+
+	// units created by the runtime, e.g. the % of percentage()
+	for _, kw := range UnitTokenList {
+		if kw.TokenType == unit.Type {
+			return kw.Keyword
+		}
+	}
+
 	var name = string(unit.Type.String())
 	return strings.ToLower(strings.TrimPrefix(name, "T_UNIT_"))
 }
+
+type unitConversion struct {
+	// the canonical unit of the dimension
+	Canonical TokenType
+
+	// the number of canonical units in one unit
+	Factor float64
+}
+
+/*
+unitConversions is the conversion table of the absolute units, the units
+of the same dimension can be converted to each other:
+
+	1in == 96px
+	1s == 1000ms
+
+The relative units (em, rem, vw, %...) are not listed since they are only
+compatible with themselves.
+*/
+var unitConversions = map[TokenType]unitConversion{
+	// length
+	T_UNIT_PX: {T_UNIT_PX, 1},
+	T_UNIT_IN: {T_UNIT_PX, 96},
+	T_UNIT_CM: {T_UNIT_PX, 96 / 2.54},
+	T_UNIT_MM: {T_UNIT_PX, 96 / 25.4},
+	T_UNIT_PT: {T_UNIT_PX, 96.0 / 72},
+	T_UNIT_PC: {T_UNIT_PX, 16},
+
+	// time
+	T_UNIT_SECOND:      {T_UNIT_SECOND, 1},
+	T_UNIT_MILLISECOND: {T_UNIT_SECOND, 1.0 / 1000},
+
+	// frequency
+	T_UNIT_HZ:  {T_UNIT_HZ, 1},
+	T_UNIT_KHZ: {T_UNIT_HZ, 1000},
+
+	// angle
+	T_UNIT_DEG:  {T_UNIT_DEG, 1},
+	T_UNIT_GRAD: {T_UNIT_DEG, 360.0 / 400},
+	T_UNIT_RAD:  {T_UNIT_DEG, 180 / math.Pi},
+	T_UNIT_TURN: {T_UNIT_DEG, 360},
+
+	// resolution
+	T_UNIT_DPPX: {T_UNIT_DPPX, 1},
+	T_UNIT_DPI:  {T_UNIT_DPPX, 1.0 / 96},
+	T_UNIT_DPCM: {T_UNIT_DPPX, 2.54 / 96},
+}
+
+/*
+UnitConversionFactor returns the factor converting a value in the unit
+from to the unit to, ok is false when the units are incompatible. A nil
+unit (a number without unit) is compatible with any unit:
+
+	UnitConversionFactor(in, px) => 96, true
+	UnitConversionFactor(px, s) => 0, false
+*/
+func UnitConversionFactor(from, to *Unit) (factor float64, ok bool) {
+	if from == nil || to == nil || from.String() == to.String() {
+		return 1, true
+	}
+
+	a, okA := unitConversions[from.Type]
+	b, okB := unitConversions[to.Type]
+
+	if !okA || !okB || a.Canonical != b.Canonical {
+		return 0, false
+	}
+
+	return a.Factor / b.Factor, true
+}
+
+// UnitCompatible tells whether the values in the units can be added or
+// compared to each other
+func UnitCompatible(a, b *Unit) bool {
+	_, ok := UnitConversionFactor(a, b)
+	return ok
+}
+
+/*
+UnitsConversionFactor returns the factor converting a value in the product
+of the units from to the product of the units to, every unit must have a
+compatible unit in the other list:
+
+	[in, s], [ms, px] => 96000, true
+*/
+func UnitsConversionFactor(from, to []*Unit) (factor float64, ok bool) {
+	if len(from) != len(to) {
+		return 0, false
+	}
+
+	var rest = append([]*Unit{}, to...)
+	factor = 1
+
+	for _, unit := range from {
+		found := false
+
+		for idx, other := range rest {
+			if f, ok := UnitConversionFactor(unit, other); ok {
+				factor *= f
+				rest = append(rest[:idx], rest[idx+1:]...)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return 0, false
+		}
+	}
+
+	return factor, true
+}
@@ -0,0 +1,69 @@
+package ast
+
+import "testing"
+import "github.com/stretchr/testify/assert"
+
+func TestUnitConversionFactor(t *testing.T) {
+	unit := func(unitType TokenType) *Unit { return NewUnit(unitType, nil) }
+
+	factor, ok := UnitConversionFactor(unit(T_UNIT_IN), unit(T_UNIT_PX))
+	assert.True(t, ok)
+	assert.Equal(t, 96.0, factor)
+
+	factor, ok = UnitConversionFactor(unit(T_UNIT_MILLISECOND), unit(T_UNIT_SECOND))
+	assert.True(t, ok)
+	assert.Equal(t, 0.001, factor)
+
+	factor, ok = UnitConversionFactor(unit(T_UNIT_TURN), unit(T_UNIT_GRAD))
+	assert.True(t, ok)
+	assert.Equal(t, 400.0, factor)
+
+	factor, ok = UnitConversionFactor(nil, unit(T_UNIT_PX))
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, factor)
+
+	assert.False(t, UnitCompatible(unit(T_UNIT_PX), unit(T_UNIT_SECOND)))
+	assert.False(t, UnitCompatible(unit(T_UNIT_EM), unit(T_UNIT_PX)))
+	assert.True(t, UnitCompatible(unit(T_UNIT_EM), unit(T_UNIT_EM)))
+}
+
+func TestEqualNumbersWithCompatibleUnits(t *testing.T) {
+	assert.True(t, Equal(NewNumber(1, NewUnit(T_UNIT_IN, nil), nil), NewNumber(96, NewUnit(T_UNIT_PX, nil), nil)))
+	assert.False(t, Equal(NewNumber(1, NewUnit(T_UNIT_PX, nil), nil), NewNumber(1, NewUnit(T_UNIT_SECOND, nil), nil)))
+	assert.False(t, Equal(NewNumber(1, NewUnit(T_UNIT_PX, nil), nil), NewNumber(1, nil, nil)))
+}
+
+func TestNumberWithCompoundUnits(t *testing.T) {
+	px, em, in := NewUnit(T_UNIT_PX, nil), NewUnit(T_UNIT_EM, nil), NewUnit(T_UNIT_IN, nil)
+
+	num := NewNumberWithUnits(2, []*Unit{px, px}, []*Unit{em})
+	assert.Equal(t, "px*px/em", num.UnitString())
+	assert.True(t, num.IsCompound())
+
+	num = NewNumberWithUnits(2, []*Unit{px, px}, []*Unit{px})
+	assert.Equal(t, "2px", num.String())
+	assert.True(t, num.HasUnit(T_UNIT_PX))
+
+	num = NewNumberWithUnits(1, []*Unit{in}, []*Unit{px})
+	assert.Equal(t, "96", num.String())
+	assert.True(t, num.IsUnitless())
+
+	assert.Equal(t, "px^-1", NewNumberWithUnits(1, nil, []*Unit{px}).UnitString())
+	assert.Equal(t, "(px*em)^-1", NewNumberWithUnits(1, nil, []*Unit{px, em}).UnitString())
+}
+
+func TestNumberValueIn(t *testing.T) {
+	px, in := NewUnit(T_UNIT_PX, nil), NewUnit(T_UNIT_IN, nil)
+	s, ms := NewUnit(T_UNIT_SECOND, nil), NewUnit(T_UNIT_MILLISECOND, nil)
+
+	value, ok := NewNumberWithUnits(1, []*Unit{px}, []*Unit{s}).ValueIn(NewNumberWithUnits(1, []*Unit{px}, []*Unit{ms}))
+	assert.True(t, ok)
+	assert.Equal(t, 0.001, value)
+
+	value, ok = NewNumberWithUnits(1, []*Unit{in, s}, nil).ValueIn(NewNumberWithUnits(1, []*Unit{ms, px}, nil))
+	assert.True(t, ok)
+	assert.Equal(t, 96000.0, value)
+
+	_, ok = NewNumberWithUnits(1, []*Unit{px, px}, nil).ValueIn(NewNumber(1, px, nil))
+	assert.False(t, ok)
+}
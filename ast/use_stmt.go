@@ -0,0 +1,36 @@
+package ast
+
+/*
+For loading a module with its own namespace:
+
+@use "src/corners";         // => corners.$radius
+@use "src/corners" as c;    // => c.$radius
+@use "src/corners" as *;    // => $radius
+*/
+type UseStmt struct {
+	SourceFileName string
+	Path           *String
+
+	// Namespace is "*" when the members are accessed without namespace,
+	// it's empty when the namespace is derived from the path.
+	Namespace string
+
+	// With configures the !default variables of the module, the keys
+	// are *Variable, e.g. `@use "lib" with ($primary: blue)`
+	With *Map
+}
+
+func NewUseStmt(sourceFileName string) *UseStmt {
+	return &UseStmt{
+		SourceFileName: sourceFileName,
+	}
+}
+
+func (self UseStmt) CanBeStmt() {}
+
+func (self UseStmt) String() string {
+	if self.Namespace != "" {
+		return "@use " + self.Path.String() + " as " + self.Namespace + ";"
+	}
+	return "@use " + self.Path.String() + ";"
+}
@@ -7,11 +7,51 @@ import (
 	"os"
 	"path"
 
+	"github.com/c9s/c6/ast"
 	"github.com/c9s/c6/compiler"
 	"github.com/c9s/c6/parser"
 	"github.com/spf13/cobra"
 )
 
+// compile compiles the statements with the output options of the flags
+func compile(cmd *cobra.Command, gp *parser.GlobalParser, stmts *ast.StmtList) error {
+	precision, err := cmd.Flags().GetInt("precision")
+
+	if err != nil {
+		return err
+	}
+
+	if precision < 0 {
+		return fmt.Errorf("--precision must not be negative.")
+	}
+
+	style, err := cmd.Flags().GetString("style")
+
+	if err != nil {
+		return err
+	}
+
+	options := []compiler.Option{compiler.WithPrecision(precision)}
+
+	switch style {
+	case "expanded":
+	case "compressed":
+		options = append(options, compiler.WithCompressed())
+	default:
+		return fmt.Errorf("Unknown output style %q, expected expanded or compressed.", style)
+	}
+
+	var b bytes.Buffer
+	var c = compiler.NewPrettyCompiler(&b, options...)
+
+	if err := c.Compile(gp, stmts); err != nil {
+		return err
+	}
+
+	fmt.Println(b.String())
+	return nil
+}
+
 func main() {
 	var rootCmd = &cobra.Command{
 		Use:   "c6",
@@ -28,17 +68,7 @@ func main() {
 				return err
 			}
 
-			var b bytes.Buffer
-			var compiler = compiler.NewPrettyCompiler(&b)
-
-			err = compiler.Compile(parser, stmts)
-
-			if err != nil {
-				return err
-			}
-
-			fmt.Println(b.String())
-			return nil
+			return compile(cmd, parser, stmts)
 		},
 	}
 
@@ -66,22 +96,14 @@ func main() {
 				return err
 			}
 
-			var b bytes.Buffer
-			var compiler = compiler.NewPrettyCompiler(&b)
-
-			err = compiler.Compile(parser, stmts)
-
-			if err != nil {
-				return err
-			}
-
-			fmt.Println(b.String())
-			return nil
+			return compile(cmd, parser, stmts)
 		},
 	}
 
-	compileCmd.Flags().Int("precision", 0, "I don't know the meaning of this flag")
-	rootCmd.Flags().Int("precision", 0, "I don't know the meaning of this flag")
+	for _, cmd := range []*cobra.Command{rootCmd, compileCmd} {
+		cmd.Flags().Int("precision", ast.DefaultPrecision, "The number of digits after the decimal point of the numbers")
+		cmd.Flags().String("style", "expanded", "The output style, expanded or compressed")
+	}
 
 	rootCmd.AddCommand(compileCmd)
 	if err := rootCmd.Execute(); err != nil {
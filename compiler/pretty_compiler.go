@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/c9s/c6/ast"
 	"github.com/c9s/c6/parser"
@@ -25,6 +26,14 @@ type PrettyCompiler struct {
 	Indent       int
 	DebugPrinter runtime.Printer
 	WarnPrinter  runtime.Printer
+
+	// Compressed removes the whitespace and the leading zeros of the
+	// numbers from the output
+	Compressed bool
+
+	// Precision is the number of digits written after the decimal point
+	// of the numbers
+	Precision int
 }
 
 func NewPrettyCompiler(buf *bytes.Buffer, o ...Option) *PrettyCompiler {
@@ -33,6 +42,7 @@ func NewPrettyCompiler(buf *bytes.Buffer, o ...Option) *PrettyCompiler {
 		Indent:       0,
 		DebugPrinter: DefaultPrinter,
 		WarnPrinter:  DefaultPrinter,
+		Precision:    ast.DefaultPrecision,
 	}
 
 	for _, o := range o {
@@ -54,11 +64,87 @@ func WithWarn(p runtime.Printer) Option {
 	}
 }
 
+// WithCompressed writes the compressed output: `.a{width:.5px}`
+func WithCompressed() Option {
+	return func(c *PrettyCompiler) {
+		c.Compressed = true
+	}
+}
+
+// WithPrecision writes the numbers with n digits after the decimal point
+// at most: `1.1041666667in` => `1.104in`
+func WithPrecision(n int) Option {
+	return func(c *PrettyCompiler) {
+		c.Precision = n
+	}
+}
+
+// sep returns the separator, without the spaces around it in the
+// compressed output
+func (c *PrettyCompiler) sep(s string) string {
+	if c.Compressed {
+		return strings.TrimSpace(s)
+	}
+
+	return s
+}
+
+/*
+compressNumber removes the leading zero of the number:
+
+	0.5px => .5px
+	-0.5 => -.5
+*/
+func compressNumber(s string) string {
+	if strings.HasPrefix(s, "0.") {
+		return s[1:]
+	}
+
+	if strings.HasPrefix(s, "-0.") {
+		return "-" + s[2:]
+	}
+
+	return s
+}
+
+/*
+compressColor returns the shortest form of the color, the keyword or the
+hex code:
+
+	white => #fff
+	#ff0000 => red
+	rgba(255, 0, 0, 0.5) => rgba(255,0,0,.5)
+*/
+func compressColor(color *ast.Color, precision int) string {
+	r, g, b := color.RGB()
+
+	if !ast.FuzzyEqual(color.A, 1) {
+		return fmt.Sprintf("rgba(%d,%d,%d,%s)", r, g, b, compressNumber(ast.FormatNumberWithPrecision(color.A, precision)))
+	}
+
+	hex := color.Hex()
+
+	if hex[1] == hex[2] && hex[3] == hex[4] && hex[5] == hex[6] {
+		hex = "#" + hex[1:2] + hex[3:4] + hex[5:6]
+	}
+
+	if name := color.Name(); name != "" && len(name) < len(hex) {
+		return name
+	}
+
+	return hex
+}
+
 func (c *PrettyCompiler) changeIndent(delta int) {
 	c.Indent += delta
 }
 
 func (c *PrettyCompiler) printLine(l string, printLeadingNewLine bool) {
+	if c.Compressed {
+		c.Buffer.WriteString(l)
+		return
+	}
+
 	if printLeadingNewLine {
 		err := c.Buffer.WriteByte('\n')
 
@@ -82,7 +168,9 @@ func (c *PrettyCompiler) printLine(l string, printLeadingNewLine bool) {
 }
 
 func (c *PrettyCompiler) printNewline() {
-	c.Buffer.WriteByte('\n')
+	if !c.Compressed {
+		c.Buffer.WriteByte('\n')
+	}
 }
 
 func (c *PrettyCompiler) printByte(b byte) {
@@ -94,30 +182,81 @@ func (c *PrettyCompiler) printString(s string) {
 }
 
 func (c *PrettyCompiler) CompileComplexSelectorList(selectorList *ast.ComplexSelectorList) {
-	c.printLine(selectorList.String(), false)
+	if !c.Compressed {
+		c.printLine(selectorList.String(), false)
+		return
+	}
+
+	for idx, sel := range *selectorList {
+		if idx > 0 {
+			c.printByte(',')
+		}
+
+		for _, item := range sel.ComplexSelectorItems {
+			if item.Combinator != nil {
+				// keep the descendant combinator
+				if comb := c.sep(item.Combinator.String()); comb != "" {
+					c.printString(comb)
+				} else {
+					c.printByte(' ')
+				}
+			}
+
+			if item.CompoundSelector != nil {
+				c.printString(item.CompoundSelector.String())
+			}
+		}
+	}
 }
 
 func (c *PrettyCompiler) CompileValue(v ast.Expr) {
 	switch v := v.(type) {
 	case *ast.List:
+		if v.Bracketed {
+			c.printByte('[')
+		}
+
 		for idx, expr := range v.Exprs {
-			if idx > 0 {
-				c.printString(v.Separator)
+			if idx > 0 && v.Separator == " " {
+				c.printByte(' ')
+			} else if idx > 0 {
+				c.printString(c.sep(v.Separator))
 			}
 
 			c.CompileValue(expr)
 		}
+
+		if v.Bracketed {
+			c.printByte(']')
+		}
+	case *ast.Number:
+		if c.Compressed {
+			c.printString(compressNumber(v.StringWithPrecision(c.Precision)))
+		} else {
+			c.printString(v.StringWithPrecision(c.Precision))
+		}
+	case *ast.Color:
+		if c.Compressed && v.Space.Legacy {
+			c.printString(compressColor(v, c.Precision))
+		} else {
+			c.printString(v.StringWithPrecision(c.Precision))
+		}
 	default:
-		c.printString(v.String())
+		c.printString(ast.StringWithPrecision(v, c.Precision))
 	}
 }
 
 func (c *PrettyCompiler) CompileDeclBlock(block *ast.DeclBlock) {
-	for _, stm := range block.Stmts.Stmts {
+	for idx, stm := range block.Stmts.Stmts {
+		// the last semicolon is omitted in the compressed output
+		if c.Compressed && idx > 0 {
+			c.printByte(';')
+		}
+
 		switch stm := stm.(type) {
 		case *ast.Property:
 			c.printLine(stm.Name.String(), true)
-			c.printString(": ")
+			c.printString(c.sep(": "))
 			for idx, v := range stm.Values {
 				if idx > 0 {
 					c.printByte(' ')
@@ -128,13 +267,16 @@ func (c *PrettyCompiler) CompileDeclBlock(block *ast.DeclBlock) {
 		default:
 			c.printLine(stm.String(), true)
 		}
-		c.printByte(';')
+
+		if !c.Compressed {
+			c.printByte(';')
+		}
 	}
 }
 
 func (c *PrettyCompiler) CompileRuleSet(ruleset *ast.RuleSet) {
 	c.CompileComplexSelectorList(ruleset.Selectors)
-	c.printLine(" {", false)
+	c.printString(c.sep(" {"))
 	c.changeIndent(1)
 	c.CompileDeclBlock(ruleset.Block)
 	c.changeIndent(-1)
@@ -155,11 +297,15 @@ func (c *PrettyCompiler) CompileExpression(stmt ast.Expr) {
 		c.CompileExpression(t.Expr)
 	case *ast.Token:
 		c.printString(t.Str)
+	case *ast.String:
+		c.printString(t.String())
+	case *ast.Number:
+		c.CompileValue(t)
 	case *ast.MediaFeature:
 		c.printByte('(')
 		c.CompileExpression(t.Feature)
 		if t.Value != nil {
-			c.printString(": ")
+			c.printString(c.sep(": "))
 			c.CompileExpression(t.Value)
 		}
 		c.printByte(')')
@@ -189,19 +335,79 @@ func (c *PrettyCompiler) CompileMediaQuery(stmt *ast.MediaQuery) {
 }
 
 func (c *PrettyCompiler) CompileMediaQueryList(stmt *ast.MediaQueryList) {
-	if len(stmt.List) == 0 {
+	if stmt == nil || len(stmt.List) == 0 {
 		return
 	}
 
 	for idx, q := range stmt.List {
 		if idx > 0 {
-			c.printString(", ")
+			c.printString(c.sep(", "))
 		}
 
 		c.CompileMediaQuery(q)
 	}
 }
 
+func (c *PrettyCompiler) CompileMediaQueryStmt(stmt *ast.MediaQueryStmt) error {
+	c.printLine("@media", false)
+	if stmt.MediaQueryList != nil && len(stmt.MediaQueryList.List) > 0 {
+		c.printString(" ")
+		c.CompileMediaQueryList(stmt.MediaQueryList)
+	}
+	c.printString(c.sep(" {"))
+	c.changeIndent(1)
+
+	for _, stm := range stmt.Block.Stmts.Stmts {
+		c.printNewline()
+
+		if err := c.CompileStmt(stm); err != nil {
+			return err
+		}
+	}
+
+	c.changeIndent(-1)
+	c.printLine("}", true)
+
+	return nil
+}
+
+// CompileAtRuleStmt writes the at-rules unknown to Sass, the block may
+// contain declarations or rules
+func (c *PrettyCompiler) CompileAtRuleStmt(stmt *ast.AtRuleStmt) error {
+	c.printLine("@"+stmt.Name, false)
+
+	if stmt.Prelude != nil {
+		c.printByte(' ')
+		c.printString(stmt.Prelude.String())
+	}
+
+	if stmt.Block == nil {
+		c.printByte(';')
+		return nil
+	}
+
+	c.printString(c.sep(" {"))
+	c.changeIndent(1)
+
+	for _, stm := range stmt.Block.Stmts.Stmts {
+		if _, ok := stm.(*ast.Property); ok {
+			c.CompileDeclBlock(stmt.Block)
+			break
+		}
+
+		c.printNewline()
+
+		if err := c.CompileStmt(stm); err != nil {
+			return err
+		}
+	}
+
+	c.changeIndent(-1)
+	c.printLine("}", true)
+
+	return nil
+}
+
 func (c *PrettyCompiler) CompileCssImport(stmt *ast.CssImportStmt) {
 	c.printString(fmt.Sprintf("@import url(%s)", stmt.Url))
 	if stmt.MediaQueryList != nil {
@@ -220,6 +426,10 @@ func (c *PrettyCompiler) CompileStmt(anyStm ast.Stmt) error {
 	case *ast.CssImportStmt:
 		c.CompileCssImport(stm)
 		return nil
+	case *ast.MediaQueryStmt:
+		return c.CompileMediaQueryStmt(stm)
+	case *ast.AtRuleStmt:
+		return c.CompileAtRuleStmt(stm)
 	case *ast.AssignStmt:
 		return nil
 	}
@@ -263,6 +473,7 @@ func (c *PrettyCompiler) CompileRoot(list []*ast.StmtList) error {
 func (c *PrettyCompiler) Compile(gp *parser.GlobalParser, list *ast.StmtList) error {
 	scope := runtime.NewScope(nil)
 	r := runtime.NewRuntime(gp, c.DebugPrinter, c.WarnPrinter)
+	r.Precision = c.Precision
 	executed, err := r.ExecuteList(scope, list)
 
 	if err != nil {
@@ -275,5 +486,11 @@ func (c *PrettyCompiler) Compile(gp *parser.GlobalParser, list *ast.StmtList) er
 		return err
 	}
 
-	return c.CompileRoot(expanded)
+	extended, err := runtime.ExtendTree(expanded)
+
+	if err != nil {
+		return err
+	}
+
+	return c.CompileRoot(extended)
 }
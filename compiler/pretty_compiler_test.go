@@ -10,14 +10,14 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func AssertPrettyCompile(t *testing.T, code string, expected string) {
+func AssertPrettyCompile(t *testing.T, code string, expected string, o ...Option) {
 	var parser = parser.NewParser(nil)
 	stmts, err := parser.ParseScss(code)
 	require.NoError(t, err)
 
 	var buf bytes.Buffer
 
-	var compiler = NewPrettyCompiler(&buf)
+	var compiler = NewPrettyCompiler(&buf, o...)
 
 	err = compiler.Compile(parser, stmts)
 	require.NoError(t, err)
@@ -105,3 +105,64 @@ func TestPrettyCompileMultipleDeclarations(t *testing.T) {
 }`)
 
 }
+
+func TestPrettyCompileNumberPrecision(t *testing.T) {
+	AssertPrettyCompile(t,
+		`a { width: 1in + 10px; height: 0.1 + 0.2; margin: 10 * 0.0000001; }`,
+		`a {
+  width: 1.1041666667in;
+  height: 0.3;
+  margin: 0.000001;
+}`)
+}
+
+func TestPrettyCompileWithPrecision(t *testing.T) {
+	AssertPrettyCompile(t,
+		`a { width: 1in + 10px; height: 1.23456; }`,
+		`a {
+  width: 1.104in;
+  height: 1.235;
+}`,
+		WithPrecision(3))
+
+	AssertPrettyCompile(t,
+		`$x: 1.23456px; a { b: #{$x}; c: rgba(0, 0, 0, 0.123456); d: translate($x, 2px) calc(100% - #{$x}); }`,
+		`a {
+  b: 1.235px;
+  c: rgba(0, 0, 0, 0.123);
+  d: translate(1.235px, 2px) calc(100% - 1.235px);
+}`,
+		WithPrecision(3))
+}
+
+func TestCompressedCompile(t *testing.T) {
+	AssertPrettyCompile(t,
+		`.a > .b, .c .d { width: 0.5px; margin: -0.25em 0 1.5em; font-family: a, b; }
+		.e { color: red; }
+		@media screen and (min-width: 0.5em) { .f { opacity: 0.8; } }`,
+		`.a>.b,.c .d{width:.5px;margin:-.25em 0 1.5em;font-family:a,b}.e{color:red}@media screen and (min-width:.5em){.f{opacity:.8}}`,
+		WithCompressed())
+}
+
+func TestCompressedCompileColors(t *testing.T) {
+	AssertPrettyCompile(t,
+		`.a { color: #FFFFFF; background: white #ff0000 rgba(red, 0.5) #abcdef; }`,
+		`.a{color:#fff;background:#fff red rgba(255,0,0,.5) #abcdef}`,
+		WithCompressed())
+}
+
+func TestCompressedCompileSpaceColors(t *testing.T) {
+	AssertPrettyCompile(t,
+		`.a { color: oklch(62% 0.19 255deg) color(display-p3 1 0 0 / 0.5); }`,
+		`.a{color:oklch(62% 0.19 255deg) color(display-p3 1 0 0 / 0.5)}`,
+		WithCompressed())
+}
+
+func TestPrettyCompileColorsAsWritten(t *testing.T) {
+	AssertPrettyCompile(t,
+		`.a { color: #FFF Red; background: #ffffff + #000 rgba(#f00, 0.5) hsl(120, 100%, 25%); }`,
+		`.a {
+  color: #FFF Red;
+  background: white rgba(255, 0, 0, 0.5) green;
+}`)
+}
@@ -363,7 +363,12 @@ func (l *Lexer) matchKeywordList(keywords ast.KeywordTokenList, ignoreMinus bool
 	for _, keyword := range keywords {
 		l.remember()
 		if l.match(keyword.Keyword) {
-			var r = l.peek()
+			var r, r2 = l.peek2()
+			// a keyword followed by a minus can still be the beginning of
+			// an identifier, e.g. `to-upper-case` or `not-allowed`
+			if r == '-' && (unicode.IsLetter(r2) || r2 == '-' || r2 == '_') {
+				r = r2
+			}
 			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || (r == '-' && !ignoreMinus) {
 				// try next one
 				l.rollback()
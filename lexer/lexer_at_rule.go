@@ -16,7 +16,7 @@ func lexAtRule(l *Lexer) (stateFn, error) {
 	var tok = l.matchKeywordList(ast.KeywordList, false)
 	if tok != nil {
 		switch tok.Type {
-		case ast.T_IMPORT:
+		case ast.T_IMPORT, ast.T_USE, ast.T_FORWARD:
 			l.ignoreSpaces()
 			for {
 				fn, err := lexExpr(l)
@@ -112,7 +112,7 @@ func lexAtRule(l *Lexer) (stateFn, error) {
 
 			return lexForStmt, nil
 
-		case ast.T_WHILE:
+		case ast.T_WHILE, ast.T_EACH:
 			for {
 				fn, err := lexExpr(l)
 				if err != nil {
@@ -125,13 +125,10 @@ func lexAtRule(l *Lexer) (stateFn, error) {
 			}
 			return lexStart, nil
 
-		case ast.T_CONTENT:
-			return lexStart, nil
-
 		case ast.T_EXTEND:
 			return lexSelectors, nil
 
-		case ast.T_FUNCTION, ast.T_RETURN, ast.T_MIXIN, ast.T_INCLUDE:
+		case ast.T_FUNCTION, ast.T_RETURN, ast.T_MIXIN, ast.T_INCLUDE, ast.T_CONTENT:
 			for {
 				fn, err := lexExpr(l)
 				if err != nil {
@@ -159,5 +156,64 @@ func lexAtRule(l *Lexer) (stateFn, error) {
 			return nil, fmt.Errorf("Unsupported at-rule directive '%s' %s", l.current(), tok)
 		}
 	}
+
+	// the at-rules unknown to Sass are kept as they are, e.g. @supports
+	if l.peek() == '@' {
+		return lexUnknownAtRule, nil
+	}
 	return nil, nil
 }
+
+/*
+lexUnknownAtRule lexes the name of the at-rule and its prelude, the prelude
+is not an expression so it's emitted as a single unquoted string:
+
+	@supports not (display: #{$display}) { ... }
+*/
+func lexUnknownAtRule(l *Lexer) (stateFn, error) {
+	l.next() // '@'
+
+	var r = l.next()
+	if !unicode.IsLetter(r) && r != '-' {
+		return nil, l.errorf("Expected identifier after '@', got '%c'", r)
+	}
+
+	for r = l.peek(); unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_'; r = l.peek() {
+		l.next()
+	}
+	l.emit(ast.T_AT_RULE)
+	l.ignoreSpaces()
+
+	var interpolated = false
+	var quote rune = 0
+
+	for r = l.peek(); r != EOF; r = l.peek() {
+		if quote != 0 {
+			l.next()
+			if r == '\\' {
+				l.next()
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		if r == '"' || r == '\'' {
+			quote = r
+		} else if IsInterpolationStartToken(r, l.peekBy(2)) {
+			if _, err := lexInterpolation(l, false); err != nil {
+				return nil, err
+			}
+			interpolated = true
+			continue
+		} else if r == '{' || r == ';' || r == '}' {
+			break
+		}
+		l.next()
+	}
+
+	if l.Offset > l.Start {
+		l.emit(ast.T_UNQUOTE_STRING, interpolated)
+	}
+	return lexStart, nil
+}
@@ -6,6 +6,12 @@ import (
 	"github.com/c9s/c6/ast"
 )
 
+// isConcatRune returns true when the runes right after an interpolation are
+// concatenated to it, e.g. `#{$side}-top` or `#{$size}px`
+func isConcatRune(r, r2 rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '%' || IsInterpolationStartToken(r, r2)
+}
+
 /*
 Lexing expression with interpolation support.
 */
@@ -17,7 +23,7 @@ func lexExpr(l *Lexer) (stateFn, error) {
 
 	// avoid double literal concat
 	if lastToken != nil && lastToken.Type != ast.T_LITERAL_CONCAT {
-		if leadingSpaces == 0 && lastToken != nil && lastToken.Type == ast.T_INTERPOLATION_END {
+		if leadingSpaces == 0 && lastToken != nil && lastToken.Type == ast.T_INTERPOLATION_END && isConcatRune(r, r2) {
 			l.emit(ast.T_LITERAL_CONCAT)
 		} else if leadingSpaces == 0 && l.Offset > 0 && r == '#' && r2 == '{' {
 			l.emit(ast.T_LITERAL_CONCAT)
@@ -95,6 +101,13 @@ func lexExpr(l *Lexer) (stateFn, error) {
 			l.accept("n")
 			l.emit(ast.T_N)
 
+		} else if r3 := l.peekBy(3); r2 == '-' && (unicode.IsLetter(r3) || r3 == '-' || r3 == '_') {
+			// custom properties, e.g. var(--gap)
+			l.next()
+			if _, err := lexIdentifier(l); err != nil {
+				return nil, err
+			}
+
 		} else if unicode.IsLetter(r2) {
 			// XXX: Works for '-moz' or '-webkit-..' but we should move this to property lexing...
 			//    like:
@@ -162,6 +175,16 @@ func lexExpr(l *Lexer) (stateFn, error) {
 		l.next()
 		l.emit(ast.T_PAREN_CLOSE)
 
+	} else if r == '[' { // bracketed list
+
+		l.next()
+		l.emit(ast.T_BRACKET_OPEN)
+
+	} else if r == ']' {
+
+		l.next()
+		l.emit(ast.T_BRACKET_CLOSE)
+
 	} else if r == '<' {
 
 		l.next()
@@ -16,8 +16,14 @@ func TestLexerIdentifierWithTrailingInterp(t *testing.T) {
 	AssertLexerTokenSequenceFromState(t, `none#{ 10 + 10 }`, lexExpr, []ast.TokenType{
 		ast.T_IDENT, ast.T_LITERAL_CONCAT, ast.T_INTERPOLATION_START,
 		ast.T_INTEGER, ast.T_PLUS, ast.T_INTEGER,
-		ast.T_INTERPOLATION_END,
-		ast.T_LITERAL_CONCAT})
+		ast.T_INTERPOLATION_END})
+}
+
+func TestLexerInterpolationBeforeParenClose(t *testing.T) {
+	AssertLexerTokenSequenceFromState(t, `(max-width: #{$w})`, lexExpr, []ast.TokenType{
+		ast.T_PAREN_OPEN, ast.T_IDENT, ast.T_COLON,
+		ast.T_INTERPOLATION_START, ast.T_VARIABLE, ast.T_INTERPOLATION_END,
+		ast.T_PAREN_CLOSE})
 }
 
 func TestLexerIdentifierWithLeadingInterp(t *testing.T) {
@@ -27,6 +33,10 @@ func TestLexerIdentifierWithLeadingInterp(t *testing.T) {
 		ast.T_INTERPOLATION_END, ast.T_LITERAL_CONCAT, ast.T_IDENT})
 }
 
+func TestLexerCustomPropertyIdentifier(t *testing.T) {
+	AssertLexerTokenSequenceFromState(t, `--gap`, lexExpr, []ast.TokenType{ast.T_IDENT})
+}
+
 func TestLexerExpr(t *testing.T) {
 	AssertLexerTokenSequenceFromState(t, `$foo`, lexExpr, []ast.TokenType{ast.T_VARIABLE})
 }
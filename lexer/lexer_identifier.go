@@ -2,7 +2,9 @@ package lexer
 
 import (
 	"fmt"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/c9s/c6/ast"
 )
@@ -14,12 +16,11 @@ func lexIdentifier(l *Lexer) (stateFn, error) {
 	}
 	r = l.next()
 
-	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
 		if r == '-' {
 			var r2 = l.peek()
 			if !unicode.IsLetter(r2) && r2 != '-' {
-				l.backup()
-				return lexExpr, nil
+				break
 			}
 		}
 
@@ -27,10 +28,27 @@ func lexIdentifier(l *Lexer) (stateFn, error) {
 	}
 	l.backup()
 
+	// members of modules are accessed with the namespace of the module,
+	// e.g. `colors.$primary`, `math.div(...)` or `@include theme.dark`
+	if l.peek() == '.' {
+		var r2 = l.peekBy(2)
+		if r2 == '$' {
+			l.next()
+			return lexVariableName(l)
+		} else if unicode.IsLetter(r2) {
+			l.next()
+			r = l.next()
+			for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+				r = l.next()
+			}
+			l.backup()
+		}
+	}
+
 	if l.peek() == '(' {
 		var curTok = l.emit(ast.T_FUNCTION_NAME)
 
-		if curTok.Str == "url" || curTok.Str == "local" {
+		if curTok.Str == "local" || (curTok.Str == "url" && isRawUrl(l.Input[l.Offset:])) {
 			if err := lexUrlParam(l); err != nil {
 				return nil, err
 			}
@@ -44,3 +62,35 @@ func lexIdentifier(l *Lexer) (stateFn, error) {
 	}
 	return lexExpr, nil
 }
+
+/*
+isRawUrl tells whether the argument of url() starting at the parenthesis
+is an unquoted url, which is kept as it is written. The other arguments
+are sassscript:
+
+	url(foo.png)
+	url($base + "/foo.png")
+*/
+func isRawUrl(input string) bool {
+	var found = false
+	var rest = strings.TrimLeftFunc(strings.TrimPrefix(input, "("), unicode.IsSpace)
+
+	for len(rest) > 0 {
+		r, width := utf8.DecodeRuneInString(rest)
+
+		if strings.HasPrefix(rest, "#{") {
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return false
+			}
+			width = end + 1
+		} else if !(r == '!' || r == '%' || r == '&' || (r >= '*' && r <= '~') || r >= 0x80) {
+			break
+		}
+
+		found = true
+		rest = rest[width:]
+	}
+
+	return found && strings.HasPrefix(strings.TrimLeftFunc(rest, unicode.IsSpace), ")")
+}
@@ -81,3 +81,8 @@ func lexInterpolation2(l *Lexer) (stateFn, error) {
 	l.emit(ast.T_INTERPOLATION_END)
 	return nil, nil
 }
+
+// RunInterpolation lexes the input as a single interpolation, e.g. `#{$name}`
+func (l *Lexer) RunInterpolation() ([]*ast.Token, error) {
+	return l.RunFrom(lexInterpolation2)
+}
@@ -512,6 +512,12 @@ func TestLexerForStmtSimpleFromThrough(t *testing.T) {
 	})
 }
 
+func TestLexerEachStmtDestructuring(t *testing.T) {
+	AssertLexerTokenSequence(t, `@each $key, $value in $map {  }`, []ast.TokenType{
+		ast.T_EACH, ast.T_VARIABLE, ast.T_COMMA, ast.T_VARIABLE, ast.T_FOR_IN, ast.T_VARIABLE, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE,
+	})
+}
+
 func TestLexerNestedProperty(t *testing.T) {
 	code := `
 .foo {
@@ -651,6 +657,25 @@ func TestLexerMixinIncludeArgumentsWithContentBlock(t *testing.T) {
 	})
 }
 
+func TestLexerMixinIncludeUsingContentArguments(t *testing.T) {
+	code := `@include sizes using ($size) { width: $size; }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_INCLUDE, ast.T_IDENT, ast.T_IDENT, ast.T_PAREN_OPEN, ast.T_VARIABLE, ast.T_PAREN_CLOSE,
+		ast.T_BRACE_OPEN,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_VARIABLE, ast.T_SEMICOLON,
+		ast.T_BRACE_CLOSE,
+	})
+}
+
+func TestLexerMixinContentArguments(t *testing.T) {
+	code := `@mixin sizes { @content(1px); }`
+	AssertLexerTokenSequence(t, code, []ast.TokenType{
+		ast.T_MIXIN, ast.T_IDENT, ast.T_BRACE_OPEN,
+		ast.T_CONTENT, ast.T_PAREN_OPEN, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_PAREN_CLOSE, ast.T_SEMICOLON,
+		ast.T_BRACE_CLOSE,
+	})
+}
+
 func TestLexerMixinArguments(t *testing.T) {
 	code := `
 @mixin sexy-border($color, $width) {
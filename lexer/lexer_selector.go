@@ -23,7 +23,8 @@ func IsSelector(t ast.TokenType) bool {
 		t == ast.T_UNIVERSAL_SELECTOR ||
 		t == ast.T_PARENT_SELECTOR || // SASS parent selector
 		t == ast.T_PSEUDO_SELECTOR || // :hover, :visited , ...
-		t == ast.T_FUNCTIONAL_PSEUDO
+		t == ast.T_FUNCTIONAL_PSEUDO ||
+		t == ast.T_PLACEHOLDER_SELECTOR // SASS placeholder selector
 }
 
 /*
@@ -40,6 +41,7 @@ func IsSelectorStopToken(r rune) bool {
 		r == '>' ||
 		r == '*' ||
 		r == '+' ||
+		r == '%' ||
 		r == ','
 }
 
@@ -124,7 +126,7 @@ func lexAttributeSelector(l *Lexer) (stateFn, error) {
 		if r == ']' {
 			l.next()
 			l.emit(ast.T_BRACKET_CLOSE)
-			return lexStart, nil
+			return lexSelectors, nil
 		}
 	}
 	return nil, l.errorf("Unexpected token for attribute selector. Got '%c'", r)
@@ -133,13 +135,17 @@ func lexAttributeSelector(l *Lexer) (stateFn, error) {
 func lexClassSelector(l *Lexer) (stateFn, error) {
 	l.accept(".")
 
+	if IsInterpolationStartToken(l.peek(), l.peekBy(2)) {
+		return lexInterpolatedName(l, ast.T_CLASS_SELECTOR)
+	}
+
 	var r = l.next()
 	if !unicode.IsLetter(r) {
 		return nil, l.errorf("Expecting letter for class selector. got '%c'", r)
 	}
 
 	// skip valid class name characters
-	for unicode.IsLetter(r) || r == '-' || r == '_' {
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
 		r = l.next()
 	}
 	l.backup()
@@ -147,6 +153,55 @@ func lexClassSelector(l *Lexer) (stateFn, error) {
 	return lexSelectors, nil
 }
 
+func lexPlaceholderSelector(l *Lexer) (stateFn, error) {
+	l.accept("%")
+
+	if IsInterpolationStartToken(l.peek(), l.peekBy(2)) {
+		return lexInterpolatedName(l, ast.T_PLACEHOLDER_SELECTOR)
+	}
+
+	var r = l.next()
+	if !unicode.IsLetter(r) {
+		return nil, l.errorf("Expecting letter for placeholder selector. got '%c'", r)
+	}
+
+	// skip valid placeholder name characters
+	for unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+		r = l.next()
+	}
+	l.backup()
+	l.emit(ast.T_PLACEHOLDER_SELECTOR)
+	return lexSelectors, nil
+}
+
+/*
+lexInterpolatedName lexes the name of a class or placeholder selector
+starting with interpolation, the token is re-parsed once the interpolation
+is evaluated:
+
+	.#{$name}
+	%#{$name}-base
+*/
+func lexInterpolatedName(l *Lexer, tokType ast.TokenType) (stateFn, error) {
+	var r = l.next()
+
+	for {
+		if IsInterpolationStartToken(r, l.peek()) {
+			l.backup()
+			if _, err := lexInterpolation(l, false); err != nil {
+				return nil, err
+			}
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			break
+		}
+		r = l.next()
+	}
+	l.backup()
+
+	l.emit(tokType, true)
+	return lexSelectors, nil
+}
+
 func lexPseudoSelector(l *Lexer) (stateFn, error) {
 	var foundInterpolation = false
 
@@ -184,18 +239,8 @@ func lexPseudoSelector(l *Lexer) (stateFn, error) {
 			l.next()
 			l.emit(ast.T_PAREN_OPEN)
 
-			r = l.peek()
-			for r != ')' && r != EOF {
-				fn, err := lexExpr(l)
-
-				if err != nil {
-					return nil, err
-				}
-
-				if fn == nil {
-					break
-				}
-				r = l.peek()
+			if err := lexPseudoArgument(l); err != nil {
+				return nil, err
 			}
 
 			if err := l.expect(")"); err != nil {
@@ -235,15 +280,17 @@ func lexSimpleSelector(l *Lexer) (stateFn, error) {
 
 		return lexPseudoSelector, nil
 
+	} else if r == '%' {
+
+		return lexPlaceholderSelector, nil
+
 	} else if r == '#' && l.peekBy(2) != '{' {
 
 		return lexIdSelector, nil
 
 	} else if r == '&' {
 
-		l.next()
-		l.emit(ast.T_PARENT_SELECTOR)
-		return lexSelectors, nil
+		return lexParentSelector, nil
 
 	} else if r == '*' {
 
@@ -266,8 +313,9 @@ func lexSelectors(l *Lexer) (stateFn, error) {
 		return nil, err
 	}
 
-	// space between selector means descendant selector
-	if tok := l.lastToken(); tok != nil && IsSelector(tok.Type) {
+	// space between selector means descendant selector, the functional
+	// pseudo and attribute selectors end with their closing token
+	if tok := l.lastToken(); tok != nil && (IsSelector(tok.Type) || tok.Type == ast.T_PAREN_CLOSE || tok.Type == ast.T_BRACKET_CLOSE) {
 		var foundSpace = false
 		var r = l.next()
 		for unicode.IsSpace(r) || r == '/' {
@@ -283,7 +331,7 @@ func lexSelectors(l *Lexer) (stateFn, error) {
 		if r == EOF {
 			return nil, nil
 		}
-		if foundSpace && r != ',' && r != '{' && !IsCombinatorToken(r) {
+		if foundSpace && r != ',' && r != '{' && r != ';' && r != '!' && !IsCombinatorToken(r) {
 			l.emit(ast.T_DESCENDANT_COMBINATOR)
 		} else {
 			l.ignore()
@@ -302,6 +350,10 @@ func lexSelectors(l *Lexer) (stateFn, error) {
 
 		return lexTypeSelector, nil
 
+	} else if unicode.IsDigit(r) {
+
+		return lexKeyframeSelector, nil
+
 	} else if r == '[' {
 
 		return lexAttributeSelector, nil
@@ -310,17 +362,17 @@ func lexSelectors(l *Lexer) (stateFn, error) {
 
 		return lexClassSelector, nil
 
+	} else if r == '%' {
+
+		return lexPlaceholderSelector, nil
+
 	} else if r == ':' {
 
 		return lexPseudoSelector, nil
 
 	} else if r == '&' {
 
-		if err := l.expect("&"); err != nil {
-			return nil, err
-		}
-		l.emit(ast.T_PARENT_SELECTOR)
-		return lexSelectors, nil
+		return lexParentSelector, nil
 
 	} else if r == '*' {
 
@@ -408,6 +460,14 @@ func lexSelectors(l *Lexer) (stateFn, error) {
 
 		return lexStart, nil
 
+	} else if r == '!' {
+
+		// @extend .foo !optional;
+		if l.match("!optional") {
+			l.emit(ast.T_FLAG_OPTIONAL)
+			return lexStart, nil
+		}
+
 	}
 
 	return nil, l.errorf("Unexpected token '%c' for lexing selector.", r)
@@ -445,6 +505,26 @@ func lexTypeSelector(l *Lexer) (stateFn, error) {
 	return lexSimpleSelector, nil
 }
 
+/*
+lexKeyframeSelector lexes the percentage selectors of the keyframes, they
+are type selectors like `from` and `to`:
+
+	@keyframes fade { 0% { } 50.5% { } }
+*/
+func lexKeyframeSelector(l *Lexer) (stateFn, error) {
+	var r = l.next()
+	for unicode.IsDigit(r) || r == '.' {
+		r = l.next()
+	}
+
+	if r != '%' {
+		return nil, l.errorf("Expecting '%%' for keyframe selector. got %c", r)
+	}
+
+	l.emit(ast.T_TYPE_SELECTOR)
+	return lexSimpleSelector, nil
+}
+
 //func lexLang(l *Lexer) stateFn {
 //[>
 //html:lang(fr-ca) { quotes: '« ' ' »' }
@@ -484,7 +564,7 @@ func lexIdSelector(l *Lexer) (stateFn, error) {
 	var foundInterpolation = false
 	l.next()
 	var r = l.next()
-	if !unicode.IsLetter(r) && r != '#' && l.peek() != '{' {
+	if !unicode.IsLetter(r) && r != '-' && r != '_' && !IsInterpolationStartToken(r, l.peek()) {
 		return nil, l.errorf("An identifier should start with at least a letter, Got '%c'", r)
 	}
 	for {
@@ -495,19 +575,13 @@ func lexIdSelector(l *Lexer) (stateFn, error) {
 			}
 
 			foundInterpolation = true
-		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
 			break
 		}
 		r = l.next()
 	}
 	l.backup()
 
-	r = l.next()
-	for unicode.IsLetter(r) || unicode.IsDigit(r) {
-		r = l.next()
-	}
-	l.backup()
-
 	if foundInterpolation {
 		l.emit(ast.T_INTERPOLATION_SELECTOR)
 	} else {
@@ -515,3 +589,54 @@ func lexIdSelector(l *Lexer) (stateFn, error) {
 	}
 	return lexSelectors, nil
 }
+
+// lexParentSelector lexes `&` together with its suffix, e.g. `&--active`
+func lexParentSelector(l *Lexer) (stateFn, error) {
+	if err := l.expect("&"); err != nil {
+		return nil, err
+	}
+
+	for r := l.peek(); unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_'; r = l.peek() {
+		l.next()
+	}
+
+	l.emit(ast.T_PARENT_SELECTOR)
+	return lexSelectors, nil
+}
+
+/*
+lexPseudoArgument lexes the argument of a functional pseudo selector as it
+is written, the argument may be a selector with interpolation:
+
+	:not(.#{$name})
+	:nth-child(2n+1)
+*/
+func lexPseudoArgument(l *Lexer) error {
+	var foundInterpolation = false
+	var depth = 0
+
+	l.ignoreSpaces()
+
+	var r = l.next()
+	for r != EOF && (depth > 0 || r != ')') {
+		switch {
+		case IsInterpolationStartToken(r, l.peek()):
+			l.backup()
+			if _, err := lexInterpolation(l, false); err != nil {
+				return err
+			}
+			foundInterpolation = true
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		}
+		r = l.next()
+	}
+	l.backup()
+
+	if l.Offset > l.Start {
+		l.emit(ast.T_IDENT, foundInterpolation)
+	}
+	return nil
+}
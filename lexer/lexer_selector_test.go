@@ -15,6 +15,35 @@ func TestLexerClassNameSelector(t *testing.T) {
 	AssertTokenSequence(t, l, []ast.TokenType{ast.T_CLASS_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE})
 }
 
+func TestLexerClassNameSelectorWithDigits(t *testing.T) {
+	AssertLexerTokenSequence(t, `.col-12 { }`, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE,
+	})
+}
+
+func TestLexerPlaceholderSelector(t *testing.T) {
+	AssertLexerTokenSequence(t, `%button-base, a%b { }`, []ast.TokenType{
+		ast.T_PLACEHOLDER_SELECTOR, ast.T_COMMA,
+		ast.T_TYPE_SELECTOR, ast.T_PLACEHOLDER_SELECTOR,
+		ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE,
+	})
+}
+
+func TestLexerKeyframeSelector(t *testing.T) {
+	AssertLexerTokenSequence(t, `0%, 33.3% { }`, []ast.TokenType{
+		ast.T_TYPE_SELECTOR, ast.T_COMMA, ast.T_TYPE_SELECTOR,
+		ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE,
+	})
+}
+
+func TestLexerExtendOptional(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { @extend .b !optional; }`, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_OPEN,
+		ast.T_EXTEND, ast.T_CLASS_SELECTOR, ast.T_FLAG_OPTIONAL, ast.T_SEMICOLON,
+		ast.T_BRACE_CLOSE,
+	})
+}
+
 func TestLexerRuleWithTagNameSelector(t *testing.T) {
 	l := NewLexerWithString(`a {  }`)
 	assert.NotNil(t, l)
@@ -375,6 +404,11 @@ func TestLexerRuleWithSubRuleWithParentSelector(t *testing.T) {
 		ast.T_BRACE_CLOSE})
 }
 
+func TestLexerParentSelectorSuffix(t *testing.T) {
+	AssertLexerTokenSequence(t, `&--active {  }`, []ast.TokenType{ast.T_PARENT_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE})
+	AssertLexerTokenSequence(t, `&__el:hover {  }`, []ast.TokenType{ast.T_PARENT_SELECTOR, ast.T_PSEUDO_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE})
+}
+
 func TestLexerSelectorPseudoElementBefore(t *testing.T) {
 	AssertLexerTokenSequence(t, `::before {  }`, []ast.TokenType{ast.T_PSEUDO_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE})
 	AssertLexerTokenSequence(t, `::after {  }`, []ast.TokenType{ast.T_PSEUDO_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE})
@@ -389,6 +423,17 @@ func TestLexerSelectorInterpolationPrefix(t *testing.T) {
 	AssertLexerTokenSequence(t, `#{ abc }#myPost {  }`, []ast.TokenType{ast.T_INTERPOLATION_SELECTOR, ast.T_ID_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE})
 }
 
+func TestLexerSelectorInterpolationAfterClassPrefix(t *testing.T) {
+	AssertLexerTokenSequence(t, `.#{ abc }-b {  }`, []ast.TokenType{ast.T_CLASS_SELECTOR, ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE})
+}
+
+func TestLexerSelectorInterpolationInFunctionalPseudo(t *testing.T) {
+	AssertLexerTokenSequence(t, `a:not(.#{ abc }) {  }`, []ast.TokenType{
+		ast.T_TYPE_SELECTOR, ast.T_FUNCTIONAL_PSEUDO, ast.T_PAREN_OPEN, ast.T_IDENT, ast.T_PAREN_CLOSE,
+		ast.T_BRACE_OPEN, ast.T_BRACE_CLOSE,
+	})
+}
+
 func TestLexerSelectorInterpolationWithPseudoSelector(t *testing.T) {
 	l := NewLexerWithString(`#{ abc }:hover {  }`)
 	assert.NotNil(t, l)
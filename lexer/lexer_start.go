@@ -88,7 +88,11 @@ func lexStart(l *Lexer) (stateFn, error) {
 			return lexSelectors, nil
 		}
 
-	case '[', '*', '>', '&', '.', '+', ':':
+	case '[', '*', '>', '&', '.', '+', ':', '%':
+		return lexSelectors, nil
+
+	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		// the percentage selectors of the keyframes
 		return lexSelectors, nil
 
 	}
@@ -13,7 +13,7 @@ const LETTERS = "zxcvbnmasdfghjklqwertyuiop"
 const DIGITS = "1234567890"
 
 func (l *Lexer) errorf(msg string, r rune) error {
-	return fmt.Errorf(msg, string(r))
+	return fmt.Errorf(msg, r)
 }
 
 /*
@@ -197,8 +197,8 @@ func lexHexColor(l *Lexer) (stateFn, error) {
 	l.backup()
 
 	var length = l.length() - 1
-	if length != 3 && length != 6 && length != 8 {
-		return nil, fmt.Errorf("Invalid hex color, expecting 3, 6 or 8 hex characters, got %d - %s", length, l.current())
+	if length != 3 && length != 4 && length != 6 && length != 8 {
+		return nil, fmt.Errorf("Invalid hex color, expecting 3, 4, 6 or 8 hex characters, got %d - %s", length, l.current())
 	}
 	l.emit(ast.T_HEX_COLOR)
 	return lexExpr, nil
@@ -106,6 +106,31 @@ func TestLexerAtRuleImportWithUnquoteUrl(t *testing.T) {
 	})
 }
 
+func TestLexerAtRuleUse(t *testing.T) {
+	AssertLexerTokenSequence(t, `@use "lib/colors" as c;`, []ast.TokenType{
+		ast.T_USE, ast.T_QQ_STRING, ast.T_IDENT, ast.T_IDENT, ast.T_SEMICOLON,
+	})
+}
+
+func TestLexerNamespacedMembers(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { color: c.$primary; width: c.double(2px); @include c.theme; }`, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_OPEN,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_VARIABLE, ast.T_SEMICOLON,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_FUNCTION_NAME, ast.T_PAREN_OPEN, ast.T_INTEGER, ast.T_UNIT_PX, ast.T_PAREN_CLOSE, ast.T_SEMICOLON,
+		ast.T_INCLUDE, ast.T_IDENT, ast.T_SEMICOLON,
+		ast.T_BRACE_CLOSE,
+	})
+}
+
+func TestLexerKeywordPrefixedIdentifiers(t *testing.T) {
+	AssertLexerTokenSequence(t, `.a { b: to-upper-case(x); c: not-allowed; }`, []ast.TokenType{
+		ast.T_CLASS_SELECTOR, ast.T_BRACE_OPEN,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_FUNCTION_NAME, ast.T_PAREN_OPEN, ast.T_IDENT, ast.T_PAREN_CLOSE, ast.T_SEMICOLON,
+		ast.T_PROPERTY_NAME_TOKEN, ast.T_COLON, ast.T_IDENT, ast.T_SEMICOLON,
+		ast.T_BRACE_CLOSE,
+	})
+}
+
 func TestLexerRuleWithOneProperty(t *testing.T) {
 	AssertLexerTokenSequence(t, `.test { color: #fff; }`, []ast.TokenType{
 		ast.T_CLASS_SELECTOR,
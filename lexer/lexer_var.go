@@ -14,6 +14,11 @@ func lexVariableName(l *Lexer) (stateFn, error) {
 	}
 
 	r = l.next()
+	// private members of modules start with '-' or '_'
+	if (r == '-' || r == '_') && unicode.IsLetter(l.peek()) {
+		r = l.next()
+	}
+
 	if !unicode.IsLetter(r) {
 		return nil, l.errorf("The first character of a variable name must be letter. Got '%c'", r)
 	}
@@ -33,7 +38,7 @@ func lexVariableName(l *Lexer) (stateFn, error) {
 			}
 		} else if r == ':' {
 			break
-		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+		} else if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
 			break
 		} else if r == '}' {
 			l.backup()
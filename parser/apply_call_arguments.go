@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/c9s/c6/ast"
 )
@@ -16,13 +18,8 @@ func ApplyCallArguments(protoList *ast.ArgumentList, callList *ast.CallArgumentL
 	kwMap := map[string]*ast.CallArgument{}
 	kwArgsIdx := len(callList.Args)
 
-	var spreadInProtoFound bool
 	var spreadInCallSite *ast.Variable
 
-	if l := len(protoList.Arguments); l > 0 && protoList.Arguments[l-1].VariableLength {
-		spreadInProtoFound = true
-	}
-
 	if l := len(callList.Args); l > 0 && callList.Args[l-1].VariableLength {
 		v, ok := callList.Args[l-1].Value.(*ast.Variable)
 		if !ok {
@@ -34,7 +31,7 @@ func ApplyCallArguments(protoList *ast.ArgumentList, callList *ast.CallArgumentL
 
 	for idx, arg := range callList.Args {
 		if arg.Name != nil {
-			if spreadInProtoFound || spreadInCallSite != nil {
+			if spreadInCallSite != nil {
 				return nil, fmt.Errorf("Named arguments cannot work with spread operator")
 			}
 
@@ -52,6 +49,14 @@ func ApplyCallArguments(protoList *ast.ArgumentList, callList *ast.CallArgumentL
 
 	}
 
+	if protoList == nil {
+		protoList = ast.NewArgumentList()
+	}
+
+	if err := checkCallArguments(protoList, callList, kwArgsIdx, spreadInCallSite != nil); err != nil {
+		return nil, err
+	}
+
 	for idx, proto := range protoList.Arguments {
 		var val ast.Expr
 
@@ -64,11 +69,20 @@ func ApplyCallArguments(protoList *ast.ArgumentList, callList *ast.CallArgumentL
 			} else {
 
 				list := ast.NewList(" ")
-				if idx < len(callList.Args) {
-					for _, callArg := range callList.Args[idx:] {
+				if idx < kwArgsIdx {
+					for _, callArg := range callList.Args[idx:kwArgsIdx] {
 						list.Append(callArg.Value)
 					}
 				}
+
+				// the named arguments which are not declared are the
+				// keywords of the argument list
+				list.Keywords = ast.NewMap()
+				for _, callArg := range callList.Args[kwArgsIdx:] {
+					if !declaresArgument(protoList, callArg.Name) {
+						list.Keywords.Set(ast.NewString(0, strings.TrimPrefix(callArg.Name.Name, "$"), nil), callArg.Value)
+					}
+				}
 				val = list
 			}
 
@@ -95,3 +109,79 @@ func ApplyCallArguments(protoList *ast.ArgumentList, callList *ast.CallArgumentL
 
 	return out, nil
 }
+
+// declaresArgument tells whether the prototype has an argument named like
+// the variable
+func declaresArgument(protoList *ast.ArgumentList, name *ast.Variable) bool {
+	for _, proto := range protoList.Arguments {
+		if ast.NewVariableWithToken(proto.Name).NormalizedName() == name.NormalizedName() {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+checkCallArguments rejects the arguments the prototype can't take, unless it
+ends with an argument list which takes them all:
+
+	@function foo($a) { ... }
+
+	foo(1, 2)     // Only 1 argument allowed, but 2 were passed.
+	foo($b: 1)    // No argument named $b.
+	foo(1, $a: 2) // Argument $a was passed both by position and by name.
+*/
+func checkCallArguments(protoList *ast.ArgumentList, callList *ast.CallArgumentList, kwArgsIdx int, spread bool) error {
+	restArgs := len(protoList.Arguments) > 0 && protoList.Arguments[len(protoList.Arguments)-1].VariableLength
+
+	if !restArgs && !spread && kwArgsIdx > len(protoList.Arguments) {
+		return fmt.Errorf("Only %d %s allowed, but %d %s passed.",
+			len(protoList.Arguments), pluralize("argument", len(protoList.Arguments)),
+			kwArgsIdx, pluralizeVerb(kwArgsIdx))
+	}
+
+	unknown := []string{}
+
+	for _, arg := range callList.Args[kwArgsIdx:] {
+		idx := slices.IndexFunc(protoList.Arguments, func(proto *ast.Argument) bool {
+			return !proto.VariableLength && ast.NewVariableWithToken(proto.Name).NormalizedName() == arg.Name.NormalizedName()
+		})
+
+		if idx >= 0 && idx < kwArgsIdx {
+			return fmt.Errorf("Argument %s was passed both by position and by name.", arg.Name.Name)
+		}
+
+		if idx < 0 {
+			unknown = append(unknown, arg.Name.Name)
+		}
+	}
+
+	if !restArgs && len(unknown) > 0 {
+		names := unknown[0]
+
+		if len(unknown) > 1 {
+			names = strings.Join(unknown[:len(unknown)-1], ", ") + " or " + unknown[len(unknown)-1]
+		}
+
+		return fmt.Errorf("No %s named %s.", pluralize("argument", len(unknown)), names)
+	}
+
+	return nil
+}
+
+func pluralize(word string, count int) string {
+	if count == 1 {
+		return word
+	}
+
+	return word + "s"
+}
+
+func pluralizeVerb(count int) string {
+	if count == 1 {
+		return "was"
+	}
+
+	return "were"
+}
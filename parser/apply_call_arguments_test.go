@@ -43,7 +43,13 @@ func TestApplyCallArguments(t *testing.T) {
 			description: "spread in proto",
 			args:        "1, 2, 3",
 			proto:       "$a, $b...",
-			expected:    "$a: 1, $b: [2 3]",
+			expected:    "$a: 1, $b: 2 3",
+		},
+		{
+			description: "keyword args in spread proto",
+			args:        "1, 2, $c: 4",
+			proto:       "$a, $rest...",
+			expected:    "$a: 1, $rest: 2",
 		},
 		{
 			description: "spread in callsite",
@@ -86,3 +92,56 @@ func TestApplyCallArguments(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyCallArgumentsErrors(t *testing.T) {
+	var ex = []struct {
+		description string
+		args        string
+		proto       string
+		expected    string
+	}{
+		{
+			description: "too many positional arguments",
+			args:        "1, 2, 3",
+			proto:       "$a, $b",
+			expected:    "Only 2 arguments allowed, but 3 were passed.",
+		},
+		{
+			description: "arguments without prototype",
+			args:        "1",
+			proto:       "",
+			expected:    "Only 0 arguments allowed, but 1 was passed.",
+		},
+		{
+			description: "unknown keyword args",
+			args:        "1, $c: 2, $d: 3",
+			proto:       "$a, $b: 2",
+			expected:    "No arguments named $c or $d.",
+		},
+		{
+			description: "keyword arg passed by position",
+			args:        "1, $a: 2",
+			proto:       "$a, $b: 2",
+			expected:    "Argument $a was passed both by position and by name.",
+		},
+	}
+
+	for _, ex := range ex {
+		t.Run(ex.description, func(t *testing.T) {
+			stmts, err := RunParserTest(fmt.Sprintf(`@include abc(%s);`, ex.args))
+			require.NoError(t, err)
+
+			includeStmt, ok := stmts.Stmts[0].(*ast.IncludeStmt)
+			require.True(t, ok)
+
+			stmts, err = RunParserTest(fmt.Sprintf(`@mixin abc(%s) {};`, ex.proto))
+			require.NoError(t, err)
+
+			mixinStmt, ok := stmts.Stmts[0].(*ast.MixinStmt)
+			require.True(t, ok)
+
+			_, err = ApplyCallArguments(mixinStmt.ArgumentList, includeStmt.ArgumentList)
+			require.EqualError(t, err, ex.expected)
+		})
+	}
+}
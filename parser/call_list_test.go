@@ -31,7 +31,7 @@ func TestCallArguments(t *testing.T) {
 			description: "multiple expressions including list",
 			args:        "$a, 2 + 3, 4px 5px 6px",
 			argNum:      3,
-			expected:    "$a, (2+3), [4px 5px 6px]",
+			expected:    "$a, (2+3), 4px 5px 6px",
 		},
 		{
 			description: "spread operator",
@@ -49,7 +49,7 @@ func TestCallArguments(t *testing.T) {
 			description: "multiple named arguments",
 			args:        "$a, 2 + 3, $g: 1px 2px 3px, $c: 2px",
 			argNum:      4,
-			expected:    "$a, (2+3), $g: [1px 2px 3px], $c: 2px",
+			expected:    "$a, (2+3), $g: 1px 2px 3px, $c: 2px",
 		},
 	}
 
@@ -159,7 +159,7 @@ func (parser *Parser) accept(tokenType ast.TokenType) *ast.Token {
 
 func (parser *Parser) acceptAnyOf2(tokType1, tokType2 ast.TokenType) *ast.Token {
 	var tok = parser.next()
-	if tok.Type == tokType1 || tok.Type == tokType2 {
+	if tok != nil && (tok.Type == tokType1 || tok.Type == tokType2) {
 		return tok
 	}
 	parser.backup()
@@ -168,7 +168,7 @@ func (parser *Parser) acceptAnyOf2(tokType1, tokType2 ast.TokenType) *ast.Token
 
 func (parser *Parser) acceptAnyOf3(tokType1, tokType2, tokType3 ast.TokenType) *ast.Token {
 	var tok = parser.next()
-	if tok.Type == tokType1 || tok.Type == tokType2 || tok.Type == tokType3 {
+	if tok != nil && (tok.Type == tokType1 || tok.Type == tokType2 || tok.Type == tokType3) {
 		return tok
 	}
 	parser.backup()
@@ -177,7 +177,20 @@ func (parser *Parser) acceptAnyOf3(tokType1, tokType2, tokType3 ast.TokenType) *
 
 func (parser *Parser) expect(tokenType ast.TokenType) (*ast.Token, error) {
 	var tok = parser.next()
-	if tok != nil && tok.Type != tokenType {
+	if tok == nil {
+		parser.backup()
+
+		// the end of input ends the last statement as well
+		if tokenType == ast.T_SEMICOLON {
+			return nil, nil
+		}
+
+		return nil, SyntaxError{
+			Reason: fmt.Sprintf("expected: %s, got the end of input", tokenType.String()),
+			File:   parser.File,
+		}
+	}
+	if tok.Type != tokenType {
 		parser.backup()
 		return nil, SyntaxError{
 			Reason:      fmt.Sprintf("expected: %s", tokenType.String()),
@@ -106,6 +106,18 @@ func TestParserNestedRuleWithParentSelector(t *testing.T) {
 
 }
 
+func TestParserRuleSetWithInterpolatedSelector(t *testing.T) {
+	stmts, err := RunParserTest(`.icon-#{$name} > li:nth-child(#{$n}) { color: red; }`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	rs := stmts.Stmts[0].(*ast.RuleSet)
+	assert.Nil(t, rs.Selectors)
+	require.IsType(t, &ast.LiteralConcat{}, rs.SelectorTemplate)
+	assert.Equal(t, ".icon-$name > li:nth-child($n)", rs.SelectorTemplate.String())
+	assert.Equal(t, 1, len(rs.Block.Stmts.Stmts))
+}
+
 func TestParserPropertyNameBorderWidth(t *testing.T) {
 	stmts, err := RunParserTest(`div { border-width: 3px 3px 3px 3px; }`)
 	require.NoError(t, err)
@@ -122,8 +134,25 @@ func TestParserNestedProperty(t *testing.T) {
 	}`)
 	require.NoError(t, err)
 	assert.Equal(t, 1, len(stmts.Stmts))
-	assert.Equal(t, 1, len(stmts.Stmts))
 
+	prop := stmts.Stmts[0].(*ast.RuleSet).Block.Stmts.Stmts[0].(*ast.Property)
+	assert.Equal(t, "border", prop.Name.Name)
+	assert.Equal(t, 0, len(prop.Values))
+	require.NotNil(t, prop.Block)
+	assert.Equal(t, 2, len(prop.Block.Stmts.Stmts))
+}
+
+func TestParserNestedPropertyWithValue(t *testing.T) {
+	stmts, err := RunParserTest(`div { margin: 0 { left: 4px } color: red; }`)
+	require.NoError(t, err)
+
+	block := stmts.Stmts[0].(*ast.RuleSet).Block
+	require.Equal(t, 2, len(block.Stmts.Stmts))
+
+	prop := block.Stmts.Stmts[0].(*ast.Property)
+	assert.Equal(t, 1, len(prop.Values))
+	require.NotNil(t, prop.Block)
+	assert.Equal(t, 1, len(prop.Block.Stmts.Stmts))
 }
 
 func TestParserPropertyNameBorderWidthInterpolation(t *testing.T) {
@@ -147,6 +176,33 @@ func TestParserPropertyNameBorderWidthInterpolation3(t *testing.T) {
 
 }
 
+func TestParserPropertyNameInterpolationTemplate(t *testing.T) {
+	stmts, err := RunParserTest(`.a { #{$prefix}-transition: none; }`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	prop := stmts.Stmts[0].(*ast.RuleSet).Block.Stmts.Stmts[0].(*ast.Property)
+	assert.True(t, prop.Name.Interpolation)
+	require.IsType(t, &ast.LiteralConcat{}, prop.Name.Template)
+	assert.Equal(t, "$prefix-transition", prop.Name.Template.String())
+}
+
+func TestParserAtRule(t *testing.T) {
+	stmts, err := RunParserTest(`@supports (display: #{$d}) { .a { b: c; } } @custom;`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(stmts.Stmts))
+
+	supports := stmts.Stmts[0].(*ast.AtRuleStmt)
+	assert.Equal(t, "supports", supports.Name)
+	assert.IsType(t, &ast.LiteralConcat{}, supports.Prelude)
+	assert.NotNil(t, supports.Block)
+
+	custom := stmts.Stmts[1].(*ast.AtRuleStmt)
+	assert.Equal(t, "custom", custom.Name)
+	assert.Nil(t, custom.Prelude)
+	assert.Nil(t, custom.Block)
+}
+
 func TestParserImportRuleWithUnquoteUrl(t *testing.T) {
 	stmts, err := RunParserTest(`@import url(../foo.css);`)
 	require.NoError(t, err)
@@ -202,6 +258,105 @@ func TestParserImportRuleWithMedia2(t *testing.T) {
 	assert.Equal(t, 1, len(stmts.Stmts))
 }
 
+func TestParserUseRule(t *testing.T) {
+	stmts, err := RunParserTest(`@use "lib/colors";`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	rule, ok := stmts.Stmts[0].(*ast.UseStmt)
+	require.True(t, ok)
+	assert.Equal(t, "lib/colors", rule.Path.Value)
+	assert.Equal(t, "", rule.Namespace)
+}
+
+func TestParserUseRuleWithNamespace(t *testing.T) {
+	for code, namespace := range map[string]string{
+		`@use "lib/colors" as c;`: "c",
+		`@use 'lib/colors' as *;`: "*",
+	} {
+		stmts, err := RunParserTest(code)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(stmts.Stmts))
+
+		rule, ok := stmts.Stmts[0].(*ast.UseStmt)
+		require.True(t, ok)
+		assert.Equal(t, namespace, rule.Namespace)
+	}
+}
+
+func TestParserUseRuleWithConfiguration(t *testing.T) {
+	stmts, err := RunParserTest(`@use "lib" as l with ($primary: red, $padding: 1px 2px);`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	rule, ok := stmts.Stmts[0].(*ast.UseStmt)
+	require.True(t, ok)
+	assert.Equal(t, "l", rule.Namespace)
+	require.NotNil(t, rule.With)
+	require.Equal(t, 2, len(rule.With.Items))
+	assert.IsType(t, &ast.Variable{}, rule.With.Items[0].Key)
+	assert.IsType(t, &ast.List{}, rule.With.Items[1].Value)
+}
+
+func TestParserAssignStmtListWithDefaultFlag(t *testing.T) {
+	stmts, err := RunParserTest(`$padding: 1px 2px !default; $a: 1;`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(stmts.Stmts))
+
+	stm, ok := stmts.Stmts[0].(*ast.AssignStmt)
+	require.True(t, ok)
+	assert.True(t, stm.Default)
+	assert.IsType(t, &ast.List{}, stm.Expr)
+}
+
+func TestParserAssignStmtBracketedList(t *testing.T) {
+	stmts, err := RunParserTest(`$a: [full-start] 1fr; $b: [a, b]; $c: [];`)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(stmts.Stmts))
+
+	a := stmts.Stmts[0].(*ast.AssignStmt).Expr.(*ast.List)
+	assert.False(t, a.Bracketed)
+	require.Equal(t, 2, a.Len())
+	assert.True(t, a.Exprs[0].(*ast.List).Bracketed)
+
+	b := stmts.Stmts[1].(*ast.AssignStmt).Expr.(*ast.List)
+	assert.True(t, b.Bracketed)
+	assert.Equal(t, ", ", b.Separator)
+	assert.Equal(t, 2, b.Len())
+
+	c := stmts.Stmts[2].(*ast.AssignStmt).Expr.(*ast.List)
+	assert.True(t, c.Bracketed)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestParserForwardRule(t *testing.T) {
+	stmts, err := RunParserTest(`@forward "src/list" as list-* show list-reset, $gap, item-*;`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	rule, ok := stmts.Stmts[0].(*ast.ForwardStmt)
+	require.True(t, ok)
+	assert.Equal(t, "src/list", rule.Path.Value)
+	assert.Equal(t, "list-", rule.Prefix)
+	assert.Equal(t, []string{"list-reset", "$gap", "item-*"}, rule.Show)
+	assert.Nil(t, rule.Hide)
+}
+
+func TestParserForwardRuleHide(t *testing.T) {
+	stmts, err := RunParserTest(`@forward "src/list" hide $gap-*;`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	rule, ok := stmts.Stmts[0].(*ast.ForwardStmt)
+	require.True(t, ok)
+	assert.Equal(t, []string{"$gap-*"}, rule.Hide)
+}
+
+func TestParserForwardRuleInvalidPrefix(t *testing.T) {
+	_, err := RunParserTest(`@forward "src/list" as list;`)
+	assert.Error(t, err)
+}
+
 func TestParserMediaQuerySimple(t *testing.T) {
 	stmts, err := RunParserTest(`@media screen { .red { color: red; } }`)
 	require.NoError(t, err)
@@ -460,6 +615,43 @@ $i: 6;
 	assert.Equal(t, 2, len(stmts.Stmts))
 }
 
+func TestParserEachStmt(t *testing.T) {
+	stmts, err := RunParserTest(`@each $name in a, b, c { }`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	each, ok := stmts.Stmts[0].(*ast.EachStmt)
+	require.True(t, ok)
+	assert.Equal(t, 1, len(each.Variables))
+	assert.IsType(t, &ast.List{}, each.Expr)
+}
+
+func TestParserEachStmtDestructuring(t *testing.T) {
+	stmts, err := RunParserTest(`@each $key, $value in (a: 1, b: 2) { }`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	each, ok := stmts.Stmts[0].(*ast.EachStmt)
+	require.True(t, ok)
+	assert.Equal(t, 2, len(each.Variables))
+	assert.IsType(t, &ast.Map{}, each.Expr)
+}
+
+func TestParserUnexpectedEndOfInput(t *testing.T) {
+	var buffers = []string{
+		`@each $a in a`,
+		`@each $a in a {`,
+		`@for $i from 1 through 3`,
+		`@media screen`,
+		`a { b: c`,
+	}
+
+	for _, buffer := range buffers {
+		_, err := RunParserTest(buffer)
+		require.Error(t, err, buffer)
+	}
+}
+
 func TestParserCSS3Gradient(t *testing.T) {
 	// some test cases from htmldog
 	// @see http://www.htmldog.com/guides/css/advanced/gradients/
@@ -503,6 +695,43 @@ func TestParserFontCssSlash(t *testing.T) {
 	assert.Equal(t, 1, len(stmts.Stmts))
 }
 
+func TestParserDivisionIsLeftAssociative(t *testing.T) {
+	stmts, err := RunParserTest(`$a: 8px/2/2; $b: (8px/2);`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(stmts.Stmts))
+
+	a := stmts.Stmts[0].(*ast.AssignStmt).Expr.(*ast.BinaryExpr)
+	assert.False(t, a.Grouped)
+	assert.IsType(t, &ast.BinaryExpr{}, a.Left)
+	assert.IsType(t, &ast.Number{}, a.Right)
+
+	b := stmts.Stmts[1].(*ast.AssignStmt).Expr.(*ast.BinaryExpr)
+	assert.True(t, b.Grouped)
+}
+
+func TestParserUnaryMinusInList(t *testing.T) {
+	stmts, err := RunParserTest(`$a: 10px -5px; $b: 10px - 5px; $c: 10px-5px;`)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(stmts.Stmts))
+
+	a := stmts.Stmts[0].(*ast.AssignStmt).Expr.(*ast.List)
+	assert.Equal(t, 2, len(a.Exprs))
+	assert.IsType(t, &ast.BinaryExpr{}, stmts.Stmts[1].(*ast.AssignStmt).Expr)
+	assert.IsType(t, &ast.BinaryExpr{}, stmts.Stmts[2].(*ast.AssignStmt).Expr)
+}
+
+func TestParserSpecialFunctionKeepsText(t *testing.T) {
+	stmts, err := RunParserTest(`$a: -webkit-calc(100% - 10px); $b: var(--gap);`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(stmts.Stmts))
+
+	a := stmts.Stmts[0].(*ast.AssignStmt).Expr.(*ast.FunctionCall)
+	assert.Equal(t, "-webkit-calc(100% - 10px)", a.String())
+
+	b := stmts.Stmts[1].(*ast.AssignStmt).Expr.(*ast.FunctionCall)
+	assert.Equal(t, "var(--gap)", b.String())
+}
+
 func TestParserAssignStmtWithBooleanTrue(t *testing.T) {
 	block, err := RunParserTest(`$foo: true;`)
 	require.NoError(t, err)
@@ -539,6 +768,17 @@ func TestParserAssignStmtMap(t *testing.T) {
 	assert.Equal(t, 1, len(stmts.Stmts))
 }
 
+func TestParserAssignStmtNestedMap(t *testing.T) {
+	stmts, err := RunParserTest(`$foo: (color: (primary: blue), space: 4px);`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	m, ok := stmts.Stmts[0].(*ast.AssignStmt).Expr.(*ast.Map)
+	require.True(t, ok)
+	require.Equal(t, 2, m.Len())
+	assert.IsType(t, &ast.Map{}, m.Items[0].Value)
+}
+
 func TestParserAssignStmtCommaSepList(t *testing.T) {
 	stmts, err := RunParserTest(`$foo: (1,2,3,4);`)
 	require.NoError(t, err)
@@ -705,6 +945,79 @@ func TestParserExtendComplexSelector(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestParserExtendPlaceholderSelector(t *testing.T) {
+	stmts, err := RunParserTest(`@extend %button-base;`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	stmt, ok := stmts.Stmts[0].(*ast.ExtendStmt)
+	require.True(t, ok)
+	assert.Equal(t, "%button-base", stmt.Selectors.String())
+	assert.False(t, stmt.Optional)
+}
+
+func TestParserExtendOptional(t *testing.T) {
+	stmts, err := RunParserTest(`@extend .foo !optional;`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	stmt, ok := stmts.Stmts[0].(*ast.ExtendStmt)
+	require.True(t, ok)
+	assert.True(t, stmt.Optional)
+}
+
+func TestParserAtRootSelectorList(t *testing.T) {
+	stmts, err := RunParserTest(`@at-root .a, .b { c: d; }`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	stmt, ok := stmts.Stmts[0].(*ast.AtRootStmt)
+	require.True(t, ok)
+	assert.Equal(t, ".a, .b", stmt.Selectors.String())
+	assert.Nil(t, stmt.Query)
+	assert.True(t, stmt.Excludes("rule"))
+	assert.False(t, stmt.Excludes("media"))
+}
+
+func TestParserAtRootQuery(t *testing.T) {
+	var data = []struct {
+		code     string
+		query    string
+		excluded []string
+		kept     []string
+	}{
+		{`@at-root (without: media) { a: b; }`, "(without: media)", []string{"media"}, []string{"rule", "supports"}},
+		{`@at-root (with: rule) { a: b; }`, "(with: rule)", []string{"media", "supports"}, []string{"rule"}},
+		{`@at-root (without: all) { a: b; }`, "(without: all)", []string{"media", "rule", "supports"}, nil},
+		{`@at-root (with: media supports) { a: b; }`, "(with: media supports)", []string{"rule"}, []string{"media", "supports"}},
+	}
+
+	for _, d := range data {
+		stmts, err := RunParserTest(d.code)
+		require.NoError(t, err, d.code)
+		require.Equal(t, 1, len(stmts.Stmts))
+
+		stmt, ok := stmts.Stmts[0].(*ast.AtRootStmt)
+		require.True(t, ok)
+		require.NotNil(t, stmt.Query)
+		assert.Equal(t, d.query, stmt.Query.String())
+
+		for _, name := range d.excluded {
+			assert.True(t, stmt.Excludes(name), "%s excludes %s", d.code, name)
+		}
+
+		for _, name := range d.kept {
+			assert.False(t, stmt.Excludes(name), "%s keeps %s", d.code, name)
+		}
+	}
+}
+
+func TestParserAtRootInvalidQuery(t *testing.T) {
+	_, err := RunParserTest(`@at-root (within: media) { a: b; }`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `Expected "with" or "without".`)
+}
+
 func TestParserInclude(t *testing.T) {
 	_, err := RunParserTest(`
 		@include apply-to-ie6-only;
@@ -722,6 +1035,50 @@ func TestParserIncludeWithContentBlock(t *testing.T) {
 	assert.Equal(t, 1, len(stmts.Stmts))
 }
 
+func TestParserIncludeWithContentBlockWithoutSemicolon(t *testing.T) {
+	stmts, err := RunParserTest(`
+		@include apply-to-ie6-only {
+			color: white;
+		}
+		@include apply-to-ie6-only;
+	`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, len(stmts.Stmts))
+}
+
+func TestParserIncludeUsingContentArguments(t *testing.T) {
+	stmts, err := RunParserTest(`
+		@include sizes(1px) using ($size, $unit: px) {
+			width: $size;
+		}
+	`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	stmt, ok := stmts.Stmts[0].(*ast.IncludeStmt)
+	require.True(t, ok)
+	require.NotNil(t, stmt.ContentArguments)
+	assert.Equal(t, 2, len(stmt.ContentArguments.Arguments))
+	assert.NotNil(t, stmt.ContentBlock)
+}
+
+func TestParserMixinContentDirectiveWithArguments(t *testing.T) {
+	stmts, err := RunParserTest(`
+@mixin sizes {
+  @content(1px, $unit: px);
+}
+	`)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts.Stmts))
+
+	mixin, ok := stmts.Stmts[0].(*ast.MixinStmt)
+	require.True(t, ok)
+
+	content, ok := mixin.Block.Stmts.Stmts[0].(*ast.ContentStmt)
+	require.True(t, ok)
+	assert.Equal(t, 2, len(content.Arguments.Args))
+}
+
 func TestParserFunctionSimple(t *testing.T) {
 	stmts, err := RunParserTest(`
 @function grid-width($n) {
@@ -7,8 +7,10 @@ package parser
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/c9s/c6/ast"
 	"github.com/c9s/c6/lexer"
@@ -75,6 +77,10 @@ func (parser *Parser) ParseStmt() (ast.Stmt, error) {
 	switch token.Type {
 	case ast.T_IMPORT:
 		return parser.ParseImportStmt()
+	case ast.T_USE:
+		return parser.ParseUseStmt()
+	case ast.T_FORWARD:
+		return parser.ParseForwardStmt()
 	case ast.T_CHARSET:
 		return parser.ParseCharsetStmt()
 	case ast.T_MEDIA:
@@ -99,12 +105,16 @@ func (parser *Parser) ParseStmt() (ast.Stmt, error) {
 		return parser.ParseForStmt()
 	case ast.T_WHILE:
 		return parser.ParseWhileStmt()
+	case ast.T_EACH:
+		return parser.ParseEachStmt()
 	case ast.T_CONTENT:
 		return parser.ParseContentStmt()
 	case ast.T_AT_ROOT:
 		return parser.ParseAtRootStmt()
 	case ast.T_ERROR, ast.T_WARN, ast.T_DEBUG:
 		return parser.ParseLogStmt()
+	case ast.T_AT_RULE, ast.T_PAGE:
+		return parser.ParseAtRuleStmt()
 	case ast.T_BRACKET_CLOSE:
 		return nil, nil
 	}
@@ -297,6 +307,10 @@ func (parser *Parser) ParseSimpleSelector(pos int) (ast.Selector, error) {
 
 		return ast.NewClassSelectorWithToken(tok), nil
 
+	case ast.T_PLACEHOLDER_SELECTOR:
+
+		return ast.NewPlaceholderSelectorWithToken(tok), nil
+
 	case ast.T_PARENT_SELECTOR:
 		if pos > 0 {
 			return nil, SyntaxError{
@@ -311,7 +325,8 @@ func (parser *Parser) ParseSimpleSelector(pos int) (ast.Selector, error) {
 	case ast.T_FUNCTIONAL_PSEUDO:
 
 		var sel = ast.NewFunctionalPseudoSelectorWithToken(tok)
-		if _, err := parser.expect(ast.T_PAREN_OPEN); err != nil {
+		openTok, err := parser.expect(ast.T_PAREN_OPEN)
+		if err != nil {
 			return nil, err
 		}
 
@@ -321,10 +336,16 @@ func (parser *Parser) ParseSimpleSelector(pos int) (ast.Selector, error) {
 			tok2 = parser.next()
 		}
 		parser.backup()
-		if _, err := parser.expect(ast.T_PAREN_CLOSE); err != nil {
+		closeTok, err := parser.expect(ast.T_PAREN_CLOSE)
+		if err != nil {
 			return nil, err
 		}
 
+		// the argument is kept as it is written, e.g. `2n+1`
+		if closeTok != nil && closeTok.Pos <= len(parser.Content) {
+			sel.C = strings.TrimSpace(parser.Content[openTok.Pos+1 : closeTok.Pos])
+		}
+
 		return sel, nil
 
 	case ast.T_PSEUDO_SELECTOR:
@@ -479,17 +500,174 @@ func (parser *Parser) ParseSelectorList() (*ast.ComplexSelectorList, error) {
 	return complexSelectorList, nil
 }
 
-func (parser *Parser) ParseExtendStmt() (ast.Stmt, error) {
-	if _, err := parser.expect(ast.T_EXTEND); err != nil {
+/*
+ParseSelectorTemplate parses the selector up to one of the stop tokens when
+it contains interpolation:
+
+	.icon-#{$name} > a:nth-child(#{$n})
+
+The selector can't be parsed before the interpolation is evaluated, the
+source text is kept as the literal concat of the text and interpolation,
+nil is returned when there is no interpolation.
+*/
+func (parser *Parser) ParseSelectorTemplate(stops ...ast.TokenType) (ast.Expr, error) {
+	debug("ParseSelectorTemplate")
+
+	var first = parser.peek()
+	if first == nil {
+		return nil, nil
+	}
+
+	var end = parser.Pos
+	var interpolated = false
+
+	for ; end < len(parser.Tokens) && !slices.Contains(stops, parser.Tokens[end].Type); end++ {
+		switch tok := parser.Tokens[end]; tok.Type {
+		case ast.T_INTERPOLATION_SELECTOR, ast.T_INTERPOLATION_START:
+			interpolated = true
+		default:
+			interpolated = interpolated || tok.ContainsInterpolation
+		}
+	}
+
+	if !interpolated || end == len(parser.Tokens) {
+		return nil, nil
+	}
+
+	text := strings.TrimSpace(parser.Content[first.Pos:parser.Tokens[end].Pos])
+	tmpl, err := parser.ParseInterpolatedText(text)
+
+	if err != nil {
 		return nil, err
 	}
-	var stm = ast.NewExtendStmt()
-	selectors, err := parser.ParseSelectorList()
+
+	parser.Pos = end
+	return tmpl, nil
+}
+
+/*
+ParseInterpolatedText parses the interpolation in the text, the text
+around is kept as unquoted strings:
+
+	.icon-#{$name} => LiteralConcat(".icon-", #{$name})
+*/
+func (parser *Parser) ParseInterpolatedText(text string) (ast.Expr, error) {
+	var expr ast.Expr
+
+	appendExpr := func(e ast.Expr) {
+		if expr == nil {
+			expr = e
+		} else {
+			expr = ast.NewLiteralConcat(expr, e)
+		}
+	}
+
+	for len(text) > 0 {
+		start := strings.Index(text, "#{")
+
+		if start < 0 {
+			appendExpr(ast.NewString(0, text, nil))
+			break
+		}
+
+		if start > 0 {
+			appendExpr(ast.NewString(0, text[:start], nil))
+		}
+
+		end := interpolationEnd(text, start)
+
+		if end < 0 {
+			return nil, SyntaxError{
+				Reason:      "Expected \"}\".",
+				ActualToken: parser.peek(),
+				File:        parser.File,
+			}
+		}
+
+		tokens, err := lexer.NewLexerWithString(text[start:end]).RunInterpolation()
+
+		if err != nil {
+			return nil, err
+		}
+
+		sub := &Parser{GlobalParser: parser.GlobalParser, File: parser.File, Tokens: tokens}
+		interpolation, err := sub.ParseInterpolation()
+
+		if err != nil {
+			return nil, err
+		}
+
+		appendExpr(interpolation)
+		text = text[end:]
+	}
+
+	return expr, nil
+}
+
+// interpolationEnd returns the offset after the "}" closing the
+// interpolation starting at the offset, -1 is returned when it's not closed
+func interpolationEnd(text string, start int) int {
+	var depth = 0
+	var quote byte = 0
+
+	for i := start + 1; i < len(text); i++ {
+		c := text[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return -1
+}
+
+func (parser *Parser) ParseExtendStmt() (ast.Stmt, error) {
+	tok, err := parser.expect(ast.T_EXTEND)
 	if err != nil {
 		return nil, err
 	}
+	var stm = ast.NewExtendStmtWithToken(tok)
+
+	if tmpl, err := parser.ParseSelectorTemplate(ast.T_SEMICOLON, ast.T_FLAG_OPTIONAL); err != nil {
+		return nil, err
+	} else if tmpl != nil {
+		stm.SelectorTemplate = tmpl
+	} else {
+		selectors, err := parser.ParseSelectorList()
+		if err != nil {
+			return nil, err
+		}
+
+		if selectors == nil {
+			return nil, SyntaxError{
+				Reason:      "Expected selector.",
+				ActualToken: parser.peek(),
+				File:        parser.File,
+			}
+		}
+
+		stm.Selectors = selectors
+	}
+
+	if parser.accept(ast.T_FLAG_OPTIONAL) != nil {
+		stm.Optional = true
+	}
 
-	stm.Selectors = selectors
 	if _, err := parser.expect(ast.T_SEMICOLON); err != nil {
 		return nil, err
 	}
@@ -498,13 +676,20 @@ func (parser *Parser) ParseExtendStmt() (ast.Stmt, error) {
 
 func (parser *Parser) ParseRuleSet() (ast.Stmt, error) {
 	var ruleset = ast.NewRuleSet()
-	selectors, err := parser.ParseSelectorList()
 
-	if err != nil {
+	if tmpl, err := parser.ParseSelectorTemplate(ast.T_BRACE_OPEN); err != nil {
 		return nil, err
-	}
+	} else if tmpl != nil {
+		ruleset.SelectorTemplate = tmpl
+	} else {
+		selectors, err := parser.ParseSelectorList()
+
+		if err != nil {
+			return nil, err
+		}
 
-	ruleset.Selectors = selectors
+		ruleset.Selectors = selectors
+	}
 
 	bl, err := parser.ParseDeclBlock()
 	if err != nil {
@@ -572,7 +757,7 @@ func (parser *Parser) ParseNumber() (ast.Expr, error) {
 		return nil, nil
 	}
 
-	if tok2.IsUnit() {
+	if tok2 != nil && tok2.IsUnit() {
 		// consume the unit token
 		parser.next()
 		return ast.NewNumber(val, ast.NewUnitWithToken(tok2), tok), nil
@@ -587,8 +772,21 @@ func (parser *Parser) ParseFunctionCall() (*ast.FunctionCall, error) {
 
 	var fcall = ast.NewFunctionCallWithToken(identTok)
 
+	if isSpecialFunction(identTok.Str) && parser.Content != "" {
+		return parser.parseSpecialFunctionCall(fcall)
+	}
+
+	var pos = parser.Pos
+
 	al, err := parser.ParseFunctionCallArguments()
 
+	// the arguments of calc() and var() which aren't sassscript are kept
+	// as they are written
+	if err != nil && isCalculation(identTok.Str) && parser.Content != "" {
+		parser.restore(pos)
+		return parser.parseSpecialFunctionCall(fcall)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -598,6 +796,94 @@ func (parser *Parser) ParseFunctionCall() (*ast.FunctionCall, error) {
 	return fcall, nil
 }
 
+/*
+isSpecialFunction tells whether the arguments of the css function are not
+sassscript, only the interpolation is evaluated in them:
+
+	-webkit-calc(100% - #{$gap})
+	expression(document.body.clientWidth)
+*/
+func isSpecialFunction(name string) bool {
+	name = strings.ToLower(name)
+
+	// vendor prefixed calc(), e.g. -webkit-calc or -moz-calc
+	if strings.HasPrefix(name, "-") && strings.HasSuffix(name, "-calc") {
+		return true
+	}
+
+	switch name {
+	case "element", "expression":
+		return true
+	}
+
+	return false
+}
+
+/*
+isCalculation tells whether the arguments of the css function may be kept
+as they are written when they can't be parsed as sassscript:
+
+	calc(100% - 10px)
+	var(--gap, 1px)
+*/
+func isCalculation(name string) bool {
+	switch strings.ToLower(name) {
+	case "calc", "var", "env":
+		return true
+	}
+
+	return false
+}
+
+// parseSpecialFunctionCall keeps the source text of the arguments of a
+// special function as a single argument
+func (parser *Parser) parseSpecialFunctionCall(fcall *ast.FunctionCall) (*ast.FunctionCall, error) {
+	open, err := parser.expect(ast.T_PAREN_OPEN)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var depth = 1
+	var end = parser.Pos
+
+	for ; end < len(parser.Tokens); end++ {
+		switch parser.Tokens[end].Type {
+		case ast.T_PAREN_OPEN:
+			depth++
+		case ast.T_PAREN_CLOSE:
+			depth--
+		}
+
+		if depth == 0 {
+			break
+		}
+	}
+
+	if end == len(parser.Tokens) {
+		return nil, SyntaxError{
+			Reason:      "Expected \")\".",
+			ActualToken: open,
+			File:        parser.File,
+		}
+	}
+
+	text := strings.TrimSpace(parser.Content[open.Pos+1 : parser.Tokens[end].Pos])
+
+	var val ast.Expr = ast.NewString(0, text, nil)
+
+	if strings.Contains(text, "#{") {
+		if val, err = parser.ParseInterpolatedText(text); err != nil {
+			return nil, err
+		}
+	}
+
+	parser.Pos = end + 1
+	fcall.Arguments = &ast.CallArgumentList{Args: []*ast.CallArgument{ast.NewCallArgumentWithToken(nil, val)}}
+
+	return fcall, nil
+}
+
 func (parser *Parser) ParseIdent() (*ast.Ident, error) {
 	var tok = parser.next()
 	if tok.Type != ast.T_IDENT {
@@ -613,6 +899,10 @@ The ParseFactor must return an Expr interface compatible object
 func (parser *Parser) ParseFactor() (ast.Expr, error) {
 	var tok = parser.peek()
 
+	if tok == nil {
+		return nil, nil
+	}
+
 	if tok.Type == ast.T_PAREN_OPEN {
 
 		if _, err := parser.expect(ast.T_PAREN_OPEN); err != nil {
@@ -627,12 +917,22 @@ func (parser *Parser) ParseFactor() (ast.Expr, error) {
 		if _, err := parser.expect(ast.T_PAREN_CLOSE); err != nil {
 			return nil, err
 		}
-		return expr, nil
+		return groupExpr(expr), nil
+
+	} else if tok.Type == ast.T_BRACKET_OPEN {
+
+		return parser.ParseBracketedList()
 
 	} else if tok.Type == ast.T_INTERPOLATION_START {
 
 		return parser.ParseInterp()
 
+	} else if tok.Type == ast.T_PARENT_SELECTOR {
+
+		// `&` is the value of the parent selector in expressions
+		parser.advance()
+		return ast.NewParentSelectorWithToken(tok), nil
+
 	} else if tok.Type == ast.T_QQ_STRING {
 
 		parser.advance()
@@ -683,6 +983,12 @@ func (parser *Parser) ParseFactor() (ast.Expr, error) {
 		}
 
 		parser.advance()
+
+		// the color keywords are colors, `red` is printed as written
+		if color, ok := ast.NewColorFromKeyword(tok.Str, tok); ok {
+			return color, nil
+		}
+
 		return ast.NewStringWithToken(tok), nil
 
 	} else if tok.Type == ast.T_HEX_COLOR {
@@ -698,6 +1004,19 @@ func (parser *Parser) ParseFactor() (ast.Expr, error) {
 	return nil, nil
 }
 
+/*
+groupExpr marks the binary expression as written in parentheses, the `/`
+of a grouped expression is always a division:
+
+	(8px/2) => 4px
+*/
+func groupExpr(expr ast.Expr) ast.Expr {
+	if binary, ok := expr.(*ast.BinaryExpr); ok {
+		binary.Grouped = true
+	}
+	return expr
+}
+
 func (parser *Parser) ParseTerm() (ast.Expr, error) {
 	var pos = parser.Pos
 	factor, err := parser.ParseFactor()
@@ -710,21 +1029,22 @@ func (parser *Parser) ParseTerm() (ast.Expr, error) {
 		return nil, nil
 	}
 
-	// see if the next token is '*' or '/'
-	if tok := parser.acceptAnyOf2(ast.T_MUL, ast.T_DIV); tok != nil {
-		term, err := parser.ParseTerm()
+	// see if the next token is '*' or '/', the operators are left
+	// associative: 8 / 2 / 2 == (8 / 2) / 2
+	for tok := parser.acceptAnyOf2(ast.T_MUL, ast.T_DIV); tok != nil; tok = parser.acceptAnyOf2(ast.T_MUL, ast.T_DIV) {
+		right, err := parser.ParseFactor()
 
 		if err != nil {
 			return nil, err
-		} else if term != nil {
-			return ast.NewBinaryExpr(ast.NewOpWithToken(tok), factor, term, false), nil
-		} else {
+		} else if right == nil {
 			return nil, SyntaxError{
 				Reason:      "Expecting term after '*' or '/'",
 				ActualToken: parser.peek(),
 				File:        parser.File,
 			}
 		}
+
+		factor = ast.NewBinaryExpr(ast.NewOpWithToken(tok), factor, right, false)
 	}
 	return factor, nil
 }
@@ -768,7 +1088,12 @@ func (parser *Parser) ParseExpr(inParenthesis bool) (ast.Expr, error) {
 	}
 
 	var rightTok = parser.peek()
-	for rightTok.Type == ast.T_PLUS || rightTok.Type == ast.T_MINUS || rightTok.Type == ast.T_LITERAL_CONCAT {
+	for rightTok != nil && (rightTok.Type == ast.T_PLUS || rightTok.Type == ast.T_MINUS || rightTok.Type == ast.T_LITERAL_CONCAT) {
+		// `10px -5px` is a list of two numbers
+		if parser.isUnaryMinus(rightTok) {
+			break
+		}
+
 		// accept plus or minus
 		parser.advance()
 
@@ -789,6 +1114,22 @@ func (parser *Parser) ParseExpr(inParenthesis bool) (ast.Expr, error) {
 	return expr, nil
 }
 
+/*
+isUnaryMinus tells whether the minus starts the next item of a space
+separated list, the minus has a space before it and none after it:
+
+	10px -5px => 10px (-5px)
+	10px - 5px => 5px
+	10px-5px => 5px
+*/
+func (parser *Parser) isUnaryMinus(tok *ast.Token) bool {
+	if tok.Type != ast.T_MINUS || tok.Pos < 1 || tok.Pos+1 >= len(parser.Content) {
+		return false
+	}
+
+	return unicode.IsSpace(rune(parser.Content[tok.Pos-1])) && !unicode.IsSpace(rune(parser.Content[tok.Pos+1]))
+}
+
 func (parser *Parser) ParseMap() (ast.Expr, error) {
 	var pos = parser.Pos
 	var tok = parser.accept(ast.T_PAREN_OPEN)
@@ -817,23 +1158,58 @@ func (parser *Parser) ParseMap() (ast.Expr, error) {
 			return nil, nil
 		}
 
-		valueExpr, err := parser.ParseExpr(false)
+		// the value could be a nested map, e.g. (color: (primary: blue))
+		valueExpr, err := parser.ParseMap()
 		if err != nil {
 			return nil, err
 		}
+		if valueExpr == nil {
+			valueExpr, err = parser.ParseExpr(false)
+			if err != nil {
+				return nil, err
+			}
+		}
 		if valueExpr == nil {
 			parser.restore(pos)
 			return nil, nil
 		}
 
-		// register the map value
-		mapval.Set(keyExpr, valueExpr)
+		// the value could be a space separated list, e.g. (padding: 1px 2px)
+		if tok = parser.peek(); tok != nil && tok.Type != ast.T_COMMA && tok.Type != ast.T_PAREN_CLOSE {
+			var list = ast.NewSpaceSepList()
+			list.Append(valueExpr)
+
+			for tok.Type != ast.T_COMMA && tok.Type != ast.T_PAREN_CLOSE {
+				expr, err := parser.ParseExpr(false)
+				if err != nil {
+					return nil, err
+				}
+				if expr == nil {
+					parser.restore(pos)
+					return nil, nil
+				}
+				list.Append(expr)
+				if tok = parser.peek(); tok == nil {
+					parser.restore(pos)
+					return nil, nil
+				}
+			}
+			valueExpr = list
+		}
+
+		// duplicated keys are reported by the runtime once the keys
+		// are evaluated
+		mapval.Items = append(mapval.Items, &ast.MapItem{Key: keyExpr, Value: valueExpr})
 		parser.accept(ast.T_COMMA)
 		tok = parser.peek()
 	}
 	if parser.accept(ast.T_PAREN_CLOSE) == nil {
 		return nil, nil
 	}
+	// `()` is an empty list, which is an empty map as well
+	if mapval.Len() == 0 {
+		return ast.NewSpaceSepList(), nil
+	}
 	return mapval, nil
 }
 
@@ -857,7 +1233,7 @@ func (parser *Parser) ParseString() (ast.Expr, error) {
 	}
 
 	var tok = parser.peek()
-	if tok.Type == ast.T_INTERPOLATION_START {
+	if tok != nil && tok.Type == ast.T_INTERPOLATION_START {
 		return parser.ParseInterp()
 	}
 	return nil, nil
@@ -948,7 +1324,7 @@ func (parser *Parser) ParseValue(stopTokType ast.TokenType) (ast.Expr, error) {
 		return nil, err
 	} else if mapValue != nil {
 		var tok = parser.peek()
-		if stopTokType == 0 || tok.Type == stopTokType {
+		if stopTokType == 0 || tok == nil || tok.Type == stopTokType || (stopTokType == ast.T_SEMICOLON && tok.IsFlagKeyword()) {
 			debug("OK Map Meet Stop Token")
 			return mapValue, nil
 		}
@@ -961,7 +1337,8 @@ func (parser *Parser) ParseValue(stopTokType ast.TokenType) (ast.Expr, error) {
 		return nil, err
 	} else if listValue != nil {
 		var tok = parser.peek()
-		if stopTokType == 0 || tok.Type == stopTokType {
+		// flags like !default could follow the value of an assignment
+		if stopTokType == 0 || tok == nil || tok.Type == stopTokType || (stopTokType == ast.T_SEMICOLON && tok.IsFlagKeyword()) {
 			debug("OK List: %+v", listValue)
 			return listValue, nil
 		}
@@ -991,7 +1368,7 @@ func (parser *Parser) ParseCommaSepList() (ast.Expr, error) {
 	var list = ast.NewCommaSepList()
 
 	var tok = parser.peek()
-	for tok.Type != ast.T_COMMA && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_CLOSE {
+	for tok != nil && tok.Type != ast.T_COMMA && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_CLOSE {
 
 		// when the syntax start with a '(', it could be a list or map.
 		if tok.Type == ast.T_PAREN_OPEN {
@@ -1001,7 +1378,7 @@ func (parser *Parser) ParseCommaSepList() (ast.Expr, error) {
 				return nil, err
 			} else if sublist != nil {
 				debug("Appending sublist %+v", list)
-				list.Append(sublist)
+				list.Append(groupExpr(sublist))
 			}
 			// allow empty list here
 			if _, err := parser.expect(ast.T_PAREN_CLOSE); err != nil {
@@ -1041,6 +1418,41 @@ func (parser *Parser) ParseCommaSepList() (ast.Expr, error) {
 	return list, nil
 }
 
+/*
+ParseBracketedList parses a list written with square brackets, which
+could be empty or contain a single item:
+
+	grid-template-columns: [full-start] 1fr [main-start] 2fr [];
+*/
+func (parser *Parser) ParseBracketedList() (*ast.List, error) {
+	if _, err := parser.expect(ast.T_BRACKET_OPEN); err != nil {
+		return nil, err
+	}
+
+	expr, err := parser.ParseCommaSepList()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := parser.expect(ast.T_BRACKET_CLOSE); err != nil {
+		return nil, err
+	}
+
+	list, ok := expr.(*ast.List)
+
+	if !ok || list.Bracketed {
+		list = ast.NewSpaceSepList()
+
+		if expr != nil {
+			list.Append(expr)
+		}
+	}
+
+	list.Bracketed = true
+	return list, nil
+}
+
 func (parser *Parser) ParseVariable() (*ast.Variable, error) {
 	if tok := parser.accept(ast.T_VARIABLE); tok != nil {
 		return ast.NewVariableWithToken(tok), nil
@@ -1082,7 +1494,7 @@ ParseFlags requires a variable assignment.
 */
 func (parser *Parser) ParseFlags(stm *ast.AssignStmt) {
 	var tok = parser.peek()
-	for tok.IsFlagKeyword() {
+	for tok != nil && tok.IsFlagKeyword() {
 		parser.next()
 
 		switch tok.Type {
@@ -1109,7 +1521,7 @@ func (parser *Parser) ParseSpaceSepList() (ast.Expr, error) {
 		if sublist, err := parser.ParseCommaSepList(); err != nil {
 			return nil, err
 		} else if sublist != nil {
-			list.Append(sublist)
+			list.Append(groupExpr(sublist))
 		}
 		if _, err := parser.expect(ast.T_PAREN_CLOSE); err != nil {
 			return nil, err
@@ -1117,7 +1529,7 @@ func (parser *Parser) ParseSpaceSepList() (ast.Expr, error) {
 	}
 
 	var tok = parser.peek()
-	for tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_CLOSE {
+	for tok != nil && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_CLOSE {
 		subexpr, err := parser.ParseExpr(true)
 		if err != nil {
 			return nil, err
@@ -1129,7 +1541,7 @@ func (parser *Parser) ParseSpaceSepList() (ast.Expr, error) {
 			break
 		}
 		tok = parser.peek()
-		if tok.Type == ast.T_COMMA {
+		if tok == nil || tok.Type == ast.T_COMMA {
 			break
 		}
 	}
@@ -1154,7 +1566,7 @@ func (parser *Parser) ParsePropertyValue(property *ast.Property) (*ast.List, err
 	var list = ast.NewSpaceSepList()
 
 	var tok = parser.peek()
-	for tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_CLOSE {
+	for tok != nil && tok.Type != ast.T_SEMICOLON && tok.Type != ast.T_BRACE_CLOSE {
 		sublist, err := parser.ParseList()
 		if err != nil {
 			return nil, err
@@ -1183,16 +1595,19 @@ func (parser *Parser) ParsePropertyName() (ast.Expr, error) {
 	var tok = parser.peek()
 	for tok.Type == ast.T_LITERAL_CONCAT {
 		parser.next()
-		_, err := parser.ParsePropertyNameToken()
+		right, err := parser.ParsePropertyNameToken()
 		if err != nil {
 			return nil, err
 		}
+		if right != nil {
+			ident = ast.NewLiteralConcat(ident, right)
+		}
 		tok = parser.peek()
 	}
 	if _, err := parser.expect(ast.T_COLON); err != nil {
 		return nil, err
 	}
-	return ident, nil // TODO: new literal concat ast
+	return ident, nil
 }
 
 func (parser *Parser) ParsePropertyNameToken() (ast.Expr, error) {
@@ -1242,6 +1657,12 @@ func (parser *Parser) ParseDeclBlock() (*ast.DeclBlock, error) {
 		} else if propertyName != nil {
 			var property = ast.NewProperty(tok)
 
+			// the name is evaluated by the runtime, e.g. `#{$prefix}-transition`
+			if _, ok := propertyName.(*ast.Ident); !ok {
+				property.Name.Interpolation = true
+				property.Name.Template = propertyName
+			}
+
 			if valueList, err := parser.ParsePropertyValue(property); err != nil {
 				return nil, err
 			} else if valueList != nil {
@@ -1251,25 +1672,24 @@ func (parser *Parser) ParseDeclBlock() (*ast.DeclBlock, error) {
 			}
 			declBlock.Append(property)
 
-			var tok2 = parser.peek()
-
-			// if nested property found
-			if tok2.Type == ast.T_BRACE_OPEN {
-				// TODO: merge them back to current block
-				_, err := parser.ParseDeclBlock()
+			// nested properties: `font: { family: x; }` or `margin: 0 { left: 4px; }`,
+			// the semicolon is optional after the block
+			if tok2 := parser.peek(); tok2 != nil && tok2.Type == ast.T_BRACE_OPEN {
+				nested, err := parser.ParseDeclBlock()
 
 				if err != nil {
 					return nil, err
 				}
 
-				// TODO: why do we parse nested block if we don't do
-				// a thing with it?
-				//return nil, fmt.Errorf("TODO: nested declaration block not implemented")
+				property.Block = nested
+				parser.accept(ast.T_SEMICOLON)
+				tok = parser.peek()
+				continue
 			}
 
 			if parser.accept(ast.T_SEMICOLON) == nil {
-				if tok3 := parser.peek(); tok3.Type == ast.T_BRACE_CLOSE {
-					// normal break
+				if tok3 := parser.peek(); tok3 == nil || tok3.Type == ast.T_BRACE_CLOSE {
+					// normal break, the end of input is reported below
 					break
 				} else {
 					return nil, fmt.Errorf("missing semicolon after the property value.")
@@ -1371,7 +1791,7 @@ func (parser *Parser) ParseMediaQuery() (*ast.MediaQuery, error) {
 	if mediaType != nil {
 		// Check if there is an expression after the media type.
 		var tok = parser.peek()
-		if tok.Type != ast.T_LOGICAL_AND {
+		if tok == nil || tok.Type != ast.T_LOGICAL_AND {
 			return ast.NewMediaQuery(mediaType, nil), nil
 		}
 		parser.advance() // skip the and operator token
@@ -1434,9 +1854,15 @@ func (parser *Parser) ParseMediaType() (*ast.MediaType, error) {
 }
 
 /*
-An media query expression must start with a '(' and ends with ')'
+An media query expression must start with a '(' and ends with ')', or it's
+an interpolation:
+
+	screen and #{$query}
 */
 func (parser *Parser) ParseMediaQueryExpr() (ast.Expr, error) {
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_INTERPOLATION_START {
+		return parser.ParseInterpolation()
+	}
 
 	// it's not an media query expression
 	if openTok := parser.accept(ast.T_PAREN_OPEN); openTok != nil {
@@ -1570,24 +1996,87 @@ func (parser *Parser) ParseForStmt() (ast.Stmt, error) {
 }
 
 /*
-The @import syntax is described here:
+Parse the SASS @each statement.
 
-@see CSS2.1 http://www.w3.org/TR/CSS2/cascade.html#at-import
+	@each $item in <list> {  }
 
-@see https://developer.mozilla.org/en-US/docs/Web/CSS/@import
+	@each $key, $value in <map> {  }
+
+	@each $a, $b, $c in <list of lists> {  }
 */
-func (parser *Parser) ParseImportStmt() (ast.Stmt, error) {
-	// skip the ast.T_IMPORT token
-	if _, err := parser.expect(ast.T_IMPORT); err != nil {
+func (parser *Parser) ParseEachStmt() (ast.Stmt, error) {
+	if _, err := parser.expect(ast.T_EACH); err != nil {
 		return nil, err
 	}
 
-	// Create the import statement node
-	var stm ast.Stmt
+	var variables []*ast.Variable
+	for {
+		variable, err := parser.ParseVariable()
+		if err != nil {
+			return nil, err
+		}
+		if variable == nil {
+			return nil, SyntaxError{
+				Reason:      "Expecting variable in @each statement.",
+				ActualToken: parser.peek(),
+				File:        parser.File,
+			}
+		}
+		variables = append(variables, variable)
 
-	var tok = parser.peek()
+		if parser.accept(ast.T_COMMA) == nil {
+			break
+		}
+	}
 
-	// that's a css import
+	var stm = ast.NewEachStmt(variables)
+
+	if _, err := parser.expect(ast.T_FOR_IN); err != nil {
+		return nil, err
+	}
+
+	expr, err := parser.ParseValue(ast.T_BRACE_OPEN)
+	if err != nil {
+		return nil, err
+	}
+	if expr == nil {
+		return nil, SyntaxError{
+			Reason:      "Expecting expression after 'in'.",
+			ActualToken: parser.peek(),
+			File:        parser.File,
+		}
+	}
+	stm.Expr = expr
+
+	if b, err := parser.ParseDeclBlock(); err != nil {
+		return nil, err
+	} else if b != nil {
+		stm.Block = b
+	} else {
+		return nil, fmt.Errorf("The @each statement expecting block after the list expression")
+	}
+	return stm, nil
+}
+
+/*
+The @import syntax is described here:
+
+@see CSS2.1 http://www.w3.org/TR/CSS2/cascade.html#at-import
+
+@see https://developer.mozilla.org/en-US/docs/Web/CSS/@import
+*/
+func (parser *Parser) ParseImportStmt() (ast.Stmt, error) {
+	// skip the ast.T_IMPORT token
+	if _, err := parser.expect(ast.T_IMPORT); err != nil {
+		return nil, err
+	}
+
+	// Create the import statement node
+	var stm ast.Stmt
+
+	var tok = parser.peek()
+
+	// that's a css import
 	if tok.Type == ast.T_FUNCTION_NAME ||
 		tok.IsString() && (strings.HasSuffix(tok.Str, ".css") || strings.HasPrefix(tok.Str, "//") || AbsoluteUrlPattern.MatchString(tok.Str)) {
 		cssImport := ast.NewCssImportStmt()
@@ -1698,12 +2187,218 @@ func (parser *Parser) ParseImportStmt() (ast.Stmt, error) {
 	return stm, nil
 }
 
+/*
+ParseUseStmt parses the @use rule:
+
+	@use "src/corners";
+
+	@use "src/corners" as c;
+
+	@use "src/corners" as *;
+*/
+func (parser *Parser) ParseUseStmt() (ast.Stmt, error) {
+	if _, err := parser.expect(ast.T_USE); err != nil {
+		return nil, err
+	}
+
+	var sourceFname string
+	if parser.File != nil {
+		sourceFname = parser.File.FileName
+	}
+
+	var stm = ast.NewUseStmt(sourceFname)
+
+	var tok = parser.acceptAnyOf2(ast.T_QQ_STRING, ast.T_Q_STRING)
+	if tok == nil {
+		return nil, SyntaxError{
+			Reason:      "Expected string.",
+			ActualToken: parser.peek(),
+			File:        parser.File,
+		}
+	}
+	if tok.Type == ast.T_QQ_STRING {
+		stm.Path = ast.NewStringWithQuote('"', tok)
+	} else {
+		stm.Path = ast.NewStringWithQuote('\'', tok)
+	}
+
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_IDENT && tok.Str == "as" {
+		parser.next()
+
+		if tok := parser.acceptAnyOf2(ast.T_IDENT, ast.T_MUL); tok != nil {
+			stm.Namespace = tok.Str
+		} else {
+			return nil, SyntaxError{
+				Reason:      "Expected identifier.",
+				ActualToken: parser.peek(),
+				File:        parser.File,
+			}
+		}
+	}
+
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_IDENT && tok.Str == "with" {
+		parser.next()
+
+		config, err := parser.ParseMap()
+		if err != nil {
+			return nil, err
+		}
+
+		mapval, ok := config.(*ast.Map)
+		if !ok {
+			return nil, SyntaxError{
+				Reason:      "Expected configuration map.",
+				ActualToken: parser.peek(),
+				File:        parser.File,
+			}
+		}
+
+		for _, item := range mapval.Items {
+			if _, ok := item.Key.(*ast.Variable); !ok {
+				return nil, SyntaxError{
+					Reason:      "Expected variable.",
+					ActualToken: parser.peek(),
+					File:        parser.File,
+				}
+			}
+		}
+		stm.With = mapval
+	}
+
+	if _, err := parser.expect(ast.T_SEMICOLON); err != nil {
+		return nil, err
+	}
+	return stm, nil
+}
+
+/*
+ParseForwardStmt parses the @forward rule:
+
+	@forward "src/list";
+
+	@forward "src/list" as list-*;
+
+	@forward "src/list" show list-reset, $horizontal-list-gap;
+
+	@forward "src/list" hide list-reset, $horizontal-list-gap;
+*/
+func (parser *Parser) ParseForwardStmt() (ast.Stmt, error) {
+	if _, err := parser.expect(ast.T_FORWARD); err != nil {
+		return nil, err
+	}
+
+	var sourceFname string
+	if parser.File != nil {
+		sourceFname = parser.File.FileName
+	}
+
+	var stm = ast.NewForwardStmt(sourceFname)
+
+	var tok = parser.acceptAnyOf2(ast.T_QQ_STRING, ast.T_Q_STRING)
+	if tok == nil {
+		return nil, SyntaxError{
+			Reason:      "Expected string.",
+			ActualToken: parser.peek(),
+			File:        parser.File,
+		}
+	}
+
+	if tok.Type == ast.T_QQ_STRING {
+		stm.Path = ast.NewStringWithQuote('"', tok)
+	} else {
+		stm.Path = ast.NewStringWithQuote('\'', tok)
+	}
+
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_IDENT && tok.Str == "as" {
+		parser.next()
+
+		prefix, err := parser.parseForwardMemberName(false)
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasSuffix(prefix, "*") {
+			return nil, SyntaxError{
+				Reason:      "Expected \"*\".",
+				ActualToken: parser.peek(),
+				File:        parser.File,
+			}
+		}
+		stm.Prefix = strings.TrimSuffix(prefix, "*")
+	}
+
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_IDENT && (tok.Str == "show" || tok.Str == "hide") {
+		parser.next()
+
+		var names []string
+		for {
+			name, err := parser.parseForwardMemberName(true)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+
+			if parser.accept(ast.T_COMMA) == nil {
+				break
+			}
+		}
+
+		if tok.Str == "show" {
+			stm.Show = names
+		} else {
+			stm.Hide = names
+		}
+	}
+
+	if _, err := parser.expect(ast.T_SEMICOLON); err != nil {
+		return nil, err
+	}
+	return stm, nil
+}
+
+// parseForwardMemberName parses the member names and the prefixes of
+// @forward, the `-*` of `list-*` is lexed as separate tokens
+func (parser *Parser) parseForwardMemberName(allowVariable bool) (string, error) {
+	var tok *ast.Token
+	if allowVariable {
+		tok = parser.accept(ast.T_VARIABLE)
+	}
+
+	if tok == nil {
+		tok = parser.accept(ast.T_IDENT)
+	}
+
+	if tok == nil {
+		return "", SyntaxError{
+			Reason:      "Expected identifier.",
+			ActualToken: parser.peek(),
+			File:        parser.File,
+		}
+	}
+
+	var name = tok.Str
+	if parser.accept(ast.T_MINUS) != nil {
+		name += "-"
+		if parser.accept(ast.T_MUL) == nil {
+			return "", SyntaxError{
+				Reason:      "Expected \"*\".",
+				ActualToken: parser.peek(),
+				File:        parser.File,
+			}
+		}
+		name += "*"
+	} else if parser.accept(ast.T_MUL) != nil {
+		name += "*"
+	}
+	return name, nil
+}
+
 func (parser *Parser) ParseReturnStmt() (ast.Stmt, error) {
 	returnTok, err := parser.expect(ast.T_RETURN)
 	if err != nil {
 		return nil, err
 	}
-	valueExpr, err := parser.ParseExpr(true)
+	valueExpr, err := parser.ParseValue(ast.T_SEMICOLON)
 	if err != nil {
 		return nil, err
 	}
@@ -1840,6 +2535,14 @@ func (parser *Parser) ParseFunctionCallExpr() (ast.Expr, error) {
 	var pos = parser.Pos
 	var val ast.Expr
 
+	// maps could be passed as arguments, e.g. map-merge($a, (key: value))
+	if mapValue, err := parser.ParseMap(); err != nil {
+		return nil, err
+	} else if tok := parser.peek(); mapValue != nil && (tok.Type == ast.T_COMMA || tok.Type == ast.T_PAREN_CLOSE) {
+		return mapValue, nil
+	}
+	parser.restore(pos)
+
 	if listValue, err := parser.ParseSpaceSepList(); err != nil {
 		return nil, err
 	} else if listValue != nil {
@@ -1962,13 +2665,36 @@ func (parser *Parser) ParseIncludeStmt() (ast.Stmt, error) {
 		return nil, fmt.Errorf("Unexpected token after @include.")
 	}
 
+	if tok3 := parser.peek(); tok3 != nil && tok3.Type == ast.T_IDENT && tok3.Str == "using" {
+		parser.next()
+
+		if al, err := parser.ParseFunctionPrototype(); err != nil {
+			return nil, err
+		} else {
+			stm.ContentArguments = al
+		}
+
+		if tok4 := parser.peek(); tok4 == nil || tok4.Type != ast.T_BRACE_OPEN {
+			return nil, SyntaxError{
+				Reason:      "expected \"{\".",
+				ActualToken: tok4,
+				File:        parser.File,
+			}
+		}
+	}
+
 	var tok3 = parser.peek()
-	if tok3.Type == ast.T_BRACE_OPEN {
+	if tok3 != nil && tok3.Type == ast.T_BRACE_OPEN {
 		if bl, err := parser.ParseDeclBlock(); err != nil {
 			return nil, err
 		} else {
 			stm.ContentBlock = bl
 		}
+
+		// the semicolon after the content block is optional
+		parser.accept(ast.T_SEMICOLON)
+
+		return stm, nil
 	}
 
 	if _, err := parser.expect(ast.T_SEMICOLON); err != nil {
@@ -2030,16 +2756,84 @@ func (parser *Parser) ParseContentStmt() (ast.Stmt, error) {
 		return nil, err
 	}
 
+	var stm = ast.NewContentStmtWithToken(tok)
+
+	if tok := parser.peek(); tok != nil && tok.Type == ast.T_PAREN_OPEN {
+		if al, err := parser.ParseFunctionCallArguments(); err != nil {
+			return nil, err
+		} else {
+			stm.Arguments = al
+		}
+	}
+
 	if _, err := parser.expect(ast.T_SEMICOLON); err != nil {
 		return nil, err
 	}
 
-	return ast.NewContentStmtWithToken(tok), nil
+	return stm, nil
 }
 
 /*
 @content directive is only allowed in mixin block
 */
+/*
+ParseAtRuleStmt parses the at-rules unknown to Sass, the prelude may contain
+interpolation and the block is optional:
+
+	@supports (display: #{$display}) { ... }
+	@custom-rule;
+*/
+func (parser *Parser) ParseAtRuleStmt() (ast.Stmt, error) {
+	tok := parser.acceptAnyOf2(ast.T_AT_RULE, ast.T_PAGE)
+	if tok == nil {
+		return nil, SyntaxError{
+			Reason:      "Expected at-rule.",
+			ActualToken: parser.peek(),
+			File:        parser.File,
+		}
+	}
+
+	stm := ast.NewAtRuleStmtWithToken(tok)
+
+	var err error
+
+	// @page :first { ... }
+	if pseudo := parser.accept(ast.T_PSEUDO_SELECTOR); pseudo != nil {
+		stm.Prelude = ast.NewString(0, pseudo.Str, pseudo)
+	} else if prelude := parser.accept(ast.T_UNQUOTE_STRING); prelude != nil {
+		text := strings.TrimSpace(prelude.Str)
+
+		if prelude.ContainsInterpolation {
+			if stm.Prelude, err = parser.ParseInterpolatedText(text); err != nil {
+				return nil, err
+			}
+		} else {
+			stm.Prelude = ast.NewString(0, text, prelude)
+		}
+	}
+
+	if parser.peek() != nil && parser.peek().Type == ast.T_BRACE_OPEN {
+		if stm.Block, err = parser.ParseDeclBlock(); err != nil {
+			return nil, err
+		}
+
+		return stm, nil
+	}
+
+	// the semicolon can be omitted before the end of the block
+	if parser.accept(ast.T_SEMICOLON) == nil {
+		if tok := parser.peek(); tok != nil && tok.Type != ast.T_BRACE_CLOSE {
+			return nil, SyntaxError{
+				Reason:      "Expected \";\".",
+				ActualToken: tok,
+				File:        parser.File,
+			}
+		}
+	}
+
+	return stm, nil
+}
+
 func (parser *Parser) ParseAtRootStmt() (ast.Stmt, error) {
 	tok, err := parser.expect(ast.T_AT_ROOT)
 	if err != nil {
@@ -2050,19 +2844,30 @@ func (parser *Parser) ParseAtRootStmt() (ast.Stmt, error) {
 
 	tok = parser.peek()
 
-	if tok.IsSelector() {
-		sel, err := parser.ParseComplexSelector()
+	if tok == nil {
+		// the missing block is reported below
+	} else if tok.IsSelector() || tok.Type == ast.T_INTERPOLATION_START {
+		if tmpl, err := parser.ParseSelectorTemplate(ast.T_BRACE_OPEN); err != nil {
+			return nil, err
+		} else if tmpl != nil {
+			stm.SelectorTemplate = tmpl
+		} else {
+			sel, err := parser.ParseSelectorList()
+
+			if err != nil {
+				return nil, err
+			}
+
+			stm.Selectors = sel
+		}
+	} else if tok.Type == ast.T_PAREN_OPEN {
+		query, err := parser.ParseAtRootQuery()
 
 		if err != nil {
 			return nil, err
 		}
 
-		stm.Selector = sel
-	} else if tok.Type == ast.T_PAREN_OPEN {
-		return nil, SyntaxError{
-			Reason:      "@at-root does not support expressions like (without: media) yet",
-			ActualToken: parser.peek(),
-		}
+		stm.Query = query
 	}
 
 	bl, err := parser.ParseDeclBlock()
@@ -2073,3 +2878,53 @@ func (parser *Parser) ParseAtRootStmt() (ast.Stmt, error) {
 
 	return stm, nil
 }
+
+/*
+ParseAtRootQuery parses the query of @at-root:
+
+	(without: media)
+	(with: rule supports)
+*/
+func (parser *Parser) ParseAtRootQuery() (*ast.AtRootQuery, error) {
+	if _, err := parser.expect(ast.T_PAREN_OPEN); err != nil {
+		return nil, err
+	}
+
+	query := &ast.AtRootQuery{}
+
+	tok := parser.next()
+
+	if tok.Str == "with" {
+		query.With = true
+	} else if tok.Str != "without" {
+		return nil, SyntaxError{
+			Reason:      `Expected "with" or "without".`,
+			ActualToken: tok,
+			File:        parser.File,
+		}
+	}
+
+	if _, err := parser.expect(ast.T_COLON); err != nil {
+		return nil, err
+	}
+
+	for tok = parser.next(); tok != nil && tok.Type == ast.T_IDENT; tok = parser.next() {
+		query.Names = append(query.Names, tok.Str)
+	}
+
+	parser.backup()
+
+	if len(query.Names) == 0 {
+		return nil, SyntaxError{
+			Reason:      "Expected identifier.",
+			ActualToken: parser.peek(),
+			File:        parser.File,
+		}
+	}
+
+	if _, err := parser.expect(ast.T_PAREN_CLOSE); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
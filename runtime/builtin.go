@@ -0,0 +1,193 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c9s/c6/ast"
+	"github.com/c9s/c6/parser"
+)
+
+// BuiltinFunc is the implementation of a function of the sass: modules,
+// the arguments are already evaluated and ordered like in the prototype
+type BuiltinFunc func(args *BuiltinArguments) (ast.Value, error)
+
+// BuiltinArguments are the evaluated arguments of a builtin function call
+type BuiltinArguments struct {
+	Names  []string
+	Values []ast.Value
+
+	// Scope is the scope of the caller, the sass:meta functions look
+	// the members up from there
+	Scope *Scope
+}
+
+/*
+NewBuiltinFunction creates a function from its Go implementation, the
+prototype is written like the one of a @function rule:
+
+	NewBuiltinFunction("log($number, $base: null)", mathLog)
+*/
+func NewBuiltinFunction(prototype string, fn BuiltinFunc) *Function {
+	stmts, err := parser.NewParser(nil).ParseScss("@function " + prototype + " {}")
+
+	if err != nil {
+		panic(fmt.Sprintf("invalid builtin function prototype %s: %s", prototype, err))
+	}
+
+	return &Function{
+		Decl:    stmts.Stmts[0].(*ast.Function),
+		Builtin: fn,
+	}
+}
+
+func (fn *Function) callBuiltin(args *ast.CallArgumentList, caller *Scope) (ast.Value, error) {
+	builtinArgs := &BuiltinArguments{Scope: caller}
+
+	for _, arg := range args.Args {
+		val, err := EvaluateExpr(arg.Value, caller)
+
+		if err != nil {
+			return nil, err
+		}
+
+		builtinArgs.Names = append(builtinArgs.Names, arg.Name.Name)
+		builtinArgs.Values = append(builtinArgs.Values, withoutSlash(val, caller))
+	}
+
+	return fn.Builtin(builtinArgs)
+}
+
+func (args *BuiltinArguments) Value(idx int) ast.Value {
+	return args.Values[idx]
+}
+
+// IsNull tells whether the argument is null, which is the default
+// value of the optional arguments
+func (args *BuiltinArguments) IsNull(idx int) bool {
+	_, ok := args.Values[idx].(*ast.Null)
+	return ok
+}
+
+func (args *BuiltinArguments) Number(idx int) (*ast.Number, error) {
+	num, ok := args.Values[idx].(*ast.Number)
+
+	if !ok {
+		return nil, fmt.Errorf("%s: %s is not a number.", args.Names[idx], args.Values[idx])
+	}
+
+	return num, nil
+}
+
+// UnitlessNumber returns the argument as a number without any unit
+func (args *BuiltinArguments) UnitlessNumber(idx int) (*ast.Number, error) {
+	num, err := args.Number(idx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !num.IsUnitless() {
+		return nil, fmt.Errorf("%s: Expected %s to have no units.", args.Names[idx], num)
+	}
+
+	return num, nil
+}
+
+func (args *BuiltinArguments) String(idx int) (*ast.String, error) {
+	str, ok := args.Values[idx].(*ast.String)
+
+	if !ok {
+		return nil, fmt.Errorf("%s: %s is not a string.", args.Names[idx], args.Values[idx])
+	}
+
+	return str, nil
+}
+
+// Int returns the argument as an integer, e.g. the index of a string
+func (args *BuiltinArguments) Int(idx int) (int, error) {
+	num, err := args.Number(idx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	i, ok := ast.FuzzyInt(num.Value)
+
+	if !ok {
+		return 0, fmt.Errorf("%s: %s is not an int.", args.Names[idx], num)
+	}
+
+	return i, nil
+}
+
+// Bool returns the argument in boolean context, only false and null
+// are falsy in sass
+func (args *BuiltinArguments) Bool(idx int) bool {
+	switch v := args.Values[idx].(type) {
+	case *ast.Boolean:
+		return v.Value
+	case *ast.Null:
+		return false
+	}
+
+	return true
+}
+
+// List returns the items of the argument, a single value is a list of one
+// item. This is how the variable length arguments are read.
+func (args *BuiltinArguments) List(idx int) []ast.Expr {
+	if l, ok := args.Values[idx].(*ast.List); ok {
+		return l.Exprs
+	}
+
+	return []ast.Expr{args.Values[idx]}
+}
+
+// builtinModules are the modules loaded with `@use "sass:..."`
+var builtinModules = map[string]*Module{}
+
+// globalFunctions are the builtin functions that can be called without
+// loading their module, e.g. `round(1.5)` is `math.round(1.5)`
+var globalFunctions = map[string]*Function{}
+
+func NewBuiltinModule(url string, functions ...*Function) *Module {
+	module := NewModule(url)
+
+	for _, fn := range functions {
+		module.Scope.InsertFunction(fn.Decl.NormalizedName(), fn)
+	}
+
+	return module
+}
+
+// registerGlobalFunction makes the function of the module available as
+// the global function name
+func registerGlobalFunction(module *Module, member, name string) {
+	fn, ok := module.Function(normalizeMemberName(member))
+
+	if !ok {
+		panic("unknown builtin function " + member)
+	}
+
+	globalFunctions[normalizeMemberName(name)] = fn
+}
+
+func (r *Runtime) loadBuiltinModule(url string, config *Configuration) (*Module, *ast.StmtList, error) {
+	module, ok := builtinModules[url]
+
+	if !ok {
+		return nil, nil, fmt.Errorf("Can't find stylesheet to import.")
+	}
+
+	if config != nil {
+		return nil, nil, fmt.Errorf("Built-in modules can't be configured.")
+	}
+
+	return module, nil, nil
+}
+
+// isBuiltinModule tells whether the url is the one of a builtin module
+func isBuiltinModule(url string) bool {
+	return strings.HasPrefix(url, "sass:")
+}
@@ -0,0 +1,33 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/c9s/c6/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBuiltinFunction(t *testing.T) {
+	fn := NewBuiltinFunction("log($number, $base: null)", mathLog)
+
+	assert.Equal(t, "log", fn.Decl.NormalizedName())
+	assert.Len(t, fn.Decl.ArgumentList.Arguments, 2)
+	assert.Nil(t, fn.Decl.ArgumentList.Arguments[0].DefaultValue)
+	assert.NotNil(t, fn.Decl.ArgumentList.Arguments[1].DefaultValue)
+}
+
+func TestBuiltinArgumentsUnitlessNumber(t *testing.T) {
+	px := ast.NewNumber(2, ast.NewUnit(ast.T_UNIT_PX, nil), nil)
+
+	args := &BuiltinArguments{
+		Names:  []string{"$number", "$base"},
+		Values: []ast.Value{ast.NewNumber(2, nil, nil), px},
+	}
+
+	num, err := args.UnitlessNumber(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, num.Value)
+
+	_, err = args.UnitlessNumber(1)
+	assert.EqualError(t, err, "$base: Expected 2px to have no units.")
+}
@@ -3,134 +3,670 @@ package runtime
 import (
 	"fmt"
 	"math"
+	"slices"
 
 	"github.com/c9s/c6/ast"
 )
 
-func HexColorAddNumber(c *ast.HexColor, num *ast.Number) *ast.HexColor {
-	r := c.R + uint32(num.Value)
-	g := c.G + uint32(num.Value)
-	b := c.B + uint32(num.Value)
-	hex := ast.Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &ast.HexColor{
-		Hex:   hex,
-		R:     r,
-		G:     g,
-		B:     b,
-		Token: nil,
+func init() {
+	module := NewBuiltinModule("sass:color",
+		NewBuiltinFunction("adjust($color, $kwargs...)", colorUpdate(colorAdjust)),
+		NewBuiltinFunction("scale($color, $kwargs...)", colorUpdate(colorScale)),
+		NewBuiltinFunction("change($color, $kwargs...)", colorUpdate(colorChange)),
+		NewBuiltinFunction("mix($color1, $color2, $weight: 50%, $method: null)", colorMix),
+		NewBuiltinFunction("complement($color)", colorComplement),
+		NewBuiltinFunction("invert($color, $weight: 100%)", colorInvert),
+		NewBuiltinFunction("grayscale($color)", colorGrayscale),
+		NewBuiltinFunction("alpha($color)", colorAlpha),
+		NewBuiltinFunction("opacity($color)", colorAlpha),
+		NewBuiltinFunction("red($color)", colorChannel(0)),
+		NewBuiltinFunction("green($color)", colorChannel(1)),
+		NewBuiltinFunction("blue($color)", colorChannel(2)),
+		NewBuiltinFunction("hue($color)", colorHue),
+		NewBuiltinFunction("saturation($color)", colorPercentChannel(1)),
+		NewBuiltinFunction("lightness($color)", colorPercentChannel(2)),
+		NewBuiltinFunction("ie-hex-str($color)", colorIEHexStr),
+		NewBuiltinFunction("space($color)", colorSpace),
+		NewBuiltinFunction("is-legacy($color)", colorIsLegacy),
+		NewBuiltinFunction("to-space($color, $space)", colorToSpace),
+		NewBuiltinFunction("channel($color, $channel, $space: null)", colorChannelByName),
+		NewBuiltinFunction("is-in-gamut($color, $space: null)", colorIsInGamut),
+		NewBuiltinFunction("to-gamut($color, $space: null, $method: null)", colorToGamut),
+	)
+
+	builtinModules["sass:color"] = module
+
+	registerGlobalFunction(module, "adjust", "adjust-color")
+	registerGlobalFunction(module, "scale", "scale-color")
+	registerGlobalFunction(module, "change", "change-color")
+	registerGlobalFunction(module, "mix", "mix")
+	registerGlobalFunction(module, "complement", "complement")
+	registerGlobalFunction(module, "invert", "invert")
+	registerGlobalFunction(module, "grayscale", "grayscale")
+	registerGlobalFunction(module, "alpha", "alpha")
+	registerGlobalFunction(module, "opacity", "opacity")
+	registerGlobalFunction(module, "red", "red")
+	registerGlobalFunction(module, "green", "green")
+	registerGlobalFunction(module, "blue", "blue")
+	registerGlobalFunction(module, "hue", "hue")
+	registerGlobalFunction(module, "saturation", "saturation")
+	registerGlobalFunction(module, "lightness", "lightness")
+	registerGlobalFunction(module, "ie-hex-str", "ie-hex-str")
+
+	// the legacy functions are global only, sass:color has color.adjust()
+	for _, fn := range []*Function{
+		NewBuiltinFunction("lighten($color, $amount)", colorShift(2, 100, 1)),
+		NewBuiltinFunction("darken($color, $amount)", colorShift(2, 100, -1)),
+		NewBuiltinFunction("saturate($color, $amount: null)", colorSaturate),
+		NewBuiltinFunction("desaturate($color, $amount)", colorShift(1, 100, -1)),
+		NewBuiltinFunction("adjust-hue($color, $degrees)", colorAdjustHue),
+		NewBuiltinFunction("opacify($color, $amount)", colorShift(3, 1, 1)),
+		NewBuiltinFunction("fade-in($color, $amount)", colorShift(3, 1, 1)),
+		NewBuiltinFunction("transparentize($color, $amount)", colorShift(3, 1, -1)),
+		NewBuiltinFunction("fade-out($color, $amount)", colorShift(3, 1, -1)),
+	} {
+		globalFunctions[fn.Decl.NormalizedName()] = fn
+	}
+
+	// the color functions are global as well, so that sass:meta finds them
+	for _, name := range []string{"rgb", "rgba", "hsl", "hsla", "lab", "lch", "oklab", "oklch", "color"} {
+		globalFunctions[name] = NewBuiltinFunction(name+"($args...)", colorFunction(name))
+	}
+}
+
+/*
+colorFunction returns the builtin function of the color functions, the
+arguments are given back to EvaluateColorFunction like they were written:
+
+	call(get-function(rgb), 1, 2, 3) => rgb(1, 2, 3)
+*/
+func colorFunction(name string) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		fc := ast.NewFunctionCallWithToken(&ast.Token{Type: ast.T_FUNCTION_NAME, Str: name})
+		fc.Arguments = &ast.CallArgumentList{}
+
+		for _, item := range args.List(0) {
+			fc.Arguments.Args = append(fc.Arguments.Args, ast.NewCallArgumentWithToken(nil, item))
+		}
+
+		if rest, ok := args.Value(0).(*ast.List); ok && rest.Keywords != nil {
+			for _, item := range rest.Keywords.Items {
+				name := &ast.Variable{Name: "$" + item.Key.(*ast.String).Value}
+				fc.Arguments.Args = append(fc.Arguments.Args, ast.NewCallArgumentWithToken(name, item.Value))
+			}
+		}
+
+		return EvaluateColorFunction(fc, args.Scope)
+	}
+}
+
+func clamp(x, min, max float64) float64 {
+	return math.Max(min, math.Min(max, x))
+}
+
+// Color returns the argument as a color
+func (args *BuiltinArguments) Color(idx int) (*ast.Color, error) {
+	c, ok := args.Values[idx].(*ast.Color)
+
+	if !ok {
+		return nil, fmt.Errorf("%s: %s is not a color.", args.Names[idx], args.Values[idx])
 	}
+
+	return c, nil
 }
 
-func uintsub(a, b uint32) uint32 {
-	if a > b {
-		return a - b
-	}
-	return 0
+/*
+checkRange returns the value of the number, which must be within min and
+max. The unit is the one printed with the bounds:
+
+	$amount: Expected 120% to be within 0% and 100%.
+*/
+func checkRange(name string, num *ast.Number, min, max float64, unit string) (float64, error) {
+	if ast.FuzzyLessThan(num.Value, min) || ast.FuzzyLessThan(max, num.Value) {
+		return 0, fmt.Errorf("%s: Expected %s to be within %s%s and %s%s.", name, num,
+			ast.NewNumber(min, nil, nil), unit, ast.NewNumber(max, nil, nil), unit)
+	}
+
+	return num.Value, nil
 }
 
-func HexColorSubNumber(c *ast.HexColor, num *ast.Number) *ast.HexColor {
-	val := uint32(num.Value)
-	r := uintsub(c.R, val)
-	g := uintsub(c.G, val)
-	b := uintsub(c.B, val)
-	hex := ast.Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &ast.HexColor{
-		Hex:   hex,
-		R:     r,
-		G:     g,
-		B:     b,
-		Token: nil,
-	}
-}
-
-func HexColorMulNumber(color *ast.HexColor, num *ast.Number) *ast.HexColor {
-	r := uint32(math.Floor(float64(color.R) * num.Value))
-	g := uint32(math.Floor(float64(color.G) * num.Value))
-	b := uint32(math.Floor(float64(color.B) * num.Value))
-	hex := ast.Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &ast.HexColor{
-		Hex:   hex,
-		R:     r,
-		G:     g,
-		B:     b,
-		Token: nil,
-	}
-}
-
-func HexColorDivNumber(color *ast.HexColor, num *ast.Number) *ast.HexColor {
-	r := uint32(math.Floor(float64(color.R) / num.Value))
-	g := uint32(math.Floor(float64(color.G) / num.Value))
-	b := uint32(math.Floor(float64(color.B) / num.Value))
-	hex := ast.Hex(fmt.Sprintf("#%02X%02X%02X", r, g, b))
-	return &ast.HexColor{
-		Hex:   hex,
-		R:     r,
-		G:     g,
-		B:     b,
-		Token: nil,
-	}
-}
-
-func RGBColorAddNumber(c *ast.RGBColor, n *ast.Number) *ast.RGBColor {
-	var val = uint32(n.Value)
-	var r = c.R + val
-	var g = c.G + val
-	var b = c.B + val
-	return ast.NewRGBColor(r, g, b, nil)
-}
-
-func RGBColorSubNumber(c *ast.RGBColor, n *ast.Number) *ast.RGBColor {
-	var val = uint32(n.Value)
-	var r = uintsub(c.R, val)
-	var g = uintsub(c.G, val)
-	var b = uintsub(c.B, val)
-	return ast.NewRGBColor(r, g, b, nil)
-}
-
-func RGBColorMulNumber(c *ast.RGBColor, n *ast.Number) *ast.RGBColor {
-	var val = uint32(n.Value)
-	var r = c.R * val
-	var g = c.G * val
-	var b = c.B * val
-	return ast.NewRGBColor(r, g, b, nil)
-}
-
-func RGBColorDivNumber(c *ast.RGBColor, n *ast.Number) *ast.RGBColor {
-	var val = n.Value
-	var r = math.Floor(float64(c.R) / val)
-	var g = math.Floor(float64(c.G) / val)
-	var b = math.Floor(float64(c.B) / val)
-	return ast.NewRGBColor(uint32(r), uint32(g), uint32(b), nil)
-}
-
-func RGBAColorAddNumber(c *ast.RGBAColor, n *ast.Number) *ast.RGBAColor {
-	var val = uint32(n.Value)
-	var r = c.R + val
-	var g = c.G + val
-	var b = c.B + val
-	return ast.NewRGBAColor(r, g, b, c.A, nil)
-}
-
-func RGBAColorSubNumber(c *ast.RGBAColor, n *ast.Number) *ast.RGBAColor {
-	var val = uint32(n.Value)
-	var r = uintsub(c.R, val)
-	var g = uintsub(c.G, val)
-	var b = uintsub(c.B, val)
-	return ast.NewRGBAColor(r, g, b, c.A, nil)
+// NumberInRange returns the value of the number argument, the bounds are
+// in the unit of the number
+func (args *BuiltinArguments) NumberInRange(idx int, min, max float64) (float64, error) {
+	num, err := args.Number(idx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return checkRange(args.Names[idx], num, min, max, num.UnitString())
+}
+
+// colorUpdateKind tells how the arguments of adjust(), scale() and
+// change() are applied to the channels of the color
+type colorUpdateKind int
+
+const (
+	colorAdjust colorUpdateKind = iota
+	colorScale
+	colorChange
+)
+
+// the maximum values of the channels by their argument names, the hue
+// has no maximum
+var colorChannelMax = map[string]float64{
+	"$red":        255,
+	"$green":      255,
+	"$blue":       255,
+	"$hue":        0,
+	"$saturation": 100,
+	"$lightness":  100,
+	"$alpha":      1,
+}
+
+/*
+colorUpdate returns the builtin function for adjust(), scale() and
+change(), the channels are given by name. The channels of the other
+spaces are updated in the space given by $space, or in the space of a non
+legacy color:
+
+	color.adjust(#6b717f, $red: 15) => #7a717f
+	color.scale(#6b717f, $red: 15%) => #81717f
+	color.change(#6b717f, $red: 100) => #64717f
+	color.adjust(#6b717f, $lightness: 10%, $space: oklch) => #888f9d
+*/
+func colorUpdate(kind colorUpdateKind) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		args, err := colorUpdateArguments(args)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := args.Color(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(args.Names) - 1
+		nums := map[string]*ast.Number{}
+
+		for idx, name := range args.Names[1:last] {
+			if args.IsNull(idx + 1) {
+				continue
+			}
+
+			if nums[name], err = args.Number(idx + 1); err != nil {
+				return nil, err
+			}
+		}
+
+		if !c.Space.Legacy || !args.IsNull(last) {
+			space, err := colorSpaceArgument(args, last, c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			return colorSpaceUpdate(kind, c, space, nums)
+		}
+
+		values := map[string]float64{}
+
+		for name, num := range nums {
+			if _, ok := colorChannelMax[name]; !ok {
+				return nil, fmt.Errorf("%s: Color space %s doesn't have a channel with this name.", name, c.Space)
+			}
+
+			if values[name], err = colorUpdateValue(kind, name, num); err != nil {
+				return nil, err
+			}
+		}
+
+		_, hasRed := values["$red"]
+		_, hasGreen := values["$green"]
+		_, hasBlue := values["$blue"]
+		_, hasHue := values["$hue"]
+		_, hasSaturation := values["$saturation"]
+		_, hasLightness := values["$lightness"]
+
+		hasRGB := hasRed || hasGreen || hasBlue
+		hasHSL := hasHue || hasSaturation || hasLightness
+
+		if hasRGB && hasHSL {
+			return nil, fmt.Errorf("RGB parameters may not be passed along with HSL parameters.")
+		}
+
+		a := c.A
+
+		if val, ok := values["$alpha"]; ok {
+			a = colorUpdateChannel(kind, a, val, 1)
+		}
+
+		switch {
+		case hasHSL:
+			h, s, l := c.H, c.S, c.L
+			hsl := map[string]*float64{"$hue": &h, "$saturation": &s, "$lightness": &l}
+
+			for name, ptr := range hsl {
+				if val, ok := values[name]; ok {
+					*ptr = colorUpdateChannel(kind, *ptr, val, colorChannelMax[name])
+				}
+			}
+
+			return ast.NewHSLAColor(h, s, l, a, nil), nil
+		case hasRGB:
+			r, g, b := c.R, c.G, c.B
+			rgb := map[string]*float64{"$red": &r, "$green": &g, "$blue": &b}
+
+			for name, ptr := range rgb {
+				if val, ok := values[name]; ok {
+					*ptr = colorUpdateChannel(kind, *ptr, val, 255)
+				}
+			}
+
+			return ast.NewRGBAColor(r, g, b, a, nil), nil
+		}
+
+		return c.WithAlpha(a), nil
+	}
+}
+
+// colorUpdateNames are the arguments of color.adjust(), color.scale()
+// and color.change(), which are only accepted by name
+var colorUpdateNames = []string{"$red", "$green", "$blue", "$hue", "$saturation", "$lightness", "$chroma", "$a", "$b", "$x", "$y", "$z", "$alpha", "$space"}
+
+/*
+colorUpdateArguments orders the keyword arguments of color.adjust(),
+color.scale() and color.change() like colorUpdateNames, the missing ones
+are null:
+
+	color.adjust(red, $alpha: -0.5)
+*/
+func colorUpdateArguments(args *BuiltinArguments) (*BuiltinArguments, error) {
+	rest, _ := args.Value(1).(*ast.List)
+
+	if rest != nil && len(rest.Exprs) > 0 {
+		return nil, fmt.Errorf("Only one positional argument is allowed. All other arguments must be passed by name.")
+	}
+
+	out := &BuiltinArguments{
+		Names:  append([]string{args.Names[0]}, colorUpdateNames...),
+		Values: []ast.Value{args.Value(0)},
+		Scope:  args.Scope,
+	}
+
+	for range colorUpdateNames {
+		out.Values = append(out.Values, ast.NewNullWithToken(nil))
+	}
+
+	if rest == nil || rest.Keywords == nil {
+		return out, nil
+	}
+
+	for _, item := range rest.Keywords.Items {
+		name := "$" + item.Key.(*ast.String).Value
+		idx := slices.Index(colorUpdateNames, name)
+
+		if idx < 0 {
+			return nil, fmt.Errorf("No argument named %s.", name)
+		}
+
+		out.Values[idx+1] = withoutSlash(item.Value, args.Scope)
+	}
+
+	return out, nil
+}
+
+// colorUpdateValue validates the argument of a channel, scale() takes
+// percentages and the other ones take the values of the channels
+func colorUpdateValue(kind colorUpdateKind, name string, num *ast.Number) (float64, error) {
+	max := colorChannelMax[name]
+
+	switch {
+	case name == "$hue" && kind == colorScale:
+		return 0, fmt.Errorf("%s: Channel isn't scalable.", name)
+	case name == "$hue":
+		return num.Value, nil
+	case kind == colorScale:
+		if num.UnitString() != "%" {
+			return 0, fmt.Errorf("%s: Expected %s to have unit \"%%\".", name, num)
+		}
+		return checkRange(name, num, -100, 100, "%")
+	}
+
+	min := -max
+
+	if kind == colorChange {
+		min = 0
+	}
+
+	unit := ""
+
+	if max == 100 {
+		unit = "%"
+	}
+
+	return checkRange(name, num, min, max, unit)
+}
+
+// colorUpdateChannel returns the updated value of the channel, the
+// channels which are not hues are clamped to their bounds
+func colorUpdateChannel(kind colorUpdateKind, current, val, max float64) float64 {
+	switch kind {
+	case colorAdjust:
+		if max == 0 {
+			return current + val
+		}
+		return clamp(current+val, 0, max)
+	case colorScale:
+		if val > 0 {
+			return current + (max-current)*val/100
+		}
+		return current + current*val/100
+	}
+
+	return val
+}
+
+/*
+colorShift returns the builtin function of the legacy functions moving a
+channel by $amount, the channels are 1 for the saturation, 2 for the
+lightness and 3 for the alpha:
+
+	lighten($color, $amount) => colorShift(2, 100, 1)
+*/
+func colorShift(ch int, max, sign float64) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		color := args.LegacyColor
+
+		// the alpha channel is shared by all the spaces
+		if ch == 3 {
+			color = args.Color
+		}
+
+		c, err := color(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err := args.NumberInRange(1, 0, max)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ch == 3 {
+			return c.WithAlpha(c.A + sign*amount), nil
+		}
+
+		hsl := [3]float64{c.H, c.S, c.L}
+		hsl[ch] += sign * amount
+
+		return ast.NewHSLAColor(hsl[0], hsl[1], hsl[2], c.A, nil), nil
+	}
 }
 
-func RGBAColorMulNumber(c *ast.RGBAColor, n *ast.Number) *ast.RGBAColor {
-	var val = uint32(n.Value)
-	var r = c.R * val
-	var g = c.G * val
-	var b = c.B * val
-	return ast.NewRGBAColor(r, g, b, c.A, nil)
-}
-
-func RGBAColorDivNumber(c *ast.RGBAColor, n *ast.Number) *ast.RGBAColor {
-	var val = n.Value
-	var r = math.Floor(float64(c.R) / val)
-	var g = math.Floor(float64(c.G) / val)
-	var b = math.Floor(float64(c.B) / val)
-	return ast.NewRGBAColor(uint32(r), uint32(g), uint32(b), c.A, nil)
+// colorSaturate is saturate($color, $amount), saturate($amount) is the
+// css filter function
+func colorSaturate(args *BuiltinArguments) (ast.Value, error) {
+	if num, ok := args.Value(0).(*ast.Number); ok && args.IsNull(1) {
+		return ast.NewString(0, fmt.Sprintf("saturate(%s)", num), nil), nil
+	}
+
+	if args.IsNull(1) {
+		return nil, fmt.Errorf("Missing argument $amount.")
+	}
+
+	return colorShift(1, 100, 1)(args)
+}
+
+func colorAdjustHue(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.LegacyColor(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	degrees, err := args.Number(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewHSLAColor(c.H+degrees.Value, c.S, c.L, c.A, nil), nil
+}
+
+// mixColors mixes the colors like sass does, the weight of the first color
+// is 0~1 and the alpha channels are taken into account
+func mixColors(c1, c2 *ast.Color, weight float64) *ast.Color {
+	w := weight*2 - 1
+	a := c1.A - c2.A
+
+	var w1 float64
+
+	if w*a == -1 {
+		w1 = (w + 1) / 2
+	} else {
+		w1 = ((w+a)/(1+w*a) + 1) / 2
+	}
+
+	w2 := 1 - w1
+
+	return ast.NewRGBAColor(
+		c1.R*w1+c2.R*w2,
+		c1.G*w1+c2.G*w2,
+		c1.B*w1+c2.B*w2,
+		c1.A*weight+c2.A*(1-weight),
+		nil,
+	)
+}
+
+func colorMix(args *BuiltinArguments) (ast.Value, error) {
+	c1, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c2, err := args.Color(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weight, err := args.NumberInRange(2, 0, 100)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !args.IsNull(3) {
+		method, err := args.InterpolationMethod(3)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return interpolateColors(c1, c2, weight/100, method), nil
+	}
+
+	if !c1.Space.Legacy || !c2.Space.Legacy {
+		return nil, fmt.Errorf("$method: To use color.mix() with non-legacy colors, you must provide a $method.")
+	}
+
+	return mixColors(c1, c2, weight/100), nil
+}
+
+func colorComplement(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.LegacyColor(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewHSLAColor(c.H+180, c.S, c.L, c.A, nil), nil
+}
+
+// colorInvert is invert($color, $weight), invert($number) is the css
+// filter function
+func colorInvert(args *BuiltinArguments) (ast.Value, error) {
+	if num, ok := args.Value(0).(*ast.Number); ok {
+		return ast.NewString(0, fmt.Sprintf("invert(%s)", num), nil), nil
+	}
+
+	c, err := args.LegacyColor(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	weight, err := args.NumberInRange(1, 0, 100)
+
+	if err != nil {
+		return nil, err
+	}
+
+	inverse := ast.NewRGBAColor(255-c.R, 255-c.G, 255-c.B, c.A, nil)
+
+	return mixColors(inverse, c, weight/100), nil
+}
+
+// colorGrayscale is grayscale($color), grayscale($number) is the css
+// filter function
+func colorGrayscale(args *BuiltinArguments) (ast.Value, error) {
+	if num, ok := args.Value(0).(*ast.Number); ok {
+		return ast.NewString(0, fmt.Sprintf("grayscale(%s)", num), nil), nil
+	}
+
+	c, err := args.LegacyColor(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewHSLAColor(c.H, 0, c.L, c.A, nil), nil
+}
+
+func colorAlpha(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewNumber(c.A, nil, nil), nil
+}
+
+// colorChannel returns the builtin function returning the red (0), the
+// green (1) or the blue (2) channel of the color
+func colorChannel(ch int) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		c, err := args.LegacyColor(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rgb := [3]int{}
+		rgb[0], rgb[1], rgb[2] = c.RGB()
+
+		return ast.NewNumber(float64(rgb[ch]), nil, nil), nil
+	}
+}
+
+func colorHue(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.LegacyColor(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewNumber(c.H, ast.NewUnit(ast.T_UNIT_DEG, nil), nil), nil
+}
+
+// colorPercentChannel returns the builtin function returning the
+// saturation (1) or the lightness (2) of the color
+func colorPercentChannel(ch int) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		c, err := args.LegacyColor(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		hsl := [3]float64{c.H, c.S, c.L}
+
+		return ast.NewNumber(hsl[ch], ast.NewUnit(ast.T_UNIT_PERCENT, nil), nil), nil
+	}
+}
+
+// colorIEHexStr returns the #AARRGGBB form of the color used by the
+// filters of internet explorer
+func colorIEHexStr(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, g, b := c.RGB()
+	a := uint32(fuzzyRound(c.A * 255))
+	str := fmt.Sprintf("#%02X%02X%02X%02X", a, r, g, b)
+
+	return ast.NewString(0, str, nil), nil
+}
+
+/*
+ComputeColor applies the arithmetic operator to each rgb channel of the
+color, the other operand is a unitless number or a color with the same
+alpha channel. Only the legacy colors support arithmetic:
+
+	#010203 + #040506 => #050709
+	#102030 * 2 => #204060
+	#102030 + hsl(0, 100%, 50%) => #ff2030
+*/
+func ComputeColor(op *ast.Op, c *ast.Color, other ast.Value) (ast.Value, error) {
+	var r, g, b float64
+
+	// the channels of the other spaces are not added up
+	if o, ok := other.(*ast.Color); !c.Space.Legacy || (ok && !o.Space.Legacy) {
+		return nil, fmt.Errorf("Undefined operation \"%s %s %s\".", c, op, other)
+	}
+
+	switch o := other.(type) {
+	case *ast.Number:
+		if !o.IsUnitless() {
+			return nil, fmt.Errorf("Undefined operation \"%s %s %s\".", c, op, o)
+		}
+
+		r, g, b = o.Value, o.Value, o.Value
+	case *ast.Color:
+		if !ast.FuzzyEqual(c.A, o.A) {
+			return nil, fmt.Errorf("Alpha channels must be equal: %s %s %s", c, op, o)
+		}
+
+		r, g, b = o.R, o.G, o.B
+	default:
+		return nil, fmt.Errorf("Undefined operation \"%s %s %s\".", c, op, other)
+	}
+
+	var fn func(x, y float64) float64
+
+	switch op.Type {
+	case ast.T_PLUS:
+		fn = func(x, y float64) float64 { return x + y }
+	case ast.T_MINUS:
+		fn = func(x, y float64) float64 { return x - y }
+	case ast.T_MUL:
+		fn = func(x, y float64) float64 { return x * y }
+	case ast.T_DIV:
+		fn = func(x, y float64) float64 { return x / y }
+	default:
+		return nil, fmt.Errorf("Undefined operation \"%s %s %s\".", c, op, other)
+	}
+
+	return ast.NewRGBAColor(fn(c.R, r), fn(c.G, g), fn(c.B, b), c.A, nil), nil
 }
@@ -0,0 +1,434 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c9s/c6/ast"
+)
+
+/*
+spaceChannelValue returns the value of a channel of a non legacy color,
+the hues are angles and the other channels take percentages of their
+maximum:
+
+	oklch(50% 0.1 0.5turn) => 0.5, 0.1, 180
+*/
+func spaceChannelValue(name string, ch ast.ColorChannel, num *ast.Number) (float64, error) {
+	if ch.Hue {
+		value, ok := num.ValueIn(ast.NewNumber(1, ast.NewUnit(ast.T_UNIT_DEG, nil), nil))
+
+		if !ok {
+			return 0, fmt.Errorf("%s: Expected %s to be an angle.", name, num)
+		}
+
+		return value, nil
+	}
+
+	switch num.UnitString() {
+	case "":
+		return num.Value, nil
+	case "%":
+		return num.Value / 100 * ch.Max, nil
+	}
+
+	return 0, fmt.Errorf("%s: Expected %s to have unit \"%%\" or no units.", name, num)
+}
+
+// ColorSpace returns the color space named by the argument
+func (args *BuiltinArguments) ColorSpace(idx int) (*ast.ColorSpace, error) {
+	str, err := args.String(idx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	space, ok := ast.LookupColorSpace(str.Value)
+
+	if !ok {
+		return nil, fmt.Errorf("%s: Unknown color space \"%s\".", args.Names[idx], str.Value)
+	}
+
+	return space, nil
+}
+
+// LegacyColor returns the argument as a color of the rgb or hsl space, the
+// functions working on the rgb and hsl channels only take legacy colors
+func (args *BuiltinArguments) LegacyColor(idx int) (*ast.Color, error) {
+	c, err := args.Color(idx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.Space.Legacy {
+		return nil, fmt.Errorf("%s: %s is not a legacy color.", args.Names[idx], c)
+	}
+
+	return c, nil
+}
+
+// colorSpaceArgument returns the space of the optional $space argument,
+// the space of the color by default
+func colorSpaceArgument(args *BuiltinArguments, idx int, c *ast.Color) (*ast.ColorSpace, error) {
+	if args.IsNull(idx) {
+		return c.Space, nil
+	}
+
+	return args.ColorSpace(idx)
+}
+
+func colorSpace(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewString(0, c.Space.Name, nil), nil
+}
+
+func colorIsLegacy(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewBoolean(c.Space.Legacy), nil
+}
+
+/*
+colorToSpace is color.to-space($color, $space), the colors converted to rgb
+or hsl are mapped to the srgb gamut:
+
+	color.to-space(red, oklch) => oklch(62.7955363921% 0.2576833038 29.2338802796deg)
+*/
+func colorToSpace(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	space, err := args.ColorSpace(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ToSpace(space), nil
+}
+
+/*
+colorChannelByName is color.channel($color, $channel, $space: null), the
+hues are returned in degrees and the lightness of lab and oklab, and the
+saturation and the lightness of hsl in percents:
+
+	color.channel(hsl(80deg 30% 50%), "hue") => 80deg
+	color.channel(oklch(60% 0.1 120deg), "lightness") => 60%
+*/
+func colorChannelByName(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := args.String(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	space, err := colorSpaceArgument(args, 2, c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if name.Value == "alpha" {
+		return ast.NewNumber(c.A, nil, nil), nil
+	}
+
+	idx, ok := space.ChannelIndex(name.Value)
+
+	if !ok {
+		return nil, fmt.Errorf("$channel: Color %s has no channel named %s.", c, name.Value)
+	}
+
+	ch := space.Channels[idx]
+	value := c.ToSpace(space).Channels()[idx]
+
+	switch {
+	case ch.Hue:
+		return ast.NewNumber(value, ast.NewUnit(ast.T_UNIT_DEG, nil), nil), nil
+	case ch.Name == "lightness" || ch.Name == "saturation":
+		return ast.NewNumber(value*100/ch.Max, ast.NewUnit(ast.T_UNIT_PERCENT, nil), nil), nil
+	}
+
+	return ast.NewNumber(value, nil, nil), nil
+}
+
+func colorIsInGamut(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	space, err := colorSpaceArgument(args, 1, c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewBoolean(c.IsInGamut(space)), nil
+}
+
+/*
+colorToGamut is color.to-gamut($color, $space: null, $method: null), the
+method is clip or local-minde, which keeps the hue and the lightness:
+
+	color.to-gamut(oklch(70% 0.4 30deg), $space: rgb, $method: clip) => oklch(62.7955363921% 0.2576833038 29.2338802796deg)
+*/
+func colorToGamut(args *BuiltinArguments) (ast.Value, error) {
+	c, err := args.Color(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	space, err := colorSpaceArgument(args, 1, c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	method := "local-minde"
+
+	if !args.IsNull(2) {
+		str, err := args.String(2)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if str.Value != "clip" && str.Value != "local-minde" {
+			return nil, fmt.Errorf("$method: Unknown gamut map method \"%s\".", str.Value)
+		}
+
+		method = str.Value
+	}
+
+	return c.ToGamut(space, method), nil
+}
+
+// interpolationMethod is the $method of color.mix(), a space and how the
+// hues are interpolated in the polar spaces
+type interpolationMethod struct {
+	space *ast.ColorSpace
+	hue   string
+}
+
+/*
+InterpolationMethod returns the interpolation method of the argument, the
+polar spaces take shorter, longer, increasing or decreasing hue:
+
+	oklch
+	oklch longer hue
+*/
+func (args *BuiltinArguments) InterpolationMethod(idx int) (*interpolationMethod, error) {
+	name := args.Names[idx]
+
+	var words []string
+
+	for _, expr := range args.List(idx) {
+		str, ok := expr.(*ast.String)
+
+		if !ok {
+			return nil, fmt.Errorf("%s: %s is not a string.", name, expr)
+		}
+
+		words = append(words, str.Value)
+	}
+
+	space, ok := ast.LookupColorSpace(words[0])
+
+	if !ok {
+		return nil, fmt.Errorf("%s: Unknown color space \"%s\".", name, words[0])
+	}
+
+	method := &interpolationMethod{space: space, hue: "shorter"}
+
+	switch {
+	case len(words) == 1:
+	case len(words) == 3 && space.Polar() && words[2] == "hue":
+		switch words[1] {
+		case "shorter", "longer", "increasing", "decreasing":
+			method.hue = words[1]
+		default:
+			return nil, fmt.Errorf("%s: Unknown hue interpolation method %s.", name, words[1])
+		}
+	case !space.Polar():
+		return nil, fmt.Errorf("%s: Hue interpolation method \"%s\" may not be set for rectangular color space %s.",
+			name, strings.Join(words[1:], " "), space)
+	default:
+		return nil, fmt.Errorf("%s: Expected \"%s\" to be a hue interpolation method.", name, strings.Join(words[1:], " "))
+	}
+
+	return method, nil
+}
+
+// isPowerless tells whether the hue of the channels has no effect on the
+// color, the hue of a gray color
+func isPowerless(space *ast.ColorSpace, coords [3]float64) bool {
+	return ast.FuzzyEqual(coords[1], 0) && space.Polar()
+}
+
+// fixupHues adjusts the hues so that interpolating from h1 to h2 follows
+// the hue interpolation method
+func fixupHues(method string, h1, h2 float64) (float64, float64) {
+	diff := h2 - h1
+
+	switch method {
+	case "shorter":
+		if diff > 180 {
+			h1 += 360
+		} else if diff < -180 {
+			h2 += 360
+		}
+	case "longer":
+		if 0 < diff && diff < 180 {
+			h1 += 360
+		} else if -180 < diff && diff <= 0 {
+			h2 += 360
+		}
+	case "increasing":
+		if diff < 0 {
+			h2 += 360
+		}
+	case "decreasing":
+		if diff > 0 {
+			h1 += 360
+		}
+	}
+
+	return h1, h2
+}
+
+/*
+interpolateColors mixes the colors in the space of the method with
+premultiplied alpha, the weight of the first color is 0~1. The result is
+converted back to the space of the first color:
+
+	color.mix(red, blue, $method: oklch) => #b700be
+*/
+func interpolateColors(c1, c2 *ast.Color, weight float64, method *interpolationMethod) *ast.Color {
+	space := method.space
+	from := c1.ToSpace(space).Channels()
+	to := c2.ToSpace(space).Channels()
+	hue := -1
+
+	for idx, ch := range space.Channels {
+		if ch.Hue {
+			hue = idx
+		}
+	}
+
+	// a gray color takes the hue of the other color
+	if hue >= 0 {
+		switch {
+		case isPowerless(space, from) && !isPowerless(space, to):
+			from[hue] = to[hue]
+		case isPowerless(space, to):
+			to[hue] = from[hue]
+		}
+
+		from[hue], to[hue] = fixupHues(method.hue, from[hue], to[hue])
+	}
+
+	a := c1.A*weight + c2.A*(1-weight)
+
+	var coords [3]float64
+
+	for idx := range coords {
+		if idx == hue {
+			coords[idx] = from[idx]*weight + to[idx]*(1-weight)
+			continue
+		}
+
+		coords[idx] = from[idx]*c1.A*weight + to[idx]*c2.A*(1-weight)
+
+		if a != 0 {
+			coords[idx] /= a
+		}
+	}
+
+	return ast.NewSpaceColor(space, coords, a, nil).ToSpace(c1.Space)
+}
+
+// colorSpaceUpdate applies the channels of adjust(), scale() and change()
+// in the space, the color is converted back to its own space
+func colorSpaceUpdate(kind colorUpdateKind, c *ast.Color, space *ast.ColorSpace, nums map[string]*ast.Number) (*ast.Color, error) {
+	coords := c.ToSpace(space).Channels()
+	a := c.A
+
+	for name, num := range nums {
+		if name == "$alpha" {
+			val, err := colorUpdateValue(kind, name, num)
+
+			if err != nil {
+				return nil, err
+			}
+
+			a = colorUpdateChannel(kind, a, val, 1)
+			continue
+		}
+
+		idx, ok := space.ChannelIndex(strings.TrimPrefix(name, "$"))
+
+		if !ok {
+			return nil, fmt.Errorf("%s: Color space %s doesn't have a channel with this name.", name, space)
+		}
+
+		ch := space.Channels[idx]
+
+		if kind == colorScale {
+			if ch.Hue {
+				return nil, fmt.Errorf("%s: Channel isn't scalable.", name)
+			}
+
+			if num.UnitString() != "%" {
+				return nil, fmt.Errorf("%s: Expected %s to have unit \"%%\".", name, num)
+			}
+
+			val, err := checkRange(name, num, -100, 100, "%")
+
+			if err != nil {
+				return nil, err
+			}
+
+			if val > 0 {
+				coords[idx] += (ch.Max - coords[idx]) * val / 100
+			} else {
+				coords[idx] += (coords[idx] - ch.Min) * val / 100
+			}
+			continue
+		}
+
+		val, err := spaceChannelValue(name, ch, num)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if kind == colorAdjust {
+			coords[idx] += val
+		} else {
+			coords[idx] = val
+		}
+	}
+
+	return ast.NewSpaceColor(space, coords, a, nil).ToSpace(c.Space), nil
+}
@@ -0,0 +1,63 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/c9s/c6/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorArgumentKeyword(t *testing.T) {
+	red, _ := ast.NewColorFromKeyword("Red", nil)
+	args := &BuiltinArguments{Names: []string{"$color"}, Values: []ast.Value{red}}
+
+	c, err := args.Color(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "#ff0000", c.Hex())
+
+	args.Values[0] = ast.NewString('"', "red", nil)
+	_, err = args.Color(0)
+	assert.EqualError(t, err, `$color: "red" is not a color.`)
+}
+
+func TestMixColors(t *testing.T) {
+	white := ast.NewRGBAColor(255, 255, 255, 1, nil)
+	black := ast.NewRGBAColor(0, 0, 0, 1, nil)
+
+	assert.Equal(t, "gray", mixColors(white, black, 0.5).String())
+	assert.Equal(t, "white", mixColors(white, black, 1).String())
+}
+
+func TestFixupHues(t *testing.T) {
+	h1, h2 := fixupHues("shorter", 10, 350)
+	assert.Equal(t, []float64{370, 350}, []float64{h1, h2})
+
+	h1, h2 = fixupHues("longer", 10, 50)
+	assert.Equal(t, []float64{370, 50}, []float64{h1, h2})
+
+	h1, h2 = fixupHues("increasing", 50, 10)
+	assert.Equal(t, []float64{50, 370}, []float64{h1, h2})
+
+	h1, h2 = fixupHues("decreasing", 10, 50)
+	assert.Equal(t, []float64{370, 50}, []float64{h1, h2})
+}
+
+func TestInterpolateColorsPowerlessHue(t *testing.T) {
+	oklch := ast.ColorSpaces["oklch"]
+	gray := ast.NewSpaceColor(oklch, [3]float64{0.5, 0, 0}, 1, nil)
+	blue := ast.NewSpaceColor(oklch, [3]float64{0.5, 0.2, 260}, 1, nil)
+
+	c := interpolateColors(gray, blue, 0.5, &interpolationMethod{space: oklch, hue: "shorter"})
+	assert.Equal(t, "oklch(50% 0.1 260deg)", c.String())
+}
+
+func TestSpaceChannelValue(t *testing.T) {
+	lab := ast.ColorSpaces["lab"]
+
+	v, err := spaceChannelValue("$channels", lab.Channels[1], ast.NewNumber(50, ast.NewUnit(ast.T_UNIT_PERCENT, nil), nil))
+	assert.NoError(t, err)
+	assert.Equal(t, 62.5, v)
+
+	_, err = spaceChannelValue("$channels", lab.Channels[1], ast.NewNumber(5, ast.NewUnit(ast.T_UNIT_PX, nil), nil))
+	assert.EqualError(t, err, `$channels: Expected 5px to have unit "%" or no units.`)
+}
@@ -5,21 +5,9 @@ import "github.com/c9s/c6/ast"
 func IsComparable(av ast.Value, bv ast.Value) bool {
 	switch a := av.(type) {
 
-	case *ast.HexColor:
+	case *ast.Color:
 		switch bv.(type) {
-		case *ast.HexColor:
-			return true
-		}
-
-	case *ast.RGBColor:
-		switch bv.(type) {
-		case *ast.RGBColor:
-			return true
-		}
-
-	case *ast.RGBAColor:
-		switch bv.(type) {
-		case *ast.RGBAColor:
+		case *ast.Color:
 			return true
 		}
 
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/c9s/c6/ast"
 )
@@ -85,12 +86,10 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				if IsComparable(ta, tb) {
-					return ast.NewBoolean(ta.Value == tb.Value), nil
-				} else {
-					return nil, fmt.Errorf("Can't compare number (unit different)")
-				}
+				return ast.NewBoolean(ast.Equal(ta, tb)), nil
 			}
+		case *ast.Color:
+			return ast.NewBoolean(ast.Equal(ta, b)), nil
 		}
 
 	case ast.T_UNEQUAL:
@@ -101,15 +100,18 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 			case *ast.Boolean:
 				return ast.NewBoolean(ta.Value != tb.Value), nil
 			}
+		case *ast.String:
+			switch tb := b.(type) {
+			case *ast.String:
+				return ast.NewBoolean(ta.Value != tb.Value), nil
+			}
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				if IsComparable(ta, tb) {
-					return ast.NewBoolean(ta.Value != tb.Value), nil
-				} else {
-					return nil, fmt.Errorf("Can't compare number (unit different)")
-				}
+				return ast.NewBoolean(!ast.Equal(ta, tb)), nil
 			}
+		case *ast.Color:
+			return ast.NewBoolean(!ast.Equal(ta, b)), nil
 		}
 
 	case ast.T_GT:
@@ -118,11 +120,11 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				if IsComparable(ta, tb) {
-					return ast.NewBoolean(ta.Value > tb.Value), nil
-				} else {
-					return nil, fmt.Errorf("Can't compare number (unit different)")
+				x, y, _, err := numberOperands(ta, tb)
+				if err != nil {
+					return nil, err
 				}
+				return ast.NewBoolean(ast.FuzzyLessThan(y, x)), nil
 			}
 		}
 
@@ -132,11 +134,11 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				if IsComparable(ta, tb) {
-					return ast.NewBoolean(ta.Value >= tb.Value), nil
-				} else {
-					return nil, fmt.Errorf("Can't compare number (unit different)")
+				x, y, _, err := numberOperands(ta, tb)
+				if err != nil {
+					return nil, err
 				}
+				return ast.NewBoolean(ast.FuzzyLessThanOrEqual(y, x)), nil
 			}
 		}
 
@@ -146,11 +148,11 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				if IsComparable(ta, tb) {
-					return ast.NewBoolean(ta.Value < tb.Value), nil
-				} else {
-					return nil, fmt.Errorf("Can't compare number (unit different)")
+				x, y, _, err := numberOperands(ta, tb)
+				if err != nil {
+					return nil, err
 				}
+				return ast.NewBoolean(ast.FuzzyLessThan(x, y)), nil
 			}
 		}
 
@@ -160,11 +162,11 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
-				if IsComparable(ta, tb) {
-					return ast.NewBoolean(ta.Value <= tb.Value), nil
-				} else {
-					return nil, fmt.Errorf("Can't compare number (unit different)")
+				x, y, _, err := numberOperands(ta, tb)
+				if err != nil {
+					return nil, err
 				}
+				return ast.NewBoolean(ast.FuzzyLessThanOrEqual(x, y)), nil
 			}
 		}
 
@@ -211,24 +213,11 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 			switch tb := b.(type) {
 			case *ast.Number:
 				return NumberAddNumber(ta, tb)
-			case *ast.HexColor:
-				return HexColorAddNumber(tb, ta), nil
-			}
-		case *ast.HexColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return HexColorAddNumber(ta, tb), nil
-			}
-		case *ast.RGBColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBColorAddNumber(ta, tb), nil
-			}
-		case *ast.RGBAColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBAColorAddNumber(ta, tb), nil
+			case *ast.Color:
+				return ComputeColor(op, tb, ta)
 			}
+		case *ast.Color:
+			return ComputeColor(op, ta, b)
 		}
 	case ast.T_MINUS:
 		switch ta := a.(type) {
@@ -237,47 +226,32 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 			case *ast.Number:
 				return NumberSubNumber(ta, tb)
 			}
-		case *ast.HexColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return HexColorSubNumber(ta, tb), nil
-			}
+		case *ast.Color:
+			return ComputeColor(op, ta, b)
+		}
 
-		case *ast.RGBColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBColorSubNumber(ta, tb), nil
-			}
+	case ast.T_DIV:
+		// the strings are separated by the slash: auto/$ratio => auto/2
+		if _, ok := a.(*ast.String); ok {
+			return slashList(a, b), nil
+		}
+		if _, ok := b.(*ast.String); ok {
+			return slashList(a, b), nil
+		}
 
-		case *ast.RGBAColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBAColorSubNumber(ta, tb), nil
-			}
+		// as well as the css functions: 1 / var(--x) => 1/var(--x)
+		if isSpecialValue(a) || isSpecialValue(b) {
+			return slashList(a, b), nil
 		}
 
-	case ast.T_DIV:
 		switch ta := a.(type) {
 		case *ast.Number:
 			switch tb := b.(type) {
 			case *ast.Number:
 				return NumberDivNumber(ta, tb), nil
 			}
-		case *ast.HexColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return HexColorDivNumber(ta, tb), nil
-			}
-		case *ast.RGBColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBColorDivNumber(ta, tb), nil
-			}
-		case *ast.RGBAColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBAColorDivNumber(ta, tb), nil
-			}
+		case *ast.Color:
+			return ComputeColor(op, ta, b)
 		}
 
 	case ast.T_MUL:
@@ -286,25 +260,12 @@ func Compute(op *ast.Op, a ast.Value, b ast.Value) (ast.Value, error) {
 			switch tb := b.(type) {
 			case *ast.Number:
 				return NumberMulNumber(ta, tb), nil
+			case *ast.Color:
+				return ComputeColor(op, tb, ta)
 			}
 
-		case *ast.HexColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return HexColorMulNumber(ta, tb), nil
-			}
-
-		case *ast.RGBColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBColorMulNumber(ta, tb), nil
-			}
-
-		case *ast.RGBAColor:
-			switch tb := b.(type) {
-			case *ast.Number:
-				return RGBAColorMulNumber(ta, tb), nil
-			}
+		case *ast.Color:
+			return ComputeColor(op, ta, b)
 		}
 	}
 	return nil, nil
@@ -332,7 +293,7 @@ This function returns true when the val is a scalar value, not an expression.
 */
 func IsValue(val ast.Expr) bool {
 	switch val.(type) {
-	case *ast.Number, *ast.HexColor, *ast.RGBColor, *ast.RGBAColor, *ast.HSLColor, *ast.HSVColor, *ast.Boolean:
+	case *ast.Number, *ast.Color, *ast.Boolean:
 		return true
 	}
 	return false
@@ -354,6 +315,9 @@ func EvaluateExprInBooleanContext(anyexpr ast.Expr, scope *Scope) (ast.Value, er
 			return lval, nil
 		}
 
+	case *ast.FunctionCall:
+		return EvaluateFunctionCall(expr, scope)
+
 	default:
 		if bval, ok := expr.(ast.BooleanValue); ok {
 			return ast.NewBoolean(bval.Boolean()), nil
@@ -386,6 +350,11 @@ func EvaluateBinaryExprInBooleanContext(expr *ast.BinaryExpr, scope *Scope) (ast
 			return nil, err
 		}
 
+	case *ast.FunctionCall:
+		if lval, err = EvaluateFunctionCall(expr, scope); err != nil {
+			return nil, err
+		}
+
 	default:
 		lval = expr
 	}
@@ -408,6 +377,11 @@ func EvaluateBinaryExprInBooleanContext(expr *ast.BinaryExpr, scope *Scope) (ast
 			return nil, err
 		}
 
+	case *ast.FunctionCall:
+		if rval, err = EvaluateFunctionCall(expr, scope); err != nil {
+			return nil, err
+		}
+
 	default:
 		rval = expr
 	}
@@ -448,41 +422,126 @@ func EvaluateUnaryExprInBooleanContext(expr *ast.UnaryExpr, scope *Scope) (ast.V
 	return val, nil
 }
 
-func EvaluateRGBColor(args *ast.CallArgumentList, scope *Scope) (ast.Value, error) {
+/*
+colorFunctionValues evaluates the arguments of a color function, the
+channels are given as arguments or as a space separated list. The alpha
+channel following a slash is returned apart, nil if there is none:
+
+	rgb(0 0 0 / 50%)
+	oklch(70% 0.15 200deg / 0.5)
+*/
+func colorFunctionValues(args *ast.CallArgumentList, scope *Scope) ([]ast.Expr, ast.Value, error) {
 	var values []ast.Expr
 
-	if len(args.Args) == 1 {
-		v, err := EvaluateExpr(args.Args[0].Value, scope)
+	for _, a := range args.Args {
+		v, err := EvaluateExpr(a.Value, scope)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		l, ok := v.(*ast.List)
-		if !ok {
-			return nil, fmt.Errorf("the only argument is not a list")
+		if l, ok := v.(*ast.List); ok && len(args.Args) == 1 && l.Separator != "/" {
+			values = append(values, l.Exprs...)
+		} else {
+			values = append(values, v)
 		}
+	}
 
-		if len(l.Exprs) != 3 {
-			return nil, fmt.Errorf("rgb color expects 3 arguments but got %s", l.String())
-		}
+	var alpha ast.Value
 
-		values = l.Exprs
-	} else {
-		if len(args.Args) != 3 {
-			return nil, fmt.Errorf("rgb color expects 3 arguments but got %s", args.String())
+	if len(values) > 0 {
+		switch last := values[len(values)-1].(type) {
+		case *ast.Number:
+			if last.Slash != nil {
+				values[len(values)-1], alpha = last.Slash.Left, last.Slash.Right
+			}
+		case *ast.List:
+			if last.Separator == "/" && len(last.Exprs) == 2 {
+				values[len(values)-1], alpha = last.Exprs[0], last.Exprs[1]
+			}
 		}
+	}
 
-		for _, a := range args.Args {
-			v, err := EvaluateExpr(a.Value, scope)
-			if err != nil {
-				return nil, err
-			}
+	return values, alpha, nil
+}
 
-			values = append(values, v)
+// colorAlphaValue returns the alpha channel 0~1 of the argument, the
+// percents are converted
+func colorAlphaValue(alpha ast.Value) (float64, error) {
+	if alpha == nil {
+		return 1, nil
+	}
+
+	num, ok := alpha.(*ast.Number)
+	if !ok {
+		return 0, fmt.Errorf("$alpha: %s is not a number.", alpha)
+	}
+
+	if num.HasUnit(ast.T_UNIT_PERCENT) {
+		return num.Value / 100, nil
+	}
+
+	return num.Value, nil
+}
+
+/*
+legacyColorValues returns the channels and the alpha channel of rgb() and
+hsl(), the alpha channel may also be the fourth argument. A color is
+returned instead when the arguments are a color and an alpha channel:
+
+	rgb(0, 0, 0, 0.5)
+	rgb(#f00, 0.5)
+*/
+func legacyColorValues(name string, args *ast.CallArgumentList, scope *Scope) ([]ast.Expr, *ast.Color, float64, error) {
+	values, alpha, err := colorFunctionValues(args, scope)
+
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var c *ast.Color
+
+	switch {
+	case alpha == nil && len(values) == 4:
+		values, alpha = values[:3], values[3]
+	case alpha == nil && len(values) == 2:
+		var ok bool
+		if c, ok = values[0].(*ast.Color); !ok {
+			return nil, nil, 0, fmt.Errorf("$color: %s is not a color.", values[0])
 		}
+		values, alpha = nil, values[1]
+	case len(values) != 3:
+		return nil, nil, 0, fmt.Errorf("%s color expects 3 arguments but got %s", name, args.String())
+	}
+
+	a, err := colorAlphaValue(alpha)
+
+	if err != nil {
+		return nil, nil, 0, err
 	}
 
-	ints := [3]uint32{}
+	return values, c, a, nil
+}
+
+/*
+EvaluateRGBColor evaluates rgb() and rgba(), the color can be given by its
+channels or as a color with a new alpha channel:
+
+	rgb(255 0 0 / 50%)
+	rgba(255, 0, 0, 0.5)
+	rgba(#f00, 0.5)
+*/
+func EvaluateRGBColor(args *ast.CallArgumentList, scope *Scope) (ast.Value, error) {
+	values, c, a, err := legacyColorValues("rgb", args, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		return c.WithAlpha(a), nil
+	}
+
+	rgb := [3]float64{}
 
 	for idx, e := range values {
 		num, ok := e.(*ast.Number)
@@ -490,101 +549,362 @@ func EvaluateRGBColor(args *ast.CallArgumentList, scope *Scope) (ast.Value, erro
 			return nil, fmt.Errorf("Argument is not a number: %s - %T", e.String(), e)
 		}
 
-		rounded := num.Integer()
-		if rounded < 0 {
-			rounded = 0
+		rgb[idx] = num.Value
+
+		if num.HasUnit(ast.T_UNIT_PERCENT) {
+			rgb[idx] = num.Value * 255 / 100
 		}
+	}
+
+	return ast.NewRGBAColor(rgb[0], rgb[1], rgb[2], a, nil), nil
+}
+
+/*
+EvaluateHSLColor evaluates hsl() and hsla(), the hue is an angle:
 
-		if rounded > 255 {
-			rounded = 255
+	hsl(0.5turn 100% 50% / 0.5)
+	hsla(120, 100%, 50%, 0.5)
+*/
+func EvaluateHSLColor(args *ast.CallArgumentList, scope *Scope) (ast.Value, error) {
+	values, c, a, err := legacyColorValues("hsl", args, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if c != nil {
+		return c.WithAlpha(a), nil
+	}
+
+	nums := [3]float64{}
+
+	for idx, e := range values {
+		num, ok := e.(*ast.Number)
+		if !ok {
+			return nil, fmt.Errorf("Argument is not a number: %s - %T", e.String(), e)
 		}
 
-		ints[idx] = uint32(rounded)
+		// the saturation and the lightness are percents with or
+		// without the unit, the hue wraps around
+		nums[idx] = num.Value
+
+		if idx == 0 {
+			if nums[idx], ok = num.ValueIn(ast.NewNumber(1, ast.NewUnit(ast.T_UNIT_DEG, nil), nil)); !ok {
+				return nil, fmt.Errorf("$hue: Expected %s to be an angle.", num)
+			}
+		}
 	}
 
-	color := ast.NewRGBColor(ints[0], ints[1], ints[2], nil)
-	return color, nil
+	return ast.NewHSLAColor(nums[0], nums[1], nums[2], a, nil), nil
 }
 
-func EvaluateHSLColor(args *ast.CallArgumentList, scope *Scope) (ast.Value, error) {
-	var values []ast.Expr
+/*
+EvaluateSpaceColor evaluates the color functions of CSS Color Level 4, the
+channels are space separated and the alpha channel follows a slash:
 
-	if len(args.Args) == 1 {
-		v, err := EvaluateExpr(args.Args[0].Value, scope)
-		if err != nil {
-			return nil, err
+	oklch(70% 0.15 200deg / 0.5)
+	color(display-p3 1 0 0)
+*/
+func EvaluateSpaceColor(name string, args *ast.CallArgumentList, scope *Scope) (ast.Value, error) {
+	values, alpha, err := colorFunctionValues(args, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	spaceName := name
+
+	if name == "color" && len(values) > 0 {
+		var space *ast.ColorSpace
+
+		if str, ok := values[0].(*ast.String); ok {
+			space, _ = ast.LookupColorSpace(str.Value)
+		}
+
+		if space == nil || space.Legacy {
+			return nil, fmt.Errorf("$description: Unknown color space \"%s\".", values[0])
+		}
+
+		spaceName, values = space.Name, values[1:]
+	}
+
+	space, _ := ast.LookupColorSpace(spaceName)
+
+	if len(values) != 3 {
+		return nil, fmt.Errorf("$channels: The %s color space has 3 channels but %d were given.", space, len(values))
+	}
+
+	var coords [3]float64
+	var missing [4]bool
+
+	for idx, v := range values {
+		if isNoneKeyword(v) {
+			missing[idx] = true
+			continue
 		}
 
-		l, ok := v.(*ast.List)
+		num, ok := v.(*ast.Number)
 		if !ok {
-			return nil, fmt.Errorf("the only argument is not a list")
+			return nil, fmt.Errorf("$channels: %s is not a number.", v)
 		}
 
-		if len(l.Exprs) != 3 {
-			return nil, fmt.Errorf("rgb color expects 3 arguments but got %s", l.String())
+		if coords[idx], err = spaceChannelValue("$channels", space.Channels[idx], num); err != nil {
+			return nil, err
+		}
+	}
+
+	if isNoneKeyword(alpha) {
+		alpha, missing[3] = ast.NewNumber(0, nil, nil), true
+	}
+
+	a, err := colorAlphaValue(alpha)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c := ast.NewSpaceColor(space, coords, a, nil)
+	c.Missing = missing
+	return c, nil
+}
+
+// isNoneKeyword tells whether the channel is the `none` keyword of the
+// missing channels
+func isNoneKeyword(v ast.Expr) bool {
+	str, ok := v.(*ast.String)
+	return ok && str.Quote == 0 && strings.EqualFold(str.Value, "none")
+}
+
+func EvaluateFunctionCall(fc *ast.FunctionCall, scope *Scope) (ast.Value, error) {
+	// user defined functions take precedence over the builtin ones
+	if fn, err := scope.LookupFunction(fc.NormalizedName()); err == nil {
+		return fn.Call(fc.Arguments, scope)
+	} else if _, _, ok := splitNamespace(fc.NormalizedName()); ok {
+		// functions of modules are never plain css functions
+		return nil, err
+	}
+
+	if fn, ok := globalFunctions[fc.NormalizedName()]; ok {
+		val, err := fn.Call(fc.Arguments, scope)
+
+		// min() and max() are css functions as well, e.g. min(10px, 5vw)
+		if err != nil && (fc.NormalizedName() == "min" || fc.NormalizedName() == "max") {
+			return EvaluateCalculation(fc, scope)
+		}
+
+		return val, err
+	}
+
+	switch fc.Ident.Str {
+	case "calc", "clamp":
+		return EvaluateCalculation(fc, scope)
+	}
+
+	// by default we assume that we've encountered a plain css function,
+	// we still need to evaluate its arguments
+	return EvaluateCssFunctionCall(fc, scope)
+}
+
+/*
+EvaluateColorFunction evaluates the color functions, the call is kept as a
+plain css function when a channel can only be computed by the browser:
+
+	rgb(0 0 0 / var(--alpha)) => rgb(0 0 0 / var(--alpha))
+*/
+func EvaluateColorFunction(fc *ast.FunctionCall, scope *Scope) (ast.Value, error) {
+	val, err := EvaluateCssFunctionCall(fc, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	call := val.(*ast.FunctionCall)
+
+	if call.Arguments == nil {
+		call.Arguments = &ast.CallArgumentList{}
+	}
+
+	for _, arg := range call.Arguments.Args {
+		if isSpecialValue(arg.Value) {
+			return call, nil
+		}
+	}
+
+	// rgba($color, $alpha) is a plain css function as well when the first
+	// argument isn't a color, e.g. rgba(var(--rgb), 0.5)
+	if args := call.Arguments.Args; len(args) == 2 && args[0].Name == nil {
+		switch args[0].Value.(type) {
+		case *ast.Color, *ast.Number:
+		default:
+			return call, nil
+		}
+	}
+
+	switch fc.Ident.Str {
+	case "rgb", "rgba":
+		return EvaluateRGBColor(call.Arguments, scope)
+	case "hsl", "hsla":
+		return EvaluateHSLColor(call.Arguments, scope)
+	}
+
+	return EvaluateSpaceColor(fc.Ident.Str, call.Arguments, scope)
+}
+
+// isSpecialValue tells whether the value is a css function that sass
+// can't compute, e.g. var(--x) or calc(100% - 10px)
+func isSpecialValue(v ast.Expr) bool {
+	switch t := v.(type) {
+	case *ast.FunctionCall:
+		switch t.NormalizedName() {
+		case "var", "env", "calc", "clamp", "min", "max":
+			return true
+		}
+	case *ast.List:
+		for _, e := range t.Exprs {
+			if isSpecialValue(e) {
+				return true
+			}
 		}
+	}
+	return false
+}
 
-		values = l.Exprs
-	} else {
-		if len(args.Args) != 3 {
-			return nil, fmt.Errorf("rgb color expects 3 arguments but got %s", args.String())
+/*
+EvaluateCssFunctionCall returns a copy of the function call with all
+the arguments evaluated, e.g. `translate($x, 2px)` => `translate(10px, 2px)`
+*/
+func EvaluateCssFunctionCall(fc *ast.FunctionCall, scope *Scope) (ast.Value, error) {
+	if fc.Arguments == nil {
+		return fc, nil
+	}
+
+	args := &ast.CallArgumentList{}
+
+	for _, arg := range fc.Arguments.Args {
+		evaluated := &ast.CallArgument{
+			Value:          arg.Value,
+			Name:           arg.Name,
+			VariableLength: arg.VariableLength,
 		}
 
-		for _, a := range args.Args {
-			v, err := EvaluateExpr(a.Value, scope)
+		if arg.Value != nil {
+			val, err := EvaluateExpr(arg.Value, scope)
 			if err != nil {
 				return nil, err
 			}
 
-			values = append(values, v)
+			evaluated.Value = val
 		}
+
+		args.Args = append(args.Args, evaluated)
 	}
 
-	nums := [3]float64{}
+	return &ast.FunctionCall{
+		Ident:     fc.Ident,
+		Arguments: args,
+	}, nil
+}
 
-	for idx, e := range values {
-		num, ok := e.(*ast.Number)
-		if !ok {
-			return nil, fmt.Errorf("Argument is not a number: %s - %T", e.String(), e)
+/*
+EvaluateCalculation evaluates calc(), clamp() and the css min() and max(),
+the operations on compatible numbers are computed while the others are
+kept as they are written:
+
+	calc(10px + 5px) => 15px
+	calc(100% - $gap) => calc(100% - 10px)
+*/
+func EvaluateCalculation(fc *ast.FunctionCall, scope *Scope) (ast.Value, error) {
+	if fc.Arguments == nil {
+		return fc, nil
+	}
+
+	args := &ast.CallArgumentList{}
+
+	for _, arg := range fc.Arguments.Args {
+		val, _, err := evaluateCalcOperand(arg.Value, scope)
+
+		if err != nil {
+			return nil, err
 		}
 
-		fl := num.Double()
+		args.Args = append(args.Args, &ast.CallArgument{
+			Value:          val,
+			Name:           arg.Name,
+			VariableLength: arg.VariableLength,
+		})
+	}
 
-		if num.Unit != nil && num.Unit.Type == ast.T_UNIT_PERCENT {
-			fl = fl / 100.0
+	if fc.NormalizedName() == "calc" && len(args.Args) == 1 {
+		if num, ok := args.Args[0].Value.(*ast.Number); ok {
+			return num, nil
 		}
+	}
 
-		if fl < 0 {
-			fl = 0
+	return &ast.FunctionCall{
+		Ident:     fc.Ident,
+		Arguments: args,
+	}, nil
+}
+
+/*
+evaluateCalcOperand evaluates the operand of a calculation, sum is set when
+the operand is a sum kept as it is written, which is wrapped in parentheses
+when it's an operand of a product:
+
+	(100% - 10px) * 2 => (100% - 10px) * 2
+	(10px + 5px) * 2 => 30px
+*/
+func evaluateCalcOperand(expr ast.Expr, scope *Scope) (val ast.Value, sum bool, err error) {
+	switch t := expr.(type) {
+	case *ast.BinaryExpr:
+		left, leftSum, err := evaluateCalcOperand(t.Left, scope)
+		if err != nil {
+			return nil, false, err
 		}
 
-		if idx == 0 {
-			if fl > 360 {
-				fl = 360.0
+		right, rightSum, err := evaluateCalcOperand(t.Right, scope)
+		if err != nil {
+			return nil, false, err
+		}
+
+		isSum := t.Op.Type == ast.T_PLUS || t.Op.Type == ast.T_MINUS
+
+		if a, ok := left.(*ast.Number); ok {
+			if b, ok := right.(*ast.Number); ok {
+				if _, compatible := b.ValueIn(a); compatible || !isSum {
+					val, err := Compute(t.Op, a.WithoutSlash(), b.WithoutSlash())
+					return val, false, err
+				}
 			}
-		} else if fl > 1.0 {
-			fl = 1.0
 		}
 
-		nums[idx] = fl
-	}
+		l, r := ast.StringWithPrecision(left, scope.Precision), ast.StringWithPrecision(right, scope.Precision)
 
-	color := ast.NewHSLColor(nums[0], nums[1], nums[2], nil)
-	return color, nil
-}
+		if leftSum && !isSum {
+			l = "(" + l + ")"
+		}
 
-func EvaluateFunctionCall(fc *ast.FunctionCall, scope *Scope) (ast.Value, error) {
-	// this is lame, we should do better of course
-	if fc.Ident.Str == "rgb" {
-		return EvaluateRGBColor(fc.Arguments, scope)
-	}
+		if rightSum && (!isSum || t.Op.Type == ast.T_MINUS) {
+			r = "(" + r + ")"
+		}
 
-	if fc.Ident.Str == "hsl" {
-		return EvaluateHSLColor(fc.Arguments, scope)
+		return ast.NewString(0, l+" "+t.Op.String()+" "+r, nil), isSum, nil
+
+	case *ast.UnaryExpr:
+		val, _, err := evaluateCalcOperand(t.Expr, scope)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if _, ok := val.(*ast.Number); ok {
+			val, err := EvaluateUnaryExpr(ast.NewUnaryExpr(t.Op, val), scope)
+			return val, false, err
+		}
+
+		return ast.NewString(0, t.Op.String()+ast.StringWithPrecision(val, scope.Precision), nil), false, nil
 	}
 
-	// by default we assume that we've encountered a builtin function
-	return fc, nil
+	val, err = EvaluateExpr(expr, scope)
+	return val, false, err
 }
 
 /*
@@ -600,11 +920,9 @@ func EvaluateExpr(expr ast.Expr, scope *Scope) (v ast.Value, err error) {
 	switch t := expr.(type) {
 
 	case *ast.BinaryExpr:
-		// For binary expression that is a CSS slash, we evaluate the expression as a literal string (unquoted)
-		if t.IsCssSlash() {
-			// return string object without quote
-			s := ast.NewString(0, t.Left.String()+"/"+t.Right.String(), nil)
-			return s, nil
+		// `12px/1.5` is kept as it is written, see isSlashSeparated
+		if isSlashSeparated(t) {
+			return evaluateSlash(t), nil
 		}
 		return EvaluateBinaryExpr(t, scope)
 
@@ -642,8 +960,10 @@ func EvaluateExpr(expr ast.Expr, scope *Scope) (v ast.Value, err error) {
 				return nil, fmt.Errorf("%s lookup is out of bounds, idx = %d, len = %d", t.Variable.NormalizedName(), t.FromIdx, l.Len())
 			}
 
+			// the keywords are passed along with the argument list
 			val := &ast.List{
 				Separator: " ",
+				Keywords:  l.Keywords,
 			}
 
 			if t.FromIdx < l.Len() {
@@ -670,9 +990,34 @@ func EvaluateExpr(expr ast.Expr, scope *Scope) (v ast.Value, err error) {
 	case *ast.FunctionCall:
 		return EvaluateFunctionCall(t, scope)
 
+	case *ast.ParentSelector:
+		return selectorValue(scope.LookupSelectors()), nil
+
+	case *ast.Interpolation:
+		val, err := EvaluateExpr(t.Expr, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewString(0, interpolate(val, scope.Precision), nil), nil
+
+	case *ast.LiteralConcat:
+		left, err := EvaluateExpr(t.Left, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := EvaluateExpr(t.Right, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewString(0, interpolate(left, scope.Precision)+interpolate(right, scope.Precision), nil), nil
+
 	case *ast.List:
 		val := &ast.List{
 			Separator: t.Separator,
+			Bracketed: t.Bracketed,
 		}
 
 		for _, expr := range t.Exprs {
@@ -684,7 +1029,20 @@ func EvaluateExpr(expr ast.Expr, scope *Scope) (v ast.Value, err error) {
 			val.Append(evaluated)
 		}
 
+		if t.Keywords != nil {
+			keywords, err := EvaluateMap(t.Keywords, scope)
+			if err != nil {
+				return nil, err
+			}
+
+			val.Keywords = keywords
+		}
+
 		return val, nil
+
+	case *ast.Map:
+		return EvaluateMap(t, scope)
+
 	default:
 		return ast.Value(expr), nil
 
@@ -721,6 +1079,12 @@ func EvaluateBinaryExpr(expr *ast.BinaryExpr, scope *Scope) (ast.Value, error) {
 			lval = varVal.(ast.Expr)
 		}
 
+	case *ast.FunctionCall:
+		lval, err = EvaluateFunctionCall(expr, scope)
+		if err != nil {
+			return nil, err
+		}
+
 	default:
 		lval = ast.Value(expr)
 	}
@@ -746,11 +1110,26 @@ func EvaluateBinaryExpr(expr *ast.BinaryExpr, scope *Scope) (ast.Value, error) {
 			rval = varVal.(ast.Expr)
 		}
 
+	case *ast.FunctionCall:
+		rval, err = EvaluateFunctionCall(expr, scope)
+		if err != nil {
+			return nil, err
+		}
+
 	default:
 		rval = ast.Value(expr)
 	}
 
 	if lval != nil && rval != nil {
+		if expr.Op.Type == ast.T_DIV {
+			_, lnum := lval.(*ast.Number)
+			_, rnum := rval.(*ast.Number)
+
+			if lnum && rnum {
+				warnSlashDivision(expr, scope)
+			}
+		}
+
 		return Compute(expr.Op, lval, rval)
 	}
 	return nil, nil
@@ -778,6 +1157,11 @@ func EvaluateUnaryExpr(expr *ast.UnaryExpr, scope *Scope) (ast.Value, error) {
 		} else {
 			val = varVal.(ast.Expr)
 		}
+	case *ast.FunctionCall:
+		val, err = EvaluateFunctionCall(t, scope)
+		if err != nil {
+			return nil, err
+		}
 	default:
 		val = ast.Value(t)
 	}
@@ -792,8 +1176,33 @@ func EvaluateUnaryExpr(expr *ast.UnaryExpr, scope *Scope) (ast.Value, error) {
 	case ast.T_MINUS:
 		switch n := val.(type) {
 		case *ast.Number:
-			n.Value = -n.Value
+			// the number may be the value of a variable or a default
+			// argument, it must not be changed in place
+			neg := *n.WithoutSlash()
+			neg.Value = -n.Value
+			val = &neg
 		}
 	}
 	return val, nil
 }
+
+// interpolate returns the unquoted text of the value for #{...}
+func interpolate(v ast.Value, precision int) string {
+	switch t := v.(type) {
+	case *ast.String:
+		return t.Value
+	case *ast.List:
+		var strs []string
+		for _, expr := range t.Exprs {
+			strs = append(strs, interpolate(expr, precision))
+		}
+		if t.Bracketed {
+			return "[" + strings.Join(strs, t.Separator) + "]"
+		}
+		return strings.Join(strs, t.Separator)
+	case *ast.Null:
+		return ""
+	}
+
+	return ast.StringWithPrecision(v, precision)
+}
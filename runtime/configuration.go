@@ -0,0 +1,108 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/c9s/c6/ast"
+)
+
+type configuredValue struct {
+	Value ast.Value
+	Used  bool
+}
+
+/*
+Configuration is the set of variables passed to a module with:
+
+	@use "library" with ($primary: blue);
+
+the values replace the ones of the top-level !default declarations of the
+module, also the ones forwarded by it.
+*/
+type Configuration struct {
+	Values map[string]*configuredValue
+}
+
+func NewConfiguration() *Configuration {
+	return &Configuration{
+		Values: map[string]*configuredValue{},
+	}
+}
+
+// EvaluateConfiguration evaluates the `with (...)` map of the @use rule
+func EvaluateConfiguration(with *ast.Map, scope *Scope) (*Configuration, error) {
+	config := NewConfiguration()
+
+	for _, item := range with.Items {
+		variable, ok := item.Key.(*ast.Variable)
+
+		if !ok {
+			return nil, fmt.Errorf("Expected variable.")
+		}
+
+		val, err := EvaluateExpr(item.Value, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		config.Values[variable.NormalizedName()] = &configuredValue{Value: val}
+	}
+
+	return config, nil
+}
+
+// Take returns the configured value of the variable and marks it as used
+func (c *Configuration) Take(name string) (ast.Value, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	v, ok := c.Values[name]
+
+	if !ok {
+		return nil, false
+	}
+
+	v.Used = true
+
+	return v.Value, true
+}
+
+// Forward returns the part of the configuration that goes to the module
+// forwarded by @forward, the names are the ones of the forwarded module
+func (c *Configuration) Forward(fw *Forward) *Configuration {
+	if c == nil {
+		return nil
+	}
+
+	forwarded := NewConfiguration()
+
+	for name, v := range c.Values {
+		if member, ok := fw.member(name); ok {
+			forwarded.Values[member] = v
+		}
+	}
+
+	if len(forwarded.Values) == 0 {
+		return nil
+	}
+
+	return forwarded
+}
+
+// Check returns an error if any of the variables hasn't been declared with
+// !default by the module
+func (c *Configuration) Check() error {
+	if c == nil {
+		return nil
+	}
+
+	for _, v := range c.Values {
+		if !v.Used {
+			return fmt.Errorf("This variable was not declared with !default in the @used module.")
+		}
+	}
+
+	return nil
+}
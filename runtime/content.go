@@ -0,0 +1,42 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/c9s/c6/ast"
+)
+
+// Content is the block passed to a mixin with `@include foo { ... }`.
+// The block is executed wherever the mixin uses @content, but in the
+// lexical scope of the @include, not in the scope of the mixin.
+type Content struct {
+	Block     *ast.DeclBlock
+	Arguments *ast.ArgumentList // @include foo using ($a) { ... }
+	Scope     *Scope
+}
+
+func NewContent(stmt *ast.IncludeStmt, scope *Scope) *Content {
+	if stmt.ContentBlock == nil {
+		return nil
+	}
+
+	return &Content{
+		Block:     stmt.ContentBlock,
+		Arguments: stmt.ContentArguments,
+		Scope:     scope,
+	}
+}
+
+/*
+EvaluateContentExists implements content-exists(), it returns true
+if the current mixin has been included with a content block
+*/
+func EvaluateContentExists(scope *Scope) (ast.Value, error) {
+	content, ok := scope.LookupContent()
+
+	if !ok {
+		return nil, fmt.Errorf("content-exists() may only be called within a mixin.")
+	}
+
+	return ast.NewBoolean(content != nil), nil
+}
@@ -18,6 +18,13 @@ type Runtime struct {
 	DebugPrinter  Printer
 	WarnPrinter   Printer
 	ExecutedPaths map[string]struct{}
+
+	// Modules are the modules loaded by @use, by their file name
+	Modules map[string]*Module
+
+	// Precision is the number of digits after the decimal point of the
+	// numbers converted to strings, e.g. by the interpolation
+	Precision int
 }
 
 func NewRuntime(gp *parser.GlobalParser, debug, warn Printer) *Runtime {
@@ -26,12 +33,28 @@ func NewRuntime(gp *parser.GlobalParser, debug, warn Printer) *Runtime {
 		DebugPrinter:  debug,
 		WarnPrinter:   warn,
 		ExecutedPaths: map[string]struct{}{},
+		Modules:       map[string]*Module{},
+		Precision:     ast.DefaultPrecision,
 	}
 }
 
 func (r *Runtime) ExecuteList(scope *Scope, stmts *ast.StmtList) (*ast.StmtList, error) {
 	out := &ast.StmtList{}
 
+	if scope.Parent == nil && scope.WarnPrinter == nil {
+		scope.WarnPrinter = r.WarnPrinter
+	}
+
+	if scope.Parent == nil {
+		scope.Precision = r.Precision
+	}
+
+	if scope.Parent == nil {
+		if err := checkUseRules(stmts); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, stmt := range stmts.Stmts {
 		ret, err := r.ExecuteSingle(scope, stmt)
 
@@ -40,6 +63,10 @@ func (r *Runtime) ExecuteList(scope *Scope, stmts *ast.StmtList) (*ast.StmtList,
 		}
 
 		out.AppendList(ret)
+
+		if hasReturned(ret) {
+			break
+		}
 	}
 
 	return out, nil
@@ -59,6 +86,8 @@ func (r *Runtime) ExecuteSingle(scope *Scope, stmt ast.Stmt) (*ast.StmtList, err
 		return r.executeForStmt(scope, t)
 	case *ast.WhileStmt:
 		return r.executeWhileStmt(scope, t)
+	case *ast.EachStmt:
+		return r.executeEachStmt(scope, t)
 	case *ast.RuleSet:
 		return r.executeRuleSet(scope, t)
 	case *ast.Property:
@@ -68,10 +97,29 @@ func (r *Runtime) ExecuteSingle(scope *Scope, stmt ast.Stmt) (*ast.StmtList, err
 		return nil, err
 	case *ast.IncludeStmt:
 		return r.executeIncludeStmt(scope, t)
+	case *ast.ContentStmt:
+		return r.executeContentStmt(scope, t)
+	case *ast.ExtendStmt:
+		return r.executeExtendStmt(scope, t)
+	case *ast.AtRootStmt:
+		return r.executeAtRootStmt(scope, t)
+	case *ast.MediaQueryStmt:
+		return r.executeMediaQueryStmt(scope, t)
+	case *ast.AtRuleStmt:
+		return r.executeAtRuleStmt(scope, t)
+	case *ast.Function:
+		err := r.executeFunctionStmt(scope, t)
+		return nil, err
+	case *ast.ReturnStmt:
+		return r.executeReturnStmt(scope, t)
 	case *ast.CssImportStmt:
 		return r.executeCssImportStmt(scope, t)
 	case *ast.ImportStmt:
 		return r.executeImportStmt(scope, t)
+	case *ast.UseStmt:
+		return r.executeUseStmt(scope, t)
+	case *ast.ForwardStmt:
+		return r.executeForwardStmt(scope, t)
 	}
 
 	return nil, fmt.Errorf("Don't know how to execute the statement %v", stmt)
@@ -122,6 +170,11 @@ func (r *Runtime) executeWhileStmt(scope *Scope, stmt *ast.WhileStmt) (*ast.Stmt
 		}
 
 		out.AppendList(l)
+
+		if hasReturned(l) {
+			break
+		}
+
 		count++
 
 		if count == MaxWhileIterations {
@@ -191,12 +244,83 @@ func (r *Runtime) executeForStmt(scope *Scope, stmt *ast.ForStmt) (*ast.StmtList
 		}
 
 		out.AppendList(l)
+
+		if hasReturned(l) {
+			break
+		}
+
 		from = from + step
 	}
 
 	return out, nil
 }
 
+func (r *Runtime) executeEachStmt(scope *Scope, stmt *ast.EachStmt) (*ast.StmtList, error) {
+	v, err := EvaluateExpr(stmt.Expr, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// every iteration binds one tuple of values to the loop variables
+	var tuples [][]ast.Expr
+
+	switch t := v.(type) {
+	case *ast.Map:
+		for _, item := range t.Items {
+			if len(stmt.Variables) == 1 {
+				pair := ast.NewSpaceSepList()
+				pair.Append(item.Key)
+				pair.Append(item.Value)
+				tuples = append(tuples, []ast.Expr{pair})
+			} else {
+				tuples = append(tuples, []ast.Expr{item.Key, item.Value})
+			}
+		}
+
+	case *ast.List:
+		for _, item := range t.Exprs {
+			if l, ok := item.(*ast.List); ok && len(stmt.Variables) > 1 {
+				tuples = append(tuples, l.Exprs)
+			} else {
+				tuples = append(tuples, []ast.Expr{item})
+			}
+		}
+
+	default:
+		// a single value is treated as a list with one item
+		tuples = append(tuples, []ast.Expr{v})
+	}
+
+	out := &ast.StmtList{}
+
+	for _, tuple := range tuples {
+		child := NewScope(scope)
+
+		for i, variable := range stmt.Variables {
+			if i < len(tuple) {
+				child.Insert(variable.NormalizedName(), tuple[i])
+			} else {
+				child.Insert(variable.NormalizedName(), ast.NewNullWithToken(nil))
+			}
+		}
+
+		l, err := r.ExecuteList(child, &stmt.Block.Stmts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out.AppendList(l)
+
+		if hasReturned(l) {
+			break
+		}
+	}
+
+	return out, nil
+}
+
 func (r *Runtime) executeIfStmt(scope *Scope, stmt *ast.IfStmt) (*ast.StmtList, error) {
 	var out *ast.StmtList
 
@@ -261,7 +385,7 @@ func (r *Runtime) executeLogStmt(scope *Scope, stmt *ast.LogStmt) error {
 }
 
 func (r *Runtime) executeMixinStmt(scope *Scope, stmt *ast.MixinStmt) error {
-	scope.InsertMixin(stmt.NormalizedName(), stmt)
+	scope.InsertMixin(stmt.NormalizedName(), NewMixin(scope, stmt))
 
 	return nil
 }
@@ -273,26 +397,118 @@ func (r *Runtime) executeIncludeStmt(scope *Scope, stmt *ast.IncludeStmt) (*ast.
 		return nil, err
 	}
 
-	child := NewScope(scope)
+	// the mixin body only sees the scope it has been declared in
+	child := NewScope(m.Scope)
+	child.IsMixin = true
+	child.Content = NewContent(stmt, scope)
+	child.Selectors = scope.LookupSelectors()
+
+	args, err := parser.ApplyCallArguments(m.Decl.ArgumentList, stmt.ArgumentList)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bindArguments(m.Decl.ArgumentList, args, scope, child); err != nil {
+		return nil, err
+	}
+
+	l, err := r.ExecuteList(child, &m.Decl.Block.Stmts)
+	return l, err
+}
+
+func (r *Runtime) executeContentStmt(scope *Scope, stmt *ast.ContentStmt) (*ast.StmtList, error) {
+	content, ok := scope.LookupContent()
+
+	if !ok {
+		return nil, fmt.Errorf("@content is only allowed within mixin declarations.")
+	}
 
-	args, err := parser.ApplyCallArguments(m.ArgumentList, stmt.ArgumentList)
+	// the mixin has been included without a block
+	if content == nil {
+		return nil, nil
+	}
+
+	proto := content.Arguments
+	if proto == nil {
+		proto = ast.NewArgumentList()
+	}
+
+	callArgs := stmt.Arguments
+	if callArgs == nil {
+		callArgs = &ast.CallArgumentList{}
+	}
+
+	args, err := parser.ApplyCallArguments(proto, callArgs)
 
 	if err != nil {
 		return nil, err
 	}
 
-	for _, v := range args.Args {
-		val, err := EvaluateExpr(v.Value, child)
+	child := NewScope(content.Scope)
+
+	// the block is nested in the rule around @content, not in the rule
+	// where it's written
+	child.Selectors = &ast.ComplexSelectorList{}
+
+	if selectors := scope.LookupSelectors(); selectors != nil {
+		child.Selectors = selectors
+	}
+
+	if err := bindArguments(proto, args, scope, child); err != nil {
+		return nil, err
+	}
+
+	return r.ExecuteList(child, &content.Block.Stmts)
+}
+
+// executeExtendStmt keeps @extend in the tree, it's applied by ExtendTree
+// once all the selectors are expanded
+func (r *Runtime) executeExtendStmt(scope *Scope, stmt *ast.ExtendStmt) (*ast.StmtList, error) {
+	if stmt.SelectorTemplate != nil {
+		selectors, err := evaluateSelectorTemplate(stmt.SelectorTemplate, scope)
 
 		if err != nil {
 			return nil, err
 		}
 
-		child.Insert(v.Name.NormalizedName(), val)
+		stmt = &ast.ExtendStmt{Token: stmt.Token, Selectors: selectors, Optional: stmt.Optional}
 	}
 
-	l, err := r.ExecuteList(child, &m.Block.Stmts)
-	return l, err
+	out := &ast.StmtList{}
+	out.Append(stmt)
+
+	return out, nil
+}
+
+func (r *Runtime) executeFunctionStmt(scope *Scope, stmt *ast.Function) error {
+	scope.InsertFunction(stmt.NormalizedName(), NewFunction(r, scope, stmt))
+
+	return nil
+}
+
+// executeReturnStmt evaluates the returned value, the caller is expected
+// to stop the execution of the current block, see hasReturned
+func (r *Runtime) executeReturnStmt(scope *Scope, stmt *ast.ReturnStmt) (*ast.StmtList, error) {
+	if !scope.InFunction() {
+		return nil, fmt.Errorf("This at-rule is not allowed here.")
+	}
+
+	if stmt.Value == nil {
+		return nil, fmt.Errorf("Expected expression")
+	}
+
+	val, err := EvaluateExpr(stmt.Value, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	val = withoutSlash(val, scope)
+
+	return &ast.StmtList{
+		Stmts: []ast.Stmt{ast.NewReturnStmtWithToken(stmt.Token, val)},
+	}, nil
 }
 
 func (r *Runtime) executeCssImportStmt(_ *Scope, stmt *ast.CssImportStmt) (*ast.StmtList, error) {
@@ -347,24 +563,61 @@ func (r *Runtime) executeImportStmt(scope *Scope, stmt *ast.ImportStmt) (*ast.St
 }
 
 func (r *Runtime) executeAssignStmt(scope *Scope, stmt *ast.AssignStmt) error {
-	varName := stmt.Variable.Name
+	varName := stmt.Variable.NormalizedName()
+	target := scope
+
+	if stmt.Global {
+		target = scope.GetGlobal()
+	}
+
+	if stmt.Default {
+		// the value configured by `@use ... with (...)` takes precedence
+		if val, ok := target.Configuration.Take(varName); ok {
+			target.Insert(varName, val)
+			return nil
+		}
+
+		// !default only assigns variables which are undefined or null
+		if val, err := target.Lookup(varName); err == nil {
+			if _, isNull := val.(*ast.Null); !isNull {
+				return nil
+			}
+		}
+	}
+
 	val, err := EvaluateExpr(stmt.Expr, scope)
 
 	if err != nil {
 		return err
 	}
 
-	if stmt.Global {
-		scope.GetGlobal().Insert(varName, val)
-	} else {
-		scope.Insert(varName, val)
-	}
+	target.Insert(varName, withoutSlash(val, scope))
 
 	return nil
 }
 
 func (r *Runtime) executeRuleSet(scope *Scope, stmt *ast.RuleSet) (*ast.StmtList, error) {
+	selectors := stmt.Selectors
+
+	if stmt.SelectorTemplate != nil {
+		var err error
+		if selectors, err = evaluateSelectorTemplate(stmt.SelectorTemplate, scope); err != nil {
+			return nil, err
+		}
+	}
+
 	child := NewScope(scope)
+	child.Selectors = selectors
+
+	if parent := scope.LookupSelectors(); parent != nil {
+		selectors, err := joinSelectorLists(parent, selectors)
+
+		if err != nil {
+			return nil, err
+		}
+
+		child.Selectors = selectors
+	}
 
 	res, err := r.ExecuteList(child, &stmt.Block.Stmts)
 
@@ -376,25 +629,203 @@ func (r *Runtime) executeRuleSet(scope *Scope, stmt *ast.RuleSet) (*ast.StmtList
 	decl := ast.NewDeclBlock()
 	decl.AppendList(res)
 	rs.Block = decl
-	rs.Selectors = stmt.Selectors
+	rs.Selectors = selectors
 
 	return &ast.StmtList{
 		Stmts: []ast.Stmt{rs},
 	}, nil
 }
 
+// executeAtRootStmt executes the block, the parent selectors are escaped
+// later on by ExpandTree
+func (r *Runtime) executeAtRootStmt(scope *Scope, stmt *ast.AtRootStmt) (*ast.StmtList, error) {
+	child := NewScope(scope)
+	atRootSelectors := stmt.Selectors
+
+	if stmt.SelectorTemplate != nil {
+		var err error
+		if atRootSelectors, err = evaluateSelectorTemplate(stmt.SelectorTemplate, scope); err != nil {
+			return nil, err
+		}
+	}
+
+	if atRootSelectors != nil {
+		selectors, err := resolveAtRootSelectors(scope.LookupSelectors(), atRootSelectors)
+
+		if err != nil {
+			return nil, err
+		}
+
+		child.Selectors = selectors
+	} else if stmt.Excludes("rule") {
+		// the rules inside are not nested in the parent rule anymore
+		child.Selectors = &ast.ComplexSelectorList{}
+	} else if selectors := keyframesParentSelectors(scope, stmt); selectors != nil {
+		// the rules inside are nested in the parent rule of the escaped
+		// @keyframes again
+		child.Selectors = selectors
+		atRootSelectors = selectors
+	}
+
+	res, err := r.ExecuteList(child, &stmt.Block.Stmts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ret := ast.NewAtRootStmtWithToken(stmt.Token)
+	ret.Selectors = atRootSelectors
+	ret.Query = stmt.Query
+	ret.Block = ast.NewDeclBlock()
+	ret.Block.AppendList(res)
+
+	return &ast.StmtList{
+		Stmts: []ast.Stmt{ret},
+	}, nil
+}
+
+// keyframesParentSelectors returns the selectors of the rule the @keyframes
+// excluded by the @at-root is nested in, nil if there is none
+func keyframesParentSelectors(scope *Scope, stmt *ast.AtRootStmt) *ast.ComplexSelectorList {
+	for s := scope; s != nil; s = s.Parent {
+		if s.Keyframes != nil && s.Parent != nil && stmt.Excludes(s.Keyframes.Name) {
+			return s.Parent.LookupSelectors()
+		}
+	}
+
+	return nil
+}
+
+// executeMediaQueryStmt evaluates the query and executes the block, nested
+// @media rules are bubbled up later on by ExpandTree
+func (r *Runtime) executeMediaQueryStmt(scope *Scope, stmt *ast.MediaQueryStmt) (*ast.StmtList, error) {
+	queries, err := EvaluateMediaQueryList(stmt.MediaQueryList, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	child := NewScope(scope)
+
+	res, err := r.ExecuteList(child, &stmt.Block.Stmts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ret := ast.NewMediaQueryStmt()
+	ret.MediaQueryList = queries
+	ret.Block = ast.NewDeclBlock()
+	ret.Block.AppendList(res)
+
+	return &ast.StmtList{
+		Stmts: []ast.Stmt{ret},
+	}, nil
+}
+
+// executeAtRuleStmt evaluates the interpolation in the prelude and executes
+// the block, the rule is bubbled up like @media by ExpandTree
+func (r *Runtime) executeAtRuleStmt(scope *Scope, stmt *ast.AtRuleStmt) (*ast.StmtList, error) {
+	ret := ast.NewAtRuleStmtWithToken(stmt.Token)
+
+	if stmt.Prelude != nil {
+		prelude, err := EvaluateExpr(stmt.Prelude, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Prelude = ast.NewString(0, interpolate(prelude, scope.Precision), nil)
+	}
+
+	if stmt.Block != nil {
+		child := NewScope(scope)
+
+		// the keyframe blocks are not nested in the parent rule
+		if stmt.IsKeyframes() {
+			child.Selectors = &ast.ComplexSelectorList{}
+			child.Keyframes = stmt
+		}
+
+		res, err := r.ExecuteList(child, &stmt.Block.Stmts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Block = ast.NewDeclBlock()
+		ret.Block.AppendList(res)
+	}
+
+	return &ast.StmtList{
+		Stmts: []ast.Stmt{ret},
+	}, nil
+}
+
+/*
+invalidCSSValue returns the part of the value which can't be written to the
+css output, e.g. a map or a number with a compound unit:
+
+	1px 2px*px => 2px*px
+*/
+func invalidCSSValue(val ast.Value) ast.Value {
+	switch t := val.(type) {
+	case *ast.Map, *FunctionValue:
+		return val
+	case *ast.Number:
+		if t.IsCompound() {
+			return val
+		}
+	case *ast.List:
+		for _, expr := range t.Exprs {
+			if item, ok := expr.(ast.Value); ok {
+				if invalid := invalidCSSValue(item); invalid != nil {
+					return invalid
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func (r *Runtime) executeProperty(scope *Scope, stmt *ast.Property) (*ast.StmtList, error) {
 	ret := ast.NewProperty(stmt.Name.Token)
 
+	if stmt.Name.Template != nil {
+		name, err := EvaluateExpr(stmt.Name.Template, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Name = &ast.PropertyName{Name: interpolate(name, scope.Precision), Token: stmt.Name.Token}
+	}
+
 	for _, e := range stmt.Values {
 		val, err := EvaluateExpr(e, scope)
 		if err != nil {
 			return nil, err
 		}
 
+		if invalid := invalidCSSValue(val); invalid != nil {
+			return nil, fmt.Errorf("%s isn't a valid CSS value.", invalid)
+		}
+
 		ret.Values = append(ret.Values, val)
 	}
 
+	// the nested properties are flattened by ExpandTree
+	if stmt.Block != nil {
+		res, err := r.ExecuteList(NewScope(scope), &stmt.Block.Stmts)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ret.Block = ast.NewDeclBlock()
+		ret.Block.AppendList(res)
+	}
+
 	return &ast.StmtList{
 		Stmts: []ast.Stmt{ret},
 	}, nil
@@ -19,9 +19,35 @@ func ExpandTree(stmts *ast.StmtList) ([]*ast.StmtList, error) {
 				return nil, err
 			}
 
-			out = append(out, ret)
+			out = append(out, unwrapAtRoot(ret))
 		case *ast.CssImportStmt:
 			cssImports.Append(t)
+		case *ast.AtRootStmt:
+			ret, err := expandAtRoot(t, nil)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, unwrapAtRoot(ret))
+		case *ast.MediaQueryStmt:
+			ret, err := expandMedia(t, nil)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, unwrapAtRoot(ret))
+		case *ast.AtRuleStmt:
+			ret, err := expandAtRule(t, nil)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, unwrapAtRoot(ret))
+		case *ast.ExtendStmt:
+			return nil, fmt.Errorf("@extend may only be used within style rules.")
 		default:
 			return nil, fmt.Errorf("Tree can only contain rule sets or css imports, but has variable of type %T", stmt)
 		}
@@ -50,42 +76,207 @@ func expandRuleset(rs *ast.RuleSet) (*ast.StmtList, error) {
 	out := &ast.StmtList{}
 	collector := []ast.Stmt{}
 
+	// flush wraps the collected declarations with the parent selectors
+	flush := func() {
+		if len(collector) == 0 {
+			return
+		}
+
+		nrs := ast.NewRuleSet()
+		nrs.Selectors = rs.Selectors
+		bl := ast.NewDeclBlock()
+		bl.AppendList(&ast.StmtList{
+			Stmts: collector,
+		})
+		nrs.Block = bl
+		out.Append(nrs)
+		collector = []ast.Stmt{}
+	}
+
 	for _, stmt := range rs.Block.Stmts.Stmts {
 		switch t := stmt.(type) {
 		case *ast.Property:
+			flattened, err := flattenProperty(t)
+
+			if err != nil {
+				return nil, err
+			}
+
+			collector = append(collector, flattened...)
+		case *ast.ExtendStmt:
 			collector = append(collector, t)
 		case *ast.RuleSet:
-			if len(collector) > 0 {
+			flush()
+
+			resultList, err := joinSelectorLists(rs.Selectors, t.Selectors)
+
+			if err != nil {
+				return nil, err
+			}
+
+			nrs := ast.NewRuleSet()
+			nrs.Selectors = resultList
+			nrs.Block = t.Block
+
+			expanded, err := expandRuleset(nrs)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out.AppendList(expanded)
+		case *ast.AtRootStmt:
+			flush()
+
+			var expanded *ast.StmtList
+			var err error
+
+			if t.Excludes("rule") {
+				expanded, err = expandAtRoot(t, rs.Selectors)
+			} else {
+				// only the at-rules are escaped, the content still belongs to the parent rule
 				nrs := ast.NewRuleSet()
 				nrs.Selectors = rs.Selectors
-				bl := ast.NewDeclBlock()
-				bl.AppendList(&ast.StmtList{
-					Stmts: collector,
-				})
-				nrs.Block = bl
-				out.Append(nrs)
-				collector = []ast.Stmt{}
+				nrs.Block = t.Block
+				expanded, err = expandRuleset(nrs)
+				expanded = escapeAtRules(t, expanded)
+			}
+
+			if err != nil {
+				return nil, err
 			}
 
-			childSel := t.Selectors
-			parentSel := rs.Selectors
-			bl := t.Block
-			resultList := &ast.ComplexSelectorList{}
+			out.AppendList(expanded)
+		case *ast.MediaQueryStmt:
+			flush()
+
+			expanded, err := expandMedia(t, rs.Selectors)
 
-			for _, psel := range *parentSel {
-				for _, csel := range *childSel {
-					resSel, err := ast.JoinSelectors(psel, csel)
-					if err != nil {
-						return nil, err
-					}
+			if err != nil {
+				return nil, err
+			}
 
-					resultList.Append(resSel)
-				}
+			out.AppendList(expanded)
+		case *ast.AtRuleStmt:
+			// the rules without block stay within the style rule
+			if t.Block == nil {
+				collector = append(collector, t)
+				continue
+			}
+
+			flush()
+
+			expanded, err := expandAtRule(t, rs.Selectors)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out.AppendList(expanded)
+		default:
+			return nil, fmt.Errorf("Unexpected node type in the expanded tree: %T", t)
+		}
+	}
+
+	flush()
+
+	return out, nil
+}
+
+/*
+flattenProperty flattens the nested properties into hyphenated declarations:
+
+	margin: 0 { left: 4px; } => margin: 0; margin-left: 4px;
+*/
+func flattenProperty(prop *ast.Property) ([]ast.Stmt, error) {
+	if prop.Block == nil {
+		return []ast.Stmt{prop}, nil
+	}
+
+	out := []ast.Stmt{}
+
+	if len(prop.Values) > 0 {
+		out = append(out, &ast.Property{Name: prop.Name, Values: prop.Values})
+	}
+
+	for _, s := range prop.Block.Stmts.Stmts {
+		child, ok := s.(*ast.Property)
+
+		if !ok {
+			return nil, fmt.Errorf("Only properties are allowed within nested properties.")
+		}
+
+		nested := *child
+		nested.Name = &ast.PropertyName{Name: prop.Name.Name + "-" + child.Name.Name, Token: child.Name.Token}
+
+		flattened, err := flattenProperty(&nested)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, flattened...)
+	}
+
+	return out, nil
+}
+
+/*
+expandAtRoot expands the rules of @at-root without their parent selectors:
+
+	.parent {
+		@at-root .child { ... }
+	}
+
+gives `.child { ... }`. The parent selector is only used when the child
+refers to it with `&`.
+*/
+func expandAtRoot(stmt *ast.AtRootStmt, parent *ast.ComplexSelectorList) (*ast.StmtList, error) {
+	if stmt.Selectors != nil {
+		selectors, err := resolveAtRootSelectors(parent, stmt.Selectors)
+
+		if err != nil {
+			return nil, err
+		}
+
+		nrs := ast.NewRuleSet()
+		nrs.Selectors = selectors
+		nrs.Block = stmt.Block
+
+		expanded, err := expandRuleset(nrs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return escapeAtRules(stmt, expanded), nil
+	}
+
+	expanded, err := expandRootBlock(stmt.Block, parent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return escapeAtRules(stmt, expanded), nil
+}
+
+// expandRootBlock expands a block which is not nested in any style rule
+func expandRootBlock(block *ast.DeclBlock, parent *ast.ComplexSelectorList) (*ast.StmtList, error) {
+	out := &ast.StmtList{}
+
+	for _, s := range block.Stmts.Stmts {
+		switch t := s.(type) {
+		case *ast.RuleSet:
+			selectors, err := resolveAtRootSelectors(parent, t.Selectors)
+
+			if err != nil {
+				return nil, err
 			}
 
 			nrs := ast.NewRuleSet()
-			nrs.Selectors = resultList
-			nrs.Block = bl
+			nrs.Selectors = selectors
+			nrs.Block = t.Block
 
 			expanded, err := expandRuleset(nrs)
 
@@ -94,21 +285,316 @@ func expandRuleset(rs *ast.RuleSet) (*ast.StmtList, error) {
 			}
 
 			out.AppendList(expanded)
+		case *ast.AtRootStmt:
+			expanded, err := expandAtRoot(t, parent)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out.AppendList(expanded)
+		case *ast.MediaQueryStmt:
+			expanded, err := expandMedia(t, nil)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out.AppendList(expanded)
+		case *ast.AtRuleStmt:
+			expanded, err := expandAtRule(t, nil)
+
+			if err != nil {
+				return nil, err
+			}
+
+			out.AppendList(expanded)
+		case *ast.CssImportStmt:
+			out.Append(t)
+		case *ast.Property:
+			return nil, fmt.Errorf("Declarations may only be used within style rules.")
+		case *ast.ExtendStmt:
+			return nil, fmt.Errorf("@extend may only be used within style rules.")
 		default:
 			return nil, fmt.Errorf("Unexpected node type in the expanded tree: %T", t)
 		}
 	}
 
-	if len(collector) > 0 {
+	return out, nil
+}
+
+/*
+expandMedia bubbles the @media rule up to the root, the rules inside are
+wrapped with the parent selectors:
+
+	.a {
+		@media screen {
+			color: red;
+			@media (min-width: 100px) { color: blue; }
+		}
+	}
+
+gives `@media screen { .a { color: red; } }` and
+`@media screen and (min-width: 100px) { .a { color: blue; } }`.
+*/
+func expandMedia(stmt *ast.MediaQueryStmt, parent *ast.ComplexSelectorList) (*ast.StmtList, error) {
+	var content *ast.StmtList
+	var err error
+
+	if parent != nil {
 		nrs := ast.NewRuleSet()
-		nrs.Selectors = rs.Selectors
-		bl := ast.NewDeclBlock()
-		bl.AppendList(&ast.StmtList{
-			Stmts: collector,
-		})
-		nrs.Block = bl
-		out.Append(nrs)
+		nrs.Selectors = parent
+		nrs.Block = stmt.Block
+		content, err = expandRuleset(nrs)
+	} else {
+		content, err = expandRootBlock(stmt.Block, nil)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ast.StmtList{}
+
+	if content == nil {
+		return out, nil
+	}
+
+	var current *ast.MediaQueryStmt
+
+	for _, s := range content.Stmts {
+		switch t := s.(type) {
+		case *ast.MediaQueryStmt:
+			// the nested @media rules have been bubbled up already
+			current = nil
+
+			queries := mergeMediaQueryLists(stmt.MediaQueryList, t.MediaQueryList)
+
+			// the queries could never match together
+			if queries == nil {
+				continue
+			}
+
+			nested := ast.NewMediaQueryStmt()
+			nested.MediaQueryList = queries
+			nested.Block = t.Block
+			out.Append(nested)
+		case *ast.AtRootStmt:
+			// @at-root (without: media)
+			current = nil
+
+			if !t.Excludes("media") {
+				// the rules stay in the @media rule while escaping the outer ones
+				nested := ast.NewMediaQueryStmt()
+				nested.MediaQueryList = stmt.MediaQueryList
+				nested.Block = t.Block
+				t = markAtRoot(t, &ast.StmtList{Stmts: []ast.Stmt{nested}})
+			}
+
+			out.Append(t)
+		default:
+			if current == nil {
+				current = ast.NewMediaQueryStmt()
+				current.MediaQueryList = stmt.MediaQueryList
+				current.Block = ast.NewDeclBlock()
+				out.Append(current)
+			}
+
+			current.Block.Append(t)
+		}
+	}
+
+	return out, nil
+}
+
+/*
+expandAtRule bubbles the at-rule up to the root like expandMedia, the rules
+inside are wrapped with the parent selectors:
+
+	.a {
+		@supports (display: grid) { display: grid; }
+	}
+
+gives `@supports (display: grid) { .a { display: grid; } }`. The
+declarations of the rules at the root are kept in the at-rule itself, e.g.
+`@page { margin: 1in; }`. The keyframe blocks of @keyframes are never
+wrapped with the parent selectors.
+*/
+func expandAtRule(stmt *ast.AtRuleStmt, parent *ast.ComplexSelectorList) (*ast.StmtList, error) {
+	if stmt.Block == nil {
+		return &ast.StmtList{Stmts: []ast.Stmt{stmt}}, nil
+	}
+
+	content := &ast.StmtList{}
+	var err error
+
+	if parent != nil && !stmt.IsKeyframes() {
+		nrs := ast.NewRuleSet()
+		nrs.Selectors = parent
+		nrs.Block = stmt.Block
+		content, err = expandRuleset(nrs)
+	} else if hasDeclarations(stmt.Block) {
+		content = &stmt.Block.Stmts
+	} else {
+		content, err = expandRootBlock(stmt.Block, nil)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ast.StmtList{}
+
+	// empty rules are dropped like the empty style rules
+	if content == nil {
+		return out, nil
+	}
+
+	var current *ast.AtRuleStmt
+
+	for _, s := range content.Stmts {
+		if t, ok := s.(*ast.AtRootStmt); ok {
+			// @at-root (without: supports)
+			current = nil
+
+			if !t.Excludes(stmt.Name) {
+				// the rules stay in the at-rule while escaping the outer ones
+				nested := ast.NewAtRuleStmtWithToken(stmt.Token)
+				nested.Prelude = stmt.Prelude
+				nested.Block = t.Block
+				t = markAtRoot(t, &ast.StmtList{Stmts: []ast.Stmt{nested}})
+			}
+
+			out.Append(t)
+			continue
+		}
+
+		if current == nil {
+			current = ast.NewAtRuleStmtWithToken(stmt.Token)
+			current.Prelude = stmt.Prelude
+			current.Block = ast.NewDeclBlock()
+			out.Append(current)
+		}
+
+		current.Block.Append(s)
 	}
 
 	return out, nil
 }
+
+// hasDeclarations reports if the block contains declarations directly
+func hasDeclarations(block *ast.DeclBlock) bool {
+	for _, s := range block.Stmts.Stmts {
+		if _, ok := s.(*ast.Property); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+escapeAtRules marks the expanded rules of @at-root (without: media) and the
+other queries excluding at-rules, so that expandMedia and expandAtRule move
+them out of the excluded rules. The marks are removed by unwrapAtRoot once
+the rules reach the root.
+*/
+func escapeAtRules(stmt *ast.AtRootStmt, expanded *ast.StmtList) *ast.StmtList {
+	if expanded == nil || !excludesAtRules(stmt) {
+		return expanded
+	}
+
+	return &ast.StmtList{
+		Stmts: []ast.Stmt{markAtRoot(stmt, expanded)},
+	}
+}
+
+// excludesAtRules reports if the query of the @at-root excludes other rules
+// than the style rules
+func excludesAtRules(stmt *ast.AtRootStmt) bool {
+	if stmt.Query == nil {
+		return false
+	}
+
+	if stmt.Query.With {
+		return true
+	}
+
+	for _, name := range stmt.Query.Names {
+		if name != "rule" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markAtRoot wraps the expanded rules with the mark of the @at-root
+func markAtRoot(stmt *ast.AtRootStmt, expanded *ast.StmtList) *ast.AtRootStmt {
+	ret := ast.NewAtRootStmtWithToken(stmt.Token)
+	ret.Query = stmt.Query
+	ret.Block = ast.NewDeclBlock()
+	ret.Block.AppendList(expanded)
+	return ret
+}
+
+func unwrapAtRoot(list *ast.StmtList) *ast.StmtList {
+	if list == nil {
+		return nil
+	}
+
+	out := &ast.StmtList{}
+
+	for _, s := range list.Stmts {
+		if t, ok := s.(*ast.AtRootStmt); ok {
+			out.AppendList(unwrapAtRoot(&t.Block.Stmts))
+			continue
+		}
+
+		out.Append(s)
+	}
+
+	return out
+}
+
+// joinSelectorLists joins every child selector with every parent one
+func joinSelectorLists(parent, child *ast.ComplexSelectorList) (*ast.ComplexSelectorList, error) {
+	resultList := &ast.ComplexSelectorList{}
+
+	for _, psel := range *parent {
+		for _, csel := range *child {
+			resSel, err := ast.JoinSelectors(psel, csel)
+			if err != nil {
+				return nil, err
+			}
+
+			resultList.Append(resSel)
+		}
+	}
+
+	return resultList, nil
+}
+
+func resolveAtRootSelectors(parent, child *ast.ComplexSelectorList) (*ast.ComplexSelectorList, error) {
+	if parent == nil || !hasParentSelector(child) {
+		return child, nil
+	}
+
+	return joinSelectorLists(parent, child)
+}
+
+func hasParentSelector(list *ast.ComplexSelectorList) bool {
+	for _, sel := range *list {
+		for _, item := range sel.ComplexSelectorItems {
+			if item.CompoundSelector == nil || len(*item.CompoundSelector) == 0 {
+				continue
+			}
+
+			if _, ok := (*item.CompoundSelector)[0].(*ast.ParentSelector); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
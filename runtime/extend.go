@@ -0,0 +1,924 @@
+package runtime
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/c9s/c6/ast"
+)
+
+/*
+extension is a single target of an @extend rule:
+
+	.error { ... }
+
+	.serious-error {
+		@extend .error;
+	}
+
+here `.serious-error` is the extender and `.error` is the target.
+*/
+type extension struct {
+	Extender *ast.ComplexSelector
+	Target   ast.CompoundSelector
+	Optional bool
+
+	// the media query the @extend has been found in, if any
+	Media string
+
+	// set once the target has been found in any rule
+	Matched bool
+}
+
+type Extender struct {
+	Extensions []*extension
+}
+
+/*
+ExtendTree applies all the @extend rules of the expanded tree.
+
+The selectors of the rulesets are already resolved at this point, so the
+extenders could be woven into every rule which contains the target. Placeholder
+selectors are removed from the output, the rules that consist of placeholders
+only are dropped.
+*/
+func ExtendTree(tree []*ast.StmtList) ([]*ast.StmtList, error) {
+	e := &Extender{}
+
+	for _, list := range tree {
+		if err := e.collect(list, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	out := []*ast.StmtList{}
+
+	for _, list := range tree {
+		extended, err := e.apply(list, "")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(extended.Stmts) > 0 {
+			out = append(out, extended)
+		}
+	}
+
+	for _, ext := range e.Extensions {
+		if !ext.Matched && !ext.Optional {
+			return nil, fmt.Errorf("The target selector was not found.\nUse \"@extend %s !optional\" to avoid this error.", ext.Target)
+		}
+	}
+
+	return out, nil
+}
+
+// collect finds @extend rules and removes them from the rulesets
+func (e *Extender) collect(list *ast.StmtList, media string) error {
+	if list == nil {
+		return nil
+	}
+
+	for _, stmt := range list.Stmts {
+		if m, ok := stmt.(*ast.MediaQueryStmt); ok {
+			if err := e.collect(&m.Block.Stmts, m.String()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if a, ok := stmt.(*ast.AtRuleStmt); ok && a.Block != nil {
+			if err := e.collect(&a.Block.Stmts, media); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rs, ok := stmt.(*ast.RuleSet)
+
+		if !ok {
+			continue
+		}
+
+		kept := []ast.Stmt{}
+
+		for _, s := range rs.Block.Stmts.Stmts {
+			ext, ok := s.(*ast.ExtendStmt)
+
+			if !ok {
+				kept = append(kept, s)
+				continue
+			}
+
+			if err := e.add(rs.Selectors, ext, media); err != nil {
+				return err
+			}
+		}
+
+		rs.Block.Stmts.Stmts = kept
+	}
+
+	return nil
+}
+
+func (e *Extender) add(extenders *ast.ComplexSelectorList, stmt *ast.ExtendStmt, media string) error {
+	for _, target := range *stmt.Selectors {
+		items := target.ComplexSelectorItems
+
+		if len(items) != 1 || items[0].Combinator != nil || items[0].CompoundSelector == nil {
+			return fmt.Errorf("complex selectors may not be extended.")
+		}
+
+		if compound := *items[0].CompoundSelector; len(compound) > 1 {
+			simples := []string{}
+
+			for _, sel := range compound {
+				simples = append(simples, sel.String())
+			}
+
+			return fmt.Errorf("compound selectors may no longer be extended.\nConsider `@extend %s` instead.\nSee https://sass-lang.com/d/extend-compound for details.", strings.Join(simples, ", "))
+		}
+
+		for _, extender := range *extenders {
+			e.Extensions = append(e.Extensions, &extension{
+				Extender: extender,
+				Target:   *items[0].CompoundSelector,
+				Optional: stmt.Optional,
+				Media:    media,
+			})
+		}
+	}
+
+	return nil
+}
+
+// apply extends the selectors of every ruleset in the list
+func (e *Extender) apply(list *ast.StmtList, media string) (*ast.StmtList, error) {
+	out := &ast.StmtList{}
+
+	if list == nil {
+		return out, nil
+	}
+
+	for _, stmt := range list.Stmts {
+		if m, ok := stmt.(*ast.MediaQueryStmt); ok {
+			extended, err := e.apply(&m.Block.Stmts, m.String())
+
+			if err != nil {
+				return nil, err
+			}
+
+			// all the rules inside were placeholders
+			if len(extended.Stmts) == 0 {
+				continue
+			}
+
+			nm := ast.NewMediaQueryStmt()
+			nm.MediaQueryList = m.MediaQueryList
+			nm.Block = ast.NewDeclBlock()
+			nm.Block.AppendList(extended)
+			out.Append(nm)
+			continue
+		}
+
+		if a, ok := stmt.(*ast.AtRuleStmt); ok && a.Block != nil {
+			extended, err := e.apply(&a.Block.Stmts, media)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if len(extended.Stmts) == 0 {
+				continue
+			}
+
+			na := ast.NewAtRuleStmtWithToken(a.Token)
+			na.Prelude = a.Prelude
+			na.Block = ast.NewDeclBlock()
+			na.Block.AppendList(extended)
+			out.Append(na)
+			continue
+		}
+
+		rs, ok := stmt.(*ast.RuleSet)
+
+		if !ok {
+			out.Append(stmt)
+			continue
+		}
+
+		// the rule could contain @extend only
+		if len(rs.Block.Stmts.Stmts) == 0 {
+			continue
+		}
+
+		extended, err := e.extendSelectorList(rs.Selectors, media)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// the placeholders are never emitted
+		selectors := &ast.ComplexSelectorList{}
+
+		for _, sel := range *extended {
+			if !hasPlaceholder(sel) {
+				selectors.Append(sel)
+			}
+		}
+
+		if len(*selectors) == 0 {
+			continue
+		}
+
+		nrs := ast.NewRuleSet()
+		nrs.Selectors = selectors
+		nrs.Block = rs.Block
+		out.Append(nrs)
+	}
+
+	return out, nil
+}
+
+/*
+extendSelectorList returns the selector list with all the extenders added
+right after the selectors they've been extending. The extended selectors are
+extended again, so that chained @extend rules work as well.
+*/
+func (e *Extender) extendSelectorList(list *ast.ComplexSelectorList, media string) (*ast.ComplexSelectorList, error) {
+	out := &ast.ComplexSelectorList{}
+	seen := map[string]struct{}{}
+
+	// the original selectors are never trimmed, the generated ones keep
+	// the highest specificity of the extenders they come from
+	sources := map[*ast.ComplexSelector]int{}
+
+	for _, sel := range *list {
+		if _, ok := seen[sel.String()]; ok {
+			continue
+		}
+
+		sel, err := e.extendPseudoSelectors(sel, media)
+
+		if err != nil {
+			return nil, err
+		}
+
+		seen[sel.String()] = struct{}{}
+		queue := []*ast.ComplexSelector{sel}
+
+		for idx := 0; idx < len(queue); idx++ {
+			for _, ext := range e.Extensions {
+				extended, matched := extendComplexSelector(queue[idx], ext)
+
+				if !matched {
+					continue
+				}
+
+				if ext.Media != "" && ext.Media != media {
+					return nil, fmt.Errorf("You may not @extend selectors across media queries.")
+				}
+
+				ext.Matched = true
+
+				for _, n := range extended {
+					if _, ok := seen[n.String()]; ok {
+						continue
+					}
+
+					seen[n.String()] = struct{}{}
+					queue = append(queue, n)
+					sources[n] = max(sources[queue[idx]], specificity(ext.Extender))
+				}
+			}
+		}
+
+		for _, sel := range queue {
+			out.Append(sel)
+		}
+	}
+
+	return trimSelectors(out, sources), nil
+}
+
+// selectorPseudoClasses are the pseudo classes which take a selector list
+var selectorPseudoClasses = []string{"not", "is", "where", "matches", "any", "has"}
+
+/*
+extendPseudoSelectors extends the selector lists inside of the pseudo
+selectors like :not() and :is():
+
+	:not(.a) { }
+	.b { @extend .a; }
+
+gives `:not(.a):not(.b)`, the other pseudo classes list the extenders as
+arguments, e.g. `:is(.a, .b)`.
+*/
+func (e *Extender) extendPseudoSelectors(sel *ast.ComplexSelector, media string) (*ast.ComplexSelector, error) {
+	out := &ast.ComplexSelector{}
+	changed := false
+
+	for _, item := range sel.ComplexSelectorItems {
+		if item.CompoundSelector == nil {
+			out.ComplexSelectorItems = append(out.ComplexSelectorItems, item)
+			continue
+		}
+
+		compound := ast.CompoundSelector{}
+
+		for _, s := range *item.CompoundSelector {
+			pseudo, ok := s.(*ast.FunctionalPseudoSelector)
+
+			if !ok || !slices.Contains(selectorPseudoClasses, strings.ToLower(pseudo.PseudoClass)) {
+				compound = append(compound, s)
+				continue
+			}
+
+			args, ok := parseSelectorList(pseudo.C)
+
+			if !ok || args == nil {
+				compound = append(compound, s)
+				continue
+			}
+
+			extended, err := e.extendSelectorList(args, media)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if len(*extended) == len(*args) {
+				compound = append(compound, s)
+				continue
+			}
+
+			changed = true
+
+			if strings.EqualFold(pseudo.PseudoClass, "not") {
+				// the original arguments are kept as they were
+				compound = append(compound, s)
+
+				for _, arg := range *extended {
+					if slices.ContainsFunc(*args, func(a *ast.ComplexSelector) bool { return a.String() == arg.String() }) {
+						continue
+					}
+
+					compound = append(compound, &ast.FunctionalPseudoSelector{
+						PseudoClass: pseudo.PseudoClass,
+						C:           arg.String(),
+						Token:       pseudo.Token,
+					})
+				}
+
+				continue
+			}
+
+			compound = append(compound, &ast.FunctionalPseudoSelector{
+				PseudoClass: pseudo.PseudoClass,
+				C:           extended.String(),
+				Token:       pseudo.Token,
+			})
+		}
+
+		out.ComplexSelectorItems = append(out.ComplexSelectorItems, &ast.ComplexSelectorItem{
+			Combinator:       item.Combinator,
+			CompoundSelector: &compound,
+		})
+	}
+
+	if !changed {
+		return sel, nil
+	}
+
+	return out, nil
+}
+
+/*
+trimSelectors removes the generated selectors which are matched by another
+selector of the list with at least the specificity of their extenders:
+
+	.f { @extend .g; @extend .h; }
+	.g.h { }
+
+gives `.g.h, .f` instead of `.g.h, .h.f, .g.f, .f`
+*/
+func trimSelectors(list *ast.ComplexSelectorList, sources map[*ast.ComplexSelector]int) *ast.ComplexSelectorList {
+	selectors := *list
+	kept := []*ast.ComplexSelector{}
+
+	for idx := len(selectors) - 1; idx >= 0; idx-- {
+		sel := selectors[idx]
+		source, generated := sources[sel]
+
+		subsumed := func(other *ast.ComplexSelector) bool {
+			return specificity(other) >= source && complexIsSuperselector(other, sel)
+		}
+
+		if generated && (slices.ContainsFunc(kept, subsumed) || slices.ContainsFunc(selectors[:idx], subsumed)) {
+			continue
+		}
+
+		kept = append(kept, sel)
+	}
+
+	slices.Reverse(kept)
+
+	out := ast.ComplexSelectorList(kept)
+	return &out
+}
+
+/*
+specificity returns the specificity of the selector, the ids count for 100,
+the classes, attributes and pseudo classes for 10 and the types and pseudo
+elements for 1:
+
+	#a .b span => 111
+*/
+func specificity(sel *ast.ComplexSelector) int {
+	total := 0
+
+	for _, item := range sel.ComplexSelectorItems {
+		if item.CompoundSelector == nil {
+			continue
+		}
+
+		for _, s := range *item.CompoundSelector {
+			switch t := s.(type) {
+			case *ast.IdSelector:
+				total += 100
+			case *ast.TypeSelector:
+				total += 1
+			case *ast.UniversalSelector:
+			case *ast.PseudoSelector:
+				if t.IsElement() {
+					total += 1
+				} else {
+					total += 10
+				}
+			default:
+				total += 10
+			}
+		}
+	}
+
+	return total
+}
+
+/*
+extendComplexSelector replaces the target of the extension with the extender
+in every compound selector that contains the target.
+
+	.a .b { }
+	.c .d { @extend .b; }
+
+gives `.a .c .d` and `.c .a .d`
+*/
+func extendComplexSelector(sel *ast.ComplexSelector, ext *extension) ([]*ast.ComplexSelector, bool) {
+	out := []*ast.ComplexSelector{}
+	matched := false
+
+	extItems := ext.Extender.ComplexSelectorItems
+	extLast := extItems[len(extItems)-1]
+
+	for idx, item := range sel.ComplexSelectorItems {
+		if item.CompoundSelector == nil {
+			continue
+		}
+
+		rest, ok := subtractCompound(*item.CompoundSelector, ext.Target)
+
+		if !ok {
+			continue
+		}
+
+		matched = true
+
+		// `.a.b { @extend .a; }` would only give a selector that is
+		// already matched by the original one
+		if extLast.CompoundSelector == nil || len(extItems) == 1 && containsCompound(*extLast.CompoundSelector, ext.Target) {
+			continue
+		}
+
+		unified := unifyCompound(*extLast.CompoundSelector, rest)
+
+		if unified == nil {
+			continue
+		}
+
+		target := &ast.ComplexSelectorItem{CompoundSelector: unified}
+		suffix := sel.ComplexSelectorItems[idx+1:]
+
+		for _, woven := range weave(sel.ComplexSelectorItems[:idx+1], extItems, target) {
+			items := append(woven, suffix...)
+			out = append(out, &ast.ComplexSelector{ComplexSelectorItems: items})
+		}
+	}
+
+	return out, matched
+}
+
+/*
+weave merges the parents of the selector and the parents of the extender.
+Both lists include the compound selector that is being extended as the last
+item, it's replaced with the target.
+
+The items joined with child or sibling combinators are kept together, while
+the descendant parents are interleaved.
+*/
+func weave(parents, extParents []*ast.ComplexSelectorItem, target *ast.ComplexSelectorItem) [][]*ast.ComplexSelectorItem {
+	leadA, groupA := splitTrailingGroup(parents)
+	leadB, groupB := splitTrailingGroup(extParents)
+
+	var groups [][]*ast.ComplexSelectorItem
+
+	switch {
+	case len(groupA) > 1 && len(groupB) > 1:
+		groups = mergeGroups(groupA, groupB)
+
+		if groups == nil {
+			return nil
+		}
+	case len(groupB) > 1:
+		groups = append(groups, groupB)
+	default:
+		groups = append(groups, groupA)
+	}
+
+	var leads [][]*ast.ComplexSelectorItem
+
+	switch {
+	case len(leadA) == 0:
+		leads = append(leads, leadB)
+	case len(leadB) == 0:
+		leads = append(leads, leadA)
+	default:
+		leads = append(leads, joinItems(leadA, leadB), joinItems(leadB, leadA))
+	}
+
+	out := [][]*ast.ComplexSelectorItem{}
+
+	for _, group := range groups {
+		// the last item of the group is replaced with the target, but the
+		// combinator stays the same
+		tail := append([]*ast.ComplexSelectorItem{}, group[:len(group)-1]...)
+		tail = append(tail, &ast.ComplexSelectorItem{
+			Combinator:       group[len(group)-1].Combinator,
+			CompoundSelector: target.CompoundSelector,
+		})
+
+		for _, lead := range leads {
+			out = append(out, joinItems(lead, tail))
+		}
+	}
+
+	return out
+}
+
+/*
+splitTrailingGroup splits the items into the descendant parents and the
+trailing group joined with non-descendant combinators, e.g. `.a .b > .c`
+gives `.a` and `.b > .c`.
+*/
+func splitTrailingGroup(items []*ast.ComplexSelectorItem) ([]*ast.ComplexSelectorItem, []*ast.ComplexSelectorItem) {
+	idx := len(items) - 1
+
+	for idx > 0 && !isDescendantCombinator(items[idx].Combinator) {
+		idx--
+	}
+
+	return items[:idx], items[idx:]
+}
+
+// groupComponent is a compound selector of a trailing group followed by
+// its combinator, e.g. `.a >` in `.a > .b`
+type groupComponent struct {
+	Compound   *ast.CompoundSelector
+	Combinator ast.Combinator
+}
+
+func (c groupComponent) item() *ast.ComplexSelectorItem {
+	return &ast.ComplexSelectorItem{CompoundSelector: c.Compound}
+}
+
+/*
+mergeGroups merges the trailing groups of the selector and the extender, the
+alternatives of the merged group are returned, nil if they can't be merged:
+
+	.a > .b and .c > .d => .a.c > .b
+	.l > .m and .n + .o => .l > .n + .m
+	.a ~ .b and .c ~ .d => .a ~ .c ~ .b, .c ~ .a ~ .b, .a.c ~ .b
+
+The last items are not merged since they are replaced with the target
+anyway, the merged group keeps the last item of the selector.
+*/
+func mergeGroups(a, b []*ast.ComplexSelectorItem) [][]*ast.ComplexSelectorItem {
+	choices, ok := mergeTrailingComponents(groupComponents(a), groupComponents(b))
+
+	if !ok {
+		return nil
+	}
+
+	alternatives := [][]groupComponent{{}}
+
+	for _, choice := range choices {
+		next := [][]groupComponent{}
+
+		for _, prefix := range alternatives {
+			for _, option := range choice {
+				next = append(next, append(append([]groupComponent{}, prefix...), option...))
+			}
+		}
+
+		alternatives = next
+	}
+
+	out := [][]*ast.ComplexSelectorItem{}
+
+	for _, components := range alternatives {
+		var group []*ast.ComplexSelectorItem
+		var comb ast.Combinator
+
+		for _, c := range components {
+			group = append(group, &ast.ComplexSelectorItem{Combinator: comb, CompoundSelector: c.Compound})
+			comb = c.Combinator
+		}
+
+		group = append(group, &ast.ComplexSelectorItem{Combinator: comb, CompoundSelector: a[len(a)-1].CompoundSelector})
+		out = append(out, group)
+	}
+
+	return out
+}
+
+// groupComponents returns the components of the group without its last item
+func groupComponents(group []*ast.ComplexSelectorItem) []groupComponent {
+	out := []groupComponent{}
+
+	for idx := 0; idx < len(group)-1; idx++ {
+		out = append(out, groupComponent{group[idx].CompoundSelector, group[idx+1].Combinator})
+	}
+
+	return out
+}
+
+/*
+mergeTrailingComponents merges the components from the last ones, like the
+final combinators are merged by sass. The choices are returned in order,
+each choice lists the alternative components at its position.
+*/
+func mergeTrailingComponents(a, b []groupComponent) ([][][]groupComponent, bool) {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return nil, true
+	case len(a) == 0:
+		return [][][]groupComponent{{b}}, true
+	case len(b) == 0:
+		return [][][]groupComponent{{a}}, true
+	}
+
+	c1, c2 := a[len(a)-1], b[len(b)-1]
+	restA, restB := a[:len(a)-1], b[:len(b)-1]
+	comb1, comb2 := c1.Combinator.String(), c2.Combinator.String()
+
+	var choice [][]groupComponent
+
+	switch {
+	case comb1 == " ~ " && comb2 == " ~ ":
+		switch {
+		case compoundIsSuperselector(c1.item(), c2.item()):
+			choice = [][]groupComponent{{c2}}
+		case compoundIsSuperselector(c2.item(), c1.item()):
+			choice = [][]groupComponent{{c1}}
+		default:
+			choice = [][]groupComponent{{c1, c2}, {c2, c1}}
+
+			if unified := unifyCompound(*c2.Compound, *c1.Compound); unified != nil {
+				choice = append(choice, []groupComponent{{unified, c1.Combinator}})
+			}
+		}
+
+	case (comb1 == " ~ " && comb2 == " + ") || (comb1 == " + " && comb2 == " ~ "):
+		following, next := c1, c2
+
+		if comb1 == " + " {
+			following, next = c2, c1
+		}
+
+		if compoundIsSuperselector(following.item(), next.item()) {
+			choice = [][]groupComponent{{next}}
+		} else {
+			choice = [][]groupComponent{{following, next}}
+
+			if unified := unifyCompound(*c2.Compound, *c1.Compound); unified != nil {
+				choice = append(choice, []groupComponent{{unified, next.Combinator}})
+			}
+		}
+
+	// the siblings are inside of the child, the child is merged further
+	case comb1 == " > " && (comb2 == " + " || comb2 == " ~ "):
+		choice = [][]groupComponent{{c2}}
+		restA = a
+
+	case comb2 == " > " && (comb1 == " + " || comb1 == " ~ "):
+		choice = [][]groupComponent{{c1}}
+		restB = b
+
+	case comb1 == comb2:
+		unified := unifyCompound(*c2.Compound, *c1.Compound)
+
+		if unified == nil {
+			return nil, false
+		}
+
+		choice = [][]groupComponent{{{unified, c1.Combinator}}}
+
+	default:
+		return nil, false
+	}
+
+	choices, ok := mergeTrailingComponents(restA, restB)
+
+	if !ok {
+		return nil, false
+	}
+
+	return append(choices, choice), true
+}
+
+// joinItems concatenates two item lists with a descendant combinator
+func joinItems(a, b []*ast.ComplexSelectorItem) []*ast.ComplexSelectorItem {
+	out := []*ast.ComplexSelectorItem{}
+
+	for idx, item := range append(append([]*ast.ComplexSelectorItem{}, a...), b...) {
+		comb := item.Combinator
+
+		if idx == 0 && isDescendantCombinator(comb) {
+			comb = nil
+		} else if idx > 0 && comb == nil {
+			comb = ast.NewDescendantCombinator()
+		}
+
+		out = append(out, &ast.ComplexSelectorItem{
+			Combinator:       comb,
+			CompoundSelector: item.CompoundSelector,
+		})
+	}
+
+	return out
+}
+
+func isDescendantCombinator(comb ast.Combinator) bool {
+	switch comb.(type) {
+	case nil, *ast.DescendantCombinator, ast.DescendantCombinator:
+		return true
+	}
+
+	return false
+}
+
+// subtractCompound removes the target selectors from the compound selector,
+// the second value is false if the compound does not contain the target
+func subtractCompound(compound, target ast.CompoundSelector) (ast.CompoundSelector, bool) {
+	if !containsCompound(compound, target) {
+		return nil, false
+	}
+
+	rest := ast.CompoundSelector{}
+
+	for _, sel := range compound {
+		if !compoundContains(target, sel) {
+			rest = append(rest, sel)
+		}
+	}
+
+	return rest, true
+}
+
+func containsCompound(compound, target ast.CompoundSelector) bool {
+	for _, sel := range target {
+		if !compoundContains(compound, sel) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func compoundContains(compound ast.CompoundSelector, sel ast.Selector) bool {
+	for _, s := range compound {
+		if s.String() == sel.String() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unifyCompound returns a compound selector that matches both a and b,
+// nil is returned if there is no such selector, e.g. for `a` and `span`
+func unifyCompound(a, b ast.CompoundSelector) *ast.CompoundSelector {
+	result := append(ast.CompoundSelector{}, b...)
+
+	for _, sel := range a {
+		result = unifySimpleSelector(sel, result)
+
+		if result == nil {
+			return nil
+		}
+	}
+
+	return &result
+}
+
+func unifySimpleSelector(sel ast.Selector, compound ast.CompoundSelector) ast.CompoundSelector {
+	switch t := sel.(type) {
+	case *ast.UniversalSelector:
+		if len(compound) > 0 {
+			return compound
+		}
+
+		return ast.CompoundSelector{t}
+
+	case *ast.TypeSelector:
+		if len(compound) > 0 {
+			switch first := compound[0].(type) {
+			case *ast.UniversalSelector:
+				return append(ast.CompoundSelector{t}, compound[1:]...)
+			case *ast.TypeSelector:
+				if first.Type != t.Type {
+					return nil
+				}
+
+				return compound
+			}
+		}
+
+		return append(ast.CompoundSelector{t}, compound...)
+
+	case *ast.IdSelector:
+		for _, s := range compound {
+			if id, ok := s.(*ast.IdSelector); ok && id.Id != t.Id {
+				return nil
+			}
+		}
+
+	case *ast.PseudoSelector:
+		if t.IsElement() {
+			for _, s := range compound {
+				if p, ok := s.(*ast.PseudoSelector); ok && p.IsElement() && p.PseudoClass != t.PseudoClass {
+					return nil
+				}
+			}
+		}
+	}
+
+	if compoundContains(compound, sel) {
+		return compound
+	}
+
+	// pseudo selectors always go last
+	out := ast.CompoundSelector{}
+	added := false
+
+	for _, s := range compound {
+		if !added && isPseudoSelector(s) {
+			out = append(out, sel)
+			added = true
+		}
+
+		out = append(out, s)
+	}
+
+	if !added {
+		out = append(out, sel)
+	}
+
+	return out
+}
+
+func isPseudoSelector(sel ast.Selector) bool {
+	switch sel.(type) {
+	case *ast.PseudoSelector, *ast.FunctionalPseudoSelector:
+		return true
+	}
+
+	return false
+}
+
+func hasPlaceholder(sel *ast.ComplexSelector) bool {
+	for _, item := range sel.ComplexSelectorItems {
+		if item.CompoundSelector == nil {
+			continue
+		}
+
+		for _, s := range *item.CompoundSelector {
+			if _, ok := s.(*ast.PlaceholderSelector); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/c9s/c6/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifyCompound(t *testing.T) {
+	var data = []struct {
+		a        ast.CompoundSelector
+		b        ast.CompoundSelector
+		expected string
+	}{
+		{ast.CompoundSelector{ast.NewClassSelector(".bar")}, ast.CompoundSelector{ast.NewClassSelector(".baz")}, ".baz.bar"},
+		{ast.CompoundSelector{ast.NewTypeSelector("a")}, ast.CompoundSelector{ast.NewClassSelector(".baz")}, "a.baz"},
+		{ast.CompoundSelector{ast.NewTypeSelector("a")}, ast.CompoundSelector{ast.NewUniversalSelector(), ast.NewClassSelector(".baz")}, "a.baz"},
+		{ast.CompoundSelector{ast.NewUniversalSelector()}, ast.CompoundSelector{ast.NewClassSelector(".baz")}, ".baz"},
+		{ast.CompoundSelector{ast.NewClassSelector(".bar")}, ast.CompoundSelector{}, ".bar"},
+		{ast.CompoundSelector{ast.NewIdSelector("#a")}, ast.CompoundSelector{ast.NewIdSelector("#a")}, "#a"},
+	}
+
+	for _, d := range data {
+		res := unifyCompound(d.a, d.b)
+
+		if assert.NotNil(t, res) {
+			assert.Equal(t, d.expected, res.String())
+		}
+	}
+}
+
+func TestUnifyCompoundConflict(t *testing.T) {
+	assert.Nil(t, unifyCompound(
+		ast.CompoundSelector{ast.NewTypeSelector("a")},
+		ast.CompoundSelector{ast.NewTypeSelector("span")},
+	))
+
+	assert.Nil(t, unifyCompound(
+		ast.CompoundSelector{ast.NewIdSelector("#a")},
+		ast.CompoundSelector{ast.NewIdSelector("#b")},
+	))
+}
+
+func TestSpecificity(t *testing.T) {
+	sel := &ast.ComplexSelector{}
+	sel.AppendCompoundSelector(nil, &ast.CompoundSelector{ast.NewIdSelector("#a")})
+	sel.AppendCompoundSelector(ast.NewDescendantCombinator(), &ast.CompoundSelector{ast.NewClassSelector(".b")})
+	sel.AppendCompoundSelector(ast.NewChildCombinator(), &ast.CompoundSelector{ast.NewTypeSelector("span")})
+
+	assert.Equal(t, 111, specificity(sel))
+}
@@ -0,0 +1,107 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/c9s/c6/ast"
+	"github.com/c9s/c6/parser"
+)
+
+// Function is a user defined @function together with the scope
+// it has been declared in. The body is always executed in a child
+// of that scope, callers can only pass values through the arguments.
+//
+// Functions of the builtin modules have no body nor scope, Builtin
+// is called instead.
+type Function struct {
+	Decl    *ast.Function
+	Scope   *Scope
+	Runtime *Runtime
+	Builtin BuiltinFunc
+}
+
+func NewFunction(r *Runtime, scope *Scope, decl *ast.Function) *Function {
+	return &Function{
+		Decl:    decl,
+		Scope:   scope,
+		Runtime: r,
+	}
+}
+
+func (fn *Function) Call(callArgs *ast.CallArgumentList, caller *Scope) (ast.Value, error) {
+	args, err := parser.ApplyCallArguments(fn.Decl.ArgumentList, callArgs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fn.Builtin != nil {
+		return fn.callBuiltin(args, caller)
+	}
+
+	child := NewScope(fn.Scope)
+	child.IsFunction = true
+
+	if err := bindArguments(fn.Decl.ArgumentList, args, caller, child); err != nil {
+		return nil, err
+	}
+
+	out, err := fn.Runtime.ExecuteList(child, fn.Decl.Block.Stmts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range out.Stmts {
+		ret, ok := stmt.(*ast.ReturnStmt)
+
+		if !ok {
+			return nil, fmt.Errorf("@function rules may not contain style rules or declarations.")
+		}
+
+		return ret.Value, nil
+	}
+
+	return nil, fmt.Errorf("Function finished without @return.")
+}
+
+/*
+bindArguments evaluates the arguments produced by parser.ApplyCallArguments
+and inserts them into the callee scope.
+
+Values passed by the caller are evaluated in the caller scope, while default
+values are evaluated in the callee scope, since they are allowed to refer to
+the arguments declared before them:
+
+	@function foo($a, $b: $a * 2) { ... }
+*/
+func bindArguments(proto *ast.ArgumentList, args *ast.CallArgumentList, caller, callee *Scope) error {
+	for idx, v := range args.Args {
+		evalScope := caller
+
+		if proto != nil && idx < len(proto.Arguments) && proto.Arguments[idx].DefaultValue != nil && proto.Arguments[idx].DefaultValue == v.Value {
+			evalScope = callee
+		}
+
+		val, err := EvaluateExpr(v.Value, evalScope)
+
+		if err != nil {
+			return err
+		}
+
+		callee.Insert(v.Name.NormalizedName(), withoutSlash(val, evalScope))
+	}
+
+	return nil
+}
+
+// hasReturned reports if the output of a statement ends with @return,
+// in this case the rest of the block must not be executed
+func hasReturned(list *ast.StmtList) bool {
+	if list == nil || len(list.Stmts) == 0 {
+		return false
+	}
+
+	_, ok := list.Stmts[len(list.Stmts)-1].(*ast.ReturnStmt)
+	return ok
+}
@@ -0,0 +1,289 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/c9s/c6/ast"
+)
+
+func init() {
+	module := NewBuiltinModule("sass:list",
+		NewBuiltinFunction("length($list)", listLength),
+		NewBuiltinFunction("nth($list, $n)", listNth),
+		NewBuiltinFunction("set-nth($list, $n, $value)", listSetNth),
+		NewBuiltinFunction("join($list1, $list2, $separator: auto, $bracketed: auto)", listJoin),
+		NewBuiltinFunction("append($list, $val, $separator: auto)", listAppend),
+		NewBuiltinFunction("zip($lists...)", listZip),
+		NewBuiltinFunction("index($list, $value)", listIndex),
+		NewBuiltinFunction("separator($list)", listSeparator),
+		NewBuiltinFunction("is-bracketed($list)", listIsBracketed),
+		NewBuiltinFunction("slash($elements...)", listSlash),
+	)
+
+	builtinModules["sass:list"] = module
+
+	registerGlobalFunction(module, "length", "length")
+	registerGlobalFunction(module, "nth", "nth")
+	registerGlobalFunction(module, "set-nth", "set-nth")
+	registerGlobalFunction(module, "join", "join")
+	registerGlobalFunction(module, "append", "append")
+	registerGlobalFunction(module, "zip", "zip")
+	registerGlobalFunction(module, "index", "index")
+	registerGlobalFunction(module, "separator", "list-separator")
+	registerGlobalFunction(module, "is-bracketed", "is-bracketed")
+}
+
+// the separators of ast.List by their sass names
+var listSeparators = map[string]string{
+	"space": " ",
+	"comma": ", ",
+	"slash": "/",
+}
+
+// listItems returns the items of the value seen as a list, any value
+// which is not a list is a list with a single item. Maps are lists of
+// key-value pairs.
+func listItems(v ast.Value) []ast.Expr {
+	switch t := v.(type) {
+	case *ast.List:
+		return t.Exprs
+	case *ast.Map:
+		var items []ast.Expr
+		for _, item := range t.Items {
+			pair := ast.NewSpaceSepList()
+			pair.Append(item.Key)
+			pair.Append(item.Value)
+			items = append(items, pair)
+		}
+		return items
+	}
+
+	return []ast.Expr{v}
+}
+
+// separatorName returns the sass name of the list separator, values which
+// are not lists are space separated
+func separatorName(v ast.Value) string {
+	switch t := v.(type) {
+	case *ast.List:
+		for name, sep := range listSeparators {
+			if sep == t.Separator {
+				return name
+			}
+		}
+	case *ast.Map:
+		if t.Len() > 0 {
+			return "comma"
+		}
+	}
+
+	return "space"
+}
+
+func isBracketed(v ast.Value) bool {
+	l, ok := v.(*ast.List)
+	return ok && l.Bracketed
+}
+
+/*
+autoSeparator returns the separator of the list for join() and append(),
+a list with less than two items has no separator yet, so the one of
+the other list is used instead:
+
+	join(a, (b, c)) => a, b, c
+*/
+func autoSeparator(lists ...ast.Value) string {
+	for _, l := range lists {
+		if len(listItems(l)) > 1 {
+			return listSeparators[separatorName(l)]
+		}
+	}
+
+	return " "
+}
+
+// ListIndex returns the offset of the index $n, negative indexes count
+// from the end of the list
+func (args *BuiltinArguments) ListIndex(idx int, length int) (int, error) {
+	n, err := args.Int(idx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if n == 0 {
+		return 0, fmt.Errorf("%s: List index may not be 0.", args.Names[idx])
+	}
+
+	if n > length || -n > length {
+		return 0, fmt.Errorf("%s: Invalid index %d for a list with %d elements.", args.Names[idx], n, length)
+	}
+
+	if n < 0 {
+		return length + n, nil
+	}
+
+	return n - 1, nil
+}
+
+// Separator returns the separator given by the name, the second return
+// value is false for "auto"
+func (args *BuiltinArguments) Separator(idx int) (string, bool, error) {
+	name, err := args.String(idx)
+
+	if err != nil {
+		return "", false, err
+	}
+
+	if name.Value == "auto" {
+		return "", false, nil
+	}
+
+	if sep, ok := listSeparators[name.Value]; ok {
+		return sep, true, nil
+	}
+
+	return "", false, fmt.Errorf("%s: Must be \"space\", \"comma\", \"slash\", or \"auto\".", args.Names[idx])
+}
+
+func listLength(args *BuiltinArguments) (ast.Value, error) {
+	return ast.NewNumber(float64(len(listItems(args.Value(0)))), nil, nil), nil
+}
+
+func listNth(args *BuiltinArguments) (ast.Value, error) {
+	items := listItems(args.Value(0))
+
+	offset, err := args.ListIndex(1, len(items))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return items[offset], nil
+}
+
+func listSetNth(args *BuiltinArguments) (ast.Value, error) {
+	items := listItems(args.Value(0))
+
+	offset, err := args.ListIndex(1, len(items))
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := &ast.List{
+		Separator: listSeparators[separatorName(args.Value(0))],
+		Bracketed: isBracketed(args.Value(0)),
+	}
+
+	list.Exprs = append(list.Exprs, items...)
+	list.Exprs[offset] = args.Value(2)
+
+	return list, nil
+}
+
+func listJoin(args *BuiltinArguments) (ast.Value, error) {
+	list1, list2 := args.Value(0), args.Value(1)
+
+	separator, ok, err := args.Separator(2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		separator = autoSeparator(list1, list2)
+	}
+
+	bracketed := isBracketed(list1)
+
+	if s, ok := args.Value(3).(*ast.String); !ok || s.Value != "auto" {
+		bracketed = args.Bool(3)
+	}
+
+	list := &ast.List{
+		Separator: separator,
+		Bracketed: bracketed,
+	}
+
+	list.Exprs = append(list.Exprs, listItems(list1)...)
+	list.Exprs = append(list.Exprs, listItems(list2)...)
+
+	return list, nil
+}
+
+func listAppend(args *BuiltinArguments) (ast.Value, error) {
+	separator, ok, err := args.Separator(2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		separator = autoSeparator(args.Value(0))
+	}
+
+	list := &ast.List{
+		Separator: separator,
+		Bracketed: isBracketed(args.Value(0)),
+	}
+
+	list.Exprs = append(list.Exprs, listItems(args.Value(0))...)
+	list.Exprs = append(list.Exprs, args.Value(1))
+
+	return list, nil
+}
+
+func listZip(args *BuiltinArguments) (ast.Value, error) {
+	lists := args.List(0)
+	out := ast.NewCommaSepList()
+
+	for idx := 0; len(lists) > 0; idx++ {
+		tuple := ast.NewSpaceSepList()
+
+		for _, l := range lists {
+			items := listItems(l)
+
+			if idx >= len(items) {
+				return out, nil
+			}
+
+			tuple.Append(items[idx])
+		}
+
+		out.Append(tuple)
+	}
+
+	return out, nil
+}
+
+func listIndex(args *BuiltinArguments) (ast.Value, error) {
+	for idx, item := range listItems(args.Value(0)) {
+		if ast.Equal(item, args.Value(1)) {
+			return ast.NewNumber(float64(idx+1), nil, nil), nil
+		}
+	}
+
+	return ast.NewNullWithToken(nil), nil
+}
+
+func listSeparator(args *BuiltinArguments) (ast.Value, error) {
+	return ast.NewString(0, separatorName(args.Value(0)), nil), nil
+}
+
+func listIsBracketed(args *BuiltinArguments) (ast.Value, error) {
+	return ast.NewBoolean(isBracketed(args.Value(0))), nil
+}
+
+func listSlash(args *BuiltinArguments) (ast.Value, error) {
+	items := args.List(0)
+
+	if len(items) < 2 {
+		return nil, fmt.Errorf("At least two elements are required.")
+	}
+
+	list := ast.NewList("/")
+	list.Exprs = append(list.Exprs, items...)
+
+	return list, nil
+}
@@ -0,0 +1,321 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/c9s/c6/ast"
+)
+
+func init() {
+	module := NewBuiltinModule("sass:map",
+		NewBuiltinFunction("get($map, $key, $keys...)", mapGet),
+		NewBuiltinFunction("merge($map1, $args...)", mapMerge),
+		NewBuiltinFunction("deep-merge($map1, $map2)", mapDeepMerge),
+		NewBuiltinFunction("remove($map, $keys...)", mapRemove),
+		NewBuiltinFunction("deep-remove($map, $key, $keys...)", mapDeepRemove),
+		NewBuiltinFunction("keys($map)", mapKeys),
+		NewBuiltinFunction("values($map)", mapValues),
+		NewBuiltinFunction("has-key($map, $key, $keys...)", mapHasKey),
+		NewBuiltinFunction("set($map, $args...)", mapSet),
+	)
+
+	builtinModules["sass:map"] = module
+
+	registerGlobalFunction(module, "get", "map-get")
+	registerGlobalFunction(module, "merge", "map-merge")
+	registerGlobalFunction(module, "remove", "map-remove")
+	registerGlobalFunction(module, "keys", "map-keys")
+	registerGlobalFunction(module, "values", "map-values")
+	registerGlobalFunction(module, "has-key", "map-has-key")
+}
+
+// EvaluateMap evaluates the keys and the values of the map, the keys
+// must be unique once they're evaluated
+func EvaluateMap(m *ast.Map, scope *Scope) (*ast.Map, error) {
+	out := ast.NewMap()
+
+	for _, item := range m.Items {
+		key, err := EvaluateExpr(item.Key, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if out.Has(key) {
+			return nil, fmt.Errorf("Duplicate key.")
+		}
+
+		val, err := EvaluateExpr(item.Value, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		out.Set(key, val)
+	}
+
+	return out, nil
+}
+
+// Map returns the argument as a map, an empty list is an empty map
+func (args *BuiltinArguments) Map(idx int) (*ast.Map, error) {
+	switch v := args.Values[idx].(type) {
+	case *ast.Map:
+		return v, nil
+	case *ast.List:
+		if v.Len() == 0 {
+			return ast.NewMap(), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %s is not a map.", args.Names[idx], args.Values[idx])
+}
+
+// mapGetPath returns the value at the end of the keys going through the
+// nested maps, nil is returned when any of the keys is missing
+func mapGetPath(m *ast.Map, keys []ast.Expr) ast.Value {
+	var val ast.Value = m
+
+	for _, key := range keys {
+		nested, ok := val.(*ast.Map)
+
+		if !ok {
+			return nil
+		}
+
+		if val = nested.Get(key); val == nil {
+			return nil
+		}
+	}
+
+	return val
+}
+
+/*
+mapSetPath returns a copy of the map with the value at the end of the
+keys replaced by the result of fn, the nested maps are created when they
+don't exist yet:
+
+	map.set((a: (b: 1)), a, c, 2) => (a: (b: 1, c: 2))
+
+The argument of fn is nil when there's no value for the keys yet.
+*/
+func mapSetPath(m *ast.Map, keys []ast.Expr, fn func(ast.Value) ast.Value) *ast.Map {
+	out := m.Copy()
+
+	if len(keys) == 1 {
+		out.Set(keys[0], fn(out.Get(keys[0])))
+		return out
+	}
+
+	nested, ok := out.Get(keys[0]).(*ast.Map)
+
+	if !ok {
+		nested = ast.NewMap()
+	}
+
+	out.Set(keys[0], mapSetPath(nested, keys[1:], fn))
+	return out
+}
+
+// mapMergeMaps returns a copy of m1 with the items of m2, the nested maps
+// are merged as well when deep is set
+func mapMergeMaps(m1, m2 *ast.Map, deep bool) *ast.Map {
+	out := m1.Copy()
+
+	for _, item := range m2.Items {
+		if deep {
+			n1, ok1 := out.Get(item.Key).(*ast.Map)
+			n2, ok2 := item.Value.(*ast.Map)
+
+			if ok1 && ok2 {
+				out.Set(item.Key, mapMergeMaps(n1, n2, true))
+				continue
+			}
+		}
+
+		out.Set(item.Key, item.Value)
+	}
+
+	return out
+}
+
+func mapGet(args *BuiltinArguments) (ast.Value, error) {
+	m, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := append([]ast.Expr{args.Value(1)}, args.List(2)...)
+
+	if val := mapGetPath(m, keys); val != nil {
+		return val, nil
+	}
+
+	return ast.NewNullWithToken(nil), nil
+}
+
+func mapHasKey(args *BuiltinArguments) (ast.Value, error) {
+	m, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := append([]ast.Expr{args.Value(1)}, args.List(2)...)
+
+	return ast.NewBoolean(mapGetPath(m, keys) != nil), nil
+}
+
+// mapMerge merges the maps, `map.merge($map1, $keys..., $map2)` merges
+// $map2 into the map nested in $map1 at the end of the keys
+func mapMerge(args *BuiltinArguments) (ast.Value, error) {
+	m1, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rest := args.List(1)
+
+	if len(rest) == 0 {
+		return nil, fmt.Errorf("Expected $args to contain a map.")
+	}
+
+	m2, ok := rest[len(rest)-1].(*ast.Map)
+
+	if !ok {
+		return nil, fmt.Errorf("$map2: %s is not a map.", rest[len(rest)-1])
+	}
+
+	keys := rest[:len(rest)-1]
+
+	if len(keys) == 0 {
+		return mapMergeMaps(m1, m2, false), nil
+	}
+
+	return mapSetPath(m1, keys, func(old ast.Value) ast.Value {
+		if nested, ok := old.(*ast.Map); ok {
+			return mapMergeMaps(nested, m2, false)
+		}
+
+		return m2
+	}), nil
+}
+
+func mapDeepMerge(args *BuiltinArguments) (ast.Value, error) {
+	m1, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	m2, err := args.Map(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mapMergeMaps(m1, m2, true), nil
+}
+
+func mapRemove(args *BuiltinArguments) (ast.Value, error) {
+	m, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := m.Copy()
+
+	for _, key := range args.List(1) {
+		out.Delete(key)
+	}
+
+	return out, nil
+}
+
+func mapDeepRemove(args *BuiltinArguments) (ast.Value, error) {
+	m, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := append([]ast.Expr{args.Value(1)}, args.List(2)...)
+	last := keys[len(keys)-1]
+
+	if len(keys) == 1 {
+		out := m.Copy()
+		out.Delete(last)
+		return out, nil
+	}
+
+	// nothing to remove when the path doesn't exist
+	nested, ok := mapGetPath(m, keys[:len(keys)-1]).(*ast.Map)
+
+	if !ok || !nested.Has(last) {
+		return m, nil
+	}
+
+	return mapSetPath(m, keys[:len(keys)-1], func(old ast.Value) ast.Value {
+		out := old.(*ast.Map).Copy()
+		out.Delete(last)
+		return out
+	}), nil
+}
+
+func mapKeys(args *BuiltinArguments) (ast.Value, error) {
+	m, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := ast.NewCommaSepList()
+
+	for _, item := range m.Items {
+		list.Append(item.Key)
+	}
+
+	return list, nil
+}
+
+func mapValues(args *BuiltinArguments) (ast.Value, error) {
+	m, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list := ast.NewCommaSepList()
+
+	for _, item := range m.Items {
+		list.Append(item.Value)
+	}
+
+	return list, nil
+}
+
+// mapSet sets the value of a key, `map.set($map, $keys..., $value)` sets
+// the value in the map nested at the end of the keys
+func mapSet(args *BuiltinArguments) (ast.Value, error) {
+	m, err := args.Map(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rest := args.List(1)
+
+	if len(rest) < 2 {
+		return nil, fmt.Errorf("Expected $args to contain a key and a value.")
+	}
+
+	value := rest[len(rest)-1]
+
+	return mapSetPath(m, rest[:len(rest)-1], func(ast.Value) ast.Value {
+		return value
+	}), nil
+}
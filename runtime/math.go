@@ -0,0 +1,402 @@
+package runtime
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/c9s/c6/ast"
+)
+
+func init() {
+	module := NewBuiltinModule("sass:math",
+		NewBuiltinFunction("div($number1, $number2)", mathDiv),
+		NewBuiltinFunction("ceil($number)", mathRounding(math.Ceil)),
+		NewBuiltinFunction("floor($number)", mathRounding(math.Floor)),
+		NewBuiltinFunction("round($number)", mathRounding(fuzzyRound)),
+		NewBuiltinFunction("abs($number)", mathRounding(math.Abs)),
+		NewBuiltinFunction("clamp($min, $number, $max)", mathClamp),
+		NewBuiltinFunction("min($numbers...)", mathMinMax(-1)),
+		NewBuiltinFunction("max($numbers...)", mathMinMax(1)),
+		NewBuiltinFunction("hypot($numbers...)", mathHypot),
+		NewBuiltinFunction("sqrt($number)", mathUnitless(math.Sqrt)),
+		NewBuiltinFunction("pow($base, $exponent)", mathPow),
+		NewBuiltinFunction("log($number, $base: null)", mathLog),
+		NewBuiltinFunction("sin($number)", mathTrig(math.Sin)),
+		NewBuiltinFunction("cos($number)", mathTrig(math.Cos)),
+		NewBuiltinFunction("tan($number)", mathTrig(math.Tan)),
+		NewBuiltinFunction("asin($number)", mathInverseTrig(math.Asin)),
+		NewBuiltinFunction("acos($number)", mathInverseTrig(math.Acos)),
+		NewBuiltinFunction("atan($number)", mathInverseTrig(math.Atan)),
+		NewBuiltinFunction("atan2($y, $x)", mathAtan2),
+		NewBuiltinFunction("percentage($number)", mathPercentage),
+		NewBuiltinFunction("random($limit: null)", mathRandom),
+		NewBuiltinFunction("unit($number)", mathUnit),
+		NewBuiltinFunction("is-unitless($number)", mathIsUnitless),
+		NewBuiltinFunction("compatible($number1, $number2)", mathCompatible),
+	)
+
+	module.Scope.Insert("$pi", ast.NewNumber(math.Pi, nil, nil))
+	module.Scope.Insert("$e", ast.NewNumber(math.E, nil, nil))
+
+	builtinModules["sass:math"] = module
+
+	registerGlobalFunction(module, "ceil", "ceil")
+	registerGlobalFunction(module, "floor", "floor")
+	registerGlobalFunction(module, "round", "round")
+	registerGlobalFunction(module, "abs", "abs")
+	registerGlobalFunction(module, "min", "min")
+	registerGlobalFunction(module, "max", "max")
+	registerGlobalFunction(module, "percentage", "percentage")
+	registerGlobalFunction(module, "random", "random")
+	registerGlobalFunction(module, "unit", "unit")
+	registerGlobalFunction(module, "is-unitless", "unitless")
+	registerGlobalFunction(module, "compatible", "comparable")
+}
+
+/*
+fuzzyRound rounds the number like sass does, the numbers within epsilon
+of .5 are rounded up, or down for the negative numbers:
+
+	round(2.5) => 3
+	round(-2.5) => -3
+*/
+func fuzzyRound(x float64) float64 {
+	fraction := x - math.Floor(x)
+
+	if x > 0 {
+		if ast.FuzzyLessThan(fraction, 0.5) {
+			return math.Floor(x)
+		}
+
+		return math.Ceil(x)
+	}
+
+	if ast.FuzzyLessThanOrEqual(fraction, 0.5) {
+		return math.Floor(x)
+	}
+
+	return math.Ceil(x)
+}
+
+/*
+toRadians returns the value of an angle in radians, a number without
+unit is already in radians:
+
+	math.sin(90deg) == math.sin(math.$pi / 2)
+*/
+func toRadians(args *BuiltinArguments, idx int) (float64, error) {
+	num, err := args.Number(idx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if rad, ok := num.ValueIn(ast.NewNumber(1, ast.NewUnit(ast.T_UNIT_RAD, nil), nil)); ok {
+		return rad, nil
+	}
+
+	return 0, fmt.Errorf("%s: Expected %s to be an angle.", args.Names[idx], num)
+}
+
+func degrees(radians float64) *ast.Number {
+	return ast.NewNumber(radians*180/math.Pi, ast.NewUnit(ast.T_UNIT_DEG, nil), nil)
+}
+
+func mathDiv(args *BuiltinArguments) (ast.Value, error) {
+	a, err := args.Number(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := args.Number(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NumberDivNumber(a, b), nil
+}
+
+// mathRounding returns the builtin function applying fn to the value of
+// the number, the unit is kept as it is
+func mathRounding(fn func(float64) float64) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		num, err := args.Number(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return num.WithValue(fn(num.Value)), nil
+	}
+}
+
+// mathUnitless returns the builtin function applying fn to a number
+// without units
+func mathUnitless(fn func(float64) float64) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		num, err := args.UnitlessNumber(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewNumber(fn(num.Value), nil, nil), nil
+	}
+}
+
+func mathClamp(args *BuiltinArguments) (ast.Value, error) {
+	var nums [3]*ast.Number
+
+	for idx := range nums {
+		num, err := args.Number(idx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		nums[idx] = num
+	}
+
+	min, num, max := nums[0], nums[1], nums[2]
+
+	_, value, _, err := numberOperands(min, num)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lower, upper, _, err := numberOperands(min, max)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if value <= lower {
+		return min, nil
+	}
+
+	if value >= upper {
+		return max, nil
+	}
+
+	return num, nil
+}
+
+// mathMinMax returns min() for a negative sign and max() for a positive one
+func mathMinMax(sign float64) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		var result *ast.Number
+
+		for _, item := range args.List(0) {
+			num, ok := item.(*ast.Number)
+
+			if !ok {
+				return nil, fmt.Errorf("%s is not a number.", item)
+			}
+
+			if result == nil {
+				result = num
+				continue
+			}
+
+			x, y, _, err := numberOperands(result, num)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if (y-x)*sign > 0 {
+				result = num
+			}
+		}
+
+		if result == nil {
+			return nil, fmt.Errorf("At least one argument must be passed.")
+		}
+
+		return result, nil
+	}
+}
+
+func mathHypot(args *BuiltinArguments) (ast.Value, error) {
+	var first *ast.Number
+	var sum float64
+
+	for _, item := range args.List(0) {
+		num, ok := item.(*ast.Number)
+
+		if !ok {
+			return nil, fmt.Errorf("%s is not a number.", item)
+		}
+
+		if first == nil {
+			first = num
+		}
+
+		value, ok := num.ValueIn(first)
+
+		if !ok || first.IsUnitless() != num.IsUnitless() {
+			return nil, incompatibleUnitsError(first, num)
+		}
+
+		sum += value * value
+	}
+
+	if first == nil {
+		return nil, fmt.Errorf("At least one argument must be passed.")
+	}
+
+	return first.WithValue(math.Sqrt(sum)), nil
+}
+
+func mathPow(args *BuiltinArguments) (ast.Value, error) {
+	base, err := args.UnitlessNumber(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := args.UnitlessNumber(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewNumber(math.Pow(base.Value, exponent.Value), nil, nil), nil
+}
+
+func mathLog(args *BuiltinArguments) (ast.Value, error) {
+	num, err := args.UnitlessNumber(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if args.IsNull(1) {
+		return ast.NewNumber(math.Log(num.Value), nil, nil), nil
+	}
+
+	base, err := args.UnitlessNumber(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewNumber(math.Log(num.Value)/math.Log(base.Value), nil, nil), nil
+}
+
+// mathTrig returns the builtin function applying fn to an angle
+func mathTrig(fn func(float64) float64) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		rad, err := toRadians(args, 0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewNumber(fn(rad), nil, nil), nil
+	}
+}
+
+// mathInverseTrig returns the builtin function applying fn to a number
+// without units, the angle is returned in degrees
+func mathInverseTrig(fn func(float64) float64) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		num, err := args.UnitlessNumber(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return degrees(fn(num.Value)), nil
+	}
+}
+
+func mathAtan2(args *BuiltinArguments) (ast.Value, error) {
+	y, err := args.Number(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := args.Number(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	a, b, _, err := numberOperands(y, x)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return degrees(math.Atan2(a, b)), nil
+}
+
+func mathPercentage(args *BuiltinArguments) (ast.Value, error) {
+	num, err := args.UnitlessNumber(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewNumber(num.Value*100, ast.NewUnit(ast.T_UNIT_PERCENT, nil), nil), nil
+}
+
+func mathRandom(args *BuiltinArguments) (ast.Value, error) {
+	if args.IsNull(0) {
+		return ast.NewNumber(rand.Float64(), nil, nil), nil
+	}
+
+	limit, err := args.Number(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if limit.Value < 1 {
+		return nil, fmt.Errorf("$limit: Must be greater than 0, was %s.", limit)
+	}
+
+	return ast.NewNumber(float64(rand.Intn(int(limit.Value))+1), nil, nil), nil
+}
+
+func mathUnit(args *BuiltinArguments) (ast.Value, error) {
+	num, err := args.Number(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewString('"', num.UnitString(), nil), nil
+}
+
+func mathIsUnitless(args *BuiltinArguments) (ast.Value, error) {
+	num, err := args.Number(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewBoolean(num.IsUnitless()), nil
+}
+
+func mathCompatible(args *BuiltinArguments) (ast.Value, error) {
+	a, err := args.Number(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := args.Number(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewBoolean(NumberComparable(a, b)), nil
+}
@@ -0,0 +1,152 @@
+package runtime
+
+import (
+	"strings"
+
+	"github.com/c9s/c6/ast"
+)
+
+// EvaluateMediaQueryList evaluates the variables, expressions and
+// interpolations of a media query list
+func EvaluateMediaQueryList(list *ast.MediaQueryList, scope *Scope) (*ast.MediaQueryList, error) {
+	if list == nil {
+		return nil, nil
+	}
+
+	out := ast.NewMediaQueryList()
+
+	for _, query := range list.List {
+		q := ast.NewMediaQuery(nil, nil)
+
+		if query.MediaType != nil {
+			// "not screen" and "only screen" are kept as they are
+			if _, ok := query.MediaType.Expr.(*ast.UnaryExpr); ok {
+				q.MediaType = query.MediaType
+			} else {
+				val, err := EvaluateExpr(query.MediaType.Expr, scope)
+
+				if err != nil {
+					return nil, err
+				}
+
+				q.MediaType = ast.NewMediaType(val)
+			}
+		}
+
+		if query.MediaExpr != nil {
+			expr, err := evaluateMediaExpr(query.MediaExpr, scope)
+
+			if err != nil {
+				return nil, err
+			}
+
+			q.MediaExpr = expr
+		}
+
+		out.Append(q)
+	}
+
+	return out, nil
+}
+
+func evaluateMediaExpr(expr ast.Expr, scope *Scope) (ast.Expr, error) {
+	switch t := expr.(type) {
+	case *ast.BinaryExpr:
+		left, err := evaluateMediaExpr(t.Left, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := evaluateMediaExpr(t.Right, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return ast.NewBinaryExpr(t.Op, left, right, t.Grouped), nil
+
+	case *ast.MediaFeature:
+		feature, err := EvaluateExpr(t.Feature, scope)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ret := ast.NewMediaFeature(feature, nil)
+		ret.Open = t.Open
+		ret.Close = t.Close
+
+		if t.Value != nil {
+			val, err := EvaluateExpr(t.Value, scope)
+
+			if err != nil {
+				return nil, err
+			}
+
+			ret.Value = val
+		}
+
+		return ret, nil
+	}
+
+	return EvaluateExpr(expr, scope)
+}
+
+/*
+mergeMediaQueryLists merges the queries of a nested @media rule with the
+queries of its parent:
+
+	@media screen {
+		@media (min-width: 100px) { ... }
+	}
+
+gives `@media screen and (min-width: 100px)`. Nil is returned when none of
+the queries could ever match together.
+*/
+func mergeMediaQueryLists(outer, inner *ast.MediaQueryList) *ast.MediaQueryList {
+	if outer == nil {
+		return inner
+	}
+
+	if inner == nil {
+		return outer
+	}
+
+	out := ast.NewMediaQueryList()
+
+	for _, a := range outer.List {
+		for _, b := range inner.List {
+			if q := mergeMediaQuery(a, b); q != nil {
+				out.Append(q)
+			}
+		}
+	}
+
+	if len(out.List) == 0 {
+		return nil
+	}
+
+	return out
+}
+
+func mergeMediaQuery(a, b *ast.MediaQuery) *ast.MediaQuery {
+	mediaType := a.MediaType
+
+	if mediaType == nil {
+		mediaType = b.MediaType
+	} else if b.MediaType != nil && !strings.EqualFold(a.MediaType.String(), b.MediaType.String()) {
+		// e.g. screen and print
+		return nil
+	}
+
+	mediaExpr := a.MediaExpr
+
+	if mediaExpr == nil {
+		mediaExpr = b.MediaExpr
+	} else if b.MediaExpr != nil {
+		mediaExpr = ast.NewBinaryExpr(ast.NewOp(ast.T_LOGICAL_AND), a.MediaExpr, b.MediaExpr, false)
+	}
+
+	return ast.NewMediaQuery(mediaType, mediaExpr)
+}
@@ -0,0 +1,48 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/c9s/c6/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func mediaType(name string) *ast.MediaType {
+	return ast.NewMediaType(ast.NewString(0, name, nil))
+}
+
+func mediaFeature(name, value string) ast.Expr {
+	return ast.NewMediaFeature(ast.NewString(0, name, nil), ast.NewString(0, value, nil))
+}
+
+func TestMergeMediaQuery(t *testing.T) {
+	var data = []struct {
+		a        *ast.MediaQuery
+		b        *ast.MediaQuery
+		expected string
+	}{
+		{ast.NewMediaQuery(mediaType("screen"), nil), ast.NewMediaQuery(nil, mediaFeature("min-width", "1px")), "screen and (min-width:1px)"},
+		{ast.NewMediaQuery(nil, mediaFeature("min-width", "1px")), ast.NewMediaQuery(mediaType("print"), nil), "print and (min-width:1px)"},
+		{ast.NewMediaQuery(mediaType("screen"), nil), ast.NewMediaQuery(mediaType("SCREEN"), nil), "screen"},
+		{ast.NewMediaQuery(nil, mediaFeature("min-width", "1px")), ast.NewMediaQuery(nil, mediaFeature("max-width", "2px")), "(min-width:1px)and(max-width:2px)"},
+	}
+
+	for _, d := range data {
+		res := mergeMediaQuery(d.a, d.b)
+
+		if assert.NotNil(t, res) {
+			assert.Equal(t, d.expected, res.String())
+		}
+	}
+}
+
+func TestMergeMediaQueryConflict(t *testing.T) {
+	assert.Nil(t, mergeMediaQuery(
+		ast.NewMediaQuery(mediaType("screen"), nil),
+		ast.NewMediaQuery(mediaType("print"), nil),
+	))
+
+	outer := &ast.MediaQueryList{List: []*ast.MediaQuery{ast.NewMediaQuery(mediaType("print"), nil)}}
+	inner := &ast.MediaQueryList{List: []*ast.MediaQuery{ast.NewMediaQuery(mediaType("screen"), nil)}}
+	assert.Nil(t, mergeMediaQueryLists(outer, inner))
+}
@@ -0,0 +1,438 @@
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/c9s/c6/ast"
+)
+
+func init() {
+	module := NewBuiltinModule("sass:meta",
+		NewBuiltinFunction("type-of($value)", metaTypeOf),
+		NewBuiltinFunction("inspect($value)", metaInspect),
+		NewBuiltinFunction("variable-exists($name)", metaVariableExists),
+		NewBuiltinFunction("global-variable-exists($name, $module: null)", metaGlobalVariableExists),
+		NewBuiltinFunction("function-exists($name, $module: null)", metaFunctionExists),
+		NewBuiltinFunction("mixin-exists($name, $module: null)", metaMixinExists),
+		NewBuiltinFunction("content-exists()", metaContentExists),
+		NewBuiltinFunction("get-function($name, $css: false, $module: null)", metaGetFunction),
+		NewBuiltinFunction("call($function, $args...)", metaCall),
+		NewBuiltinFunction("keywords($args)", metaKeywords),
+		NewBuiltinFunction("feature-exists($feature)", metaFeatureExists),
+		NewBuiltinFunction("module-variables($module)", metaModuleVariables),
+		NewBuiltinFunction("module-functions($module)", metaModuleFunctions),
+	)
+
+	builtinModules["sass:meta"] = module
+
+	registerGlobalFunction(module, "type-of", "type-of")
+	registerGlobalFunction(module, "inspect", "inspect")
+	registerGlobalFunction(module, "variable-exists", "variable-exists")
+	registerGlobalFunction(module, "global-variable-exists", "global-variable-exists")
+	registerGlobalFunction(module, "function-exists", "function-exists")
+	registerGlobalFunction(module, "mixin-exists", "mixin-exists")
+	registerGlobalFunction(module, "content-exists", "content-exists")
+	registerGlobalFunction(module, "get-function", "get-function")
+	registerGlobalFunction(module, "call", "call")
+	registerGlobalFunction(module, "keywords", "keywords")
+	registerGlobalFunction(module, "feature-exists", "feature-exists")
+}
+
+/*
+FunctionValue is the value returned by get-function(), functions can be
+stored in variables and passed around like any other value:
+
+	$fn: get-function("darken");
+	color: call($fn, #fff, 10%);
+
+Function is nil for the plain css functions.
+*/
+type FunctionValue struct {
+	Name     string
+	Function *Function
+}
+
+func (fv *FunctionValue) String() string {
+	return fmt.Sprintf("get-function(\"%s\")", fv.Name)
+}
+
+// the features reported by feature-exists()
+var supportedFeatures = map[string]bool{
+	"global-variable-shadowing":   true,
+	"extend-selector-pseudoclass": true,
+	"units-level-3":               true,
+	"at-error":                    true,
+	"custom-property":             true,
+}
+
+/*
+inspect returns the sass representation of the value, unlike the css
+output the quotes of the strings are kept and the nested lists get
+parentheses when needed:
+
+	inspect((1, 2) 3) => (1, 2) 3
+*/
+func inspect(v ast.Value) string {
+	switch t := v.(type) {
+	case *ast.List:
+		if len(t.Exprs) == 0 {
+			if t.Bracketed {
+				return "[]"
+			}
+			return "()"
+		}
+
+		var strs []string
+		for _, expr := range t.Exprs {
+			str := inspect(expr)
+
+			// comma lists and lists with the same separator are
+			// ambiguous without parentheses
+			nested, ok := expr.(*ast.List)
+			if ok && !nested.Bracketed && len(nested.Exprs) > 1 && (nested.Separator == ", " || nested.Separator == t.Separator) {
+				str = "(" + str + ")"
+			}
+
+			strs = append(strs, str)
+		}
+
+		str := strings.Join(strs, t.Separator)
+
+		if t.Bracketed {
+			return "[" + str + "]"
+		}
+
+		if len(t.Exprs) == 1 && t.Separator == ", " {
+			return "(" + str + ",)"
+		}
+
+		return str
+	case *ast.Map:
+		var strs []string
+		for _, item := range t.Items {
+			strs = append(strs, inspect(item.Key)+": "+inspect(item.Value))
+		}
+		return "(" + strings.Join(strs, ", ") + ")"
+	}
+
+	return v.String()
+}
+
+func metaTypeOf(args *BuiltinArguments) (ast.Value, error) {
+	var name string
+
+	switch t := args.Value(0).(type) {
+	case *ast.Number:
+		name = "number"
+	case *ast.Boolean:
+		name = "bool"
+	case *ast.Null:
+		name = "null"
+	case *ast.Map:
+		name = "map"
+	case *ast.List:
+		name = "list"
+		if t.Keywords != nil {
+			name = "arglist"
+		}
+	case *FunctionValue:
+		name = "function"
+	case *ast.Color:
+		name = "color"
+	default:
+		name = "string"
+	}
+
+	return ast.NewString(0, name, nil), nil
+}
+
+func metaInspect(args *BuiltinArguments) (ast.Value, error) {
+	return ast.NewString(0, inspect(args.Value(0)), nil), nil
+}
+
+// memberName returns the normalized name of the member, variables are
+// prefixed with $
+func memberName(args *BuiltinArguments, idx int, sigil string) (string, error) {
+	name, err := args.String(idx)
+
+	if err != nil {
+		return "", err
+	}
+
+	return normalizeMemberName(sigil + name.Value), nil
+}
+
+// moduleArgument returns the module loaded with the namespace given by
+// the argument, nil is returned when the argument is null
+func moduleArgument(args *BuiltinArguments, idx int) (*Module, error) {
+	if args.IsNull(idx) {
+		return nil, nil
+	}
+
+	namespace, err := args.String(idx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := args.Scope.LookupModule(namespace.Value, "")
+
+	if err != nil {
+		return nil, fmt.Errorf("There is no module with namespace \"%s\".", namespace.Value)
+	}
+
+	return module, nil
+}
+
+func metaVariableExists(args *BuiltinArguments) (ast.Value, error) {
+	name, err := memberName(args, 0, "$")
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = args.Scope.Lookup(name)
+
+	return ast.NewBoolean(err == nil), nil
+}
+
+func metaGlobalVariableExists(args *BuiltinArguments) (ast.Value, error) {
+	name, err := memberName(args, 0, "$")
+
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := moduleArgument(args, 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if module != nil {
+		_, ok := module.Variable(name)
+		return ast.NewBoolean(ok), nil
+	}
+
+	_, err = args.Scope.GetGlobal().Lookup(name)
+
+	return ast.NewBoolean(err == nil), nil
+}
+
+// lookupFunction returns the function by its name, the global builtin
+// functions are looked up when no module is given
+func lookupFunction(args *BuiltinArguments, name string, module *Module) (*Function, bool) {
+	if module != nil {
+		return module.Function(name)
+	}
+
+	if fn, err := args.Scope.LookupFunction(name); err == nil {
+		return fn, true
+	}
+
+	fn, ok := globalFunctions[name]
+
+	return fn, ok
+}
+
+func metaFunctionExists(args *BuiltinArguments) (ast.Value, error) {
+	name, err := memberName(args, 0, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := moduleArgument(args, 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, ok := lookupFunction(args, name, module)
+
+	return ast.NewBoolean(ok), nil
+}
+
+func metaMixinExists(args *BuiltinArguments) (ast.Value, error) {
+	name, err := memberName(args, 0, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := moduleArgument(args, 1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if module != nil {
+		_, ok := module.Mixin(name)
+		return ast.NewBoolean(ok), nil
+	}
+
+	_, err = args.Scope.LookupMixin(name)
+
+	return ast.NewBoolean(err == nil), nil
+}
+
+func metaContentExists(args *BuiltinArguments) (ast.Value, error) {
+	return EvaluateContentExists(args.Scope)
+}
+
+func metaGetFunction(args *BuiltinArguments) (ast.Value, error) {
+	name, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	module, err := moduleArgument(args, 2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if args.Bool(1) {
+		if module != nil {
+			return nil, fmt.Errorf("$css and $module may not both be passed at once.")
+		}
+
+		return &FunctionValue{Name: name.Value}, nil
+	}
+
+	fn, ok := lookupFunction(args, normalizeMemberName(name.Value), module)
+
+	if !ok {
+		return nil, fmt.Errorf("Function not found: %s", name)
+	}
+
+	return &FunctionValue{Name: name.Value, Function: fn}, nil
+}
+
+/*
+metaCall calls the function with the rest of the arguments, the keywords
+of the argument list are passed as named arguments:
+
+	call(get-function("adjust", $module: "color"), #fff, $red: -10)
+*/
+func metaCall(args *BuiltinArguments) (ast.Value, error) {
+	var fv *FunctionValue
+
+	switch t := args.Value(0).(type) {
+	case *FunctionValue:
+		fv = t
+	case *ast.String:
+		// the legacy form call("name", ...) calls the function by name
+		fv = &FunctionValue{Name: t.Value}
+		fv.Function, _ = lookupFunction(args, normalizeMemberName(t.Value), nil)
+	default:
+		return nil, fmt.Errorf("$function: %s is not a function reference.", args.Value(0))
+	}
+
+	callArgs := &ast.CallArgumentList{}
+
+	for _, item := range args.List(1) {
+		callArgs.Args = append(callArgs.Args, ast.NewCallArgumentWithToken(nil, item))
+	}
+
+	if rest, ok := args.Value(1).(*ast.List); ok && rest.Keywords != nil {
+		for _, item := range rest.Keywords.Items {
+			name := &ast.Variable{Name: "$" + item.Key.(*ast.String).Value}
+			callArgs.Args = append(callArgs.Args, ast.NewCallArgumentWithToken(name, item.Value))
+		}
+	}
+
+	if fv.Function == nil {
+		return ast.NewString(0, fmt.Sprintf("%s(%s)", fv.Name, callArgs), nil), nil
+	}
+
+	return fv.Function.Call(callArgs, args.Scope)
+}
+
+func metaKeywords(args *BuiltinArguments) (ast.Value, error) {
+	list, ok := args.Value(0).(*ast.List)
+
+	if !ok || list.Keywords == nil {
+		return nil, fmt.Errorf("$args: %s is not an argument list.", args.Value(0))
+	}
+
+	return list.Keywords, nil
+}
+
+func metaFeatureExists(args *BuiltinArguments) (ast.Value, error) {
+	feature, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewBoolean(supportedFeatures[feature.Value]), nil
+}
+
+// publicMembers returns the sorted names of the public members declared
+// in the scope of a module, without their $ sigil
+func publicMembers[T any](members map[string]T) []string {
+	var names []string
+
+	for name := range members {
+		name = strings.TrimPrefix(name, "$")
+
+		if !isPrivateMember(name) {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// memberKey returns the map key of a member name, the names are stored
+// with underscores while sass prints them with dashes
+func memberKey(name string) *ast.String {
+	return ast.NewString('"', strings.ReplaceAll(name, "_", "-"), nil)
+}
+
+func metaModuleVariables(args *BuiltinArguments) (ast.Value, error) {
+	module, err := moduleArgument(args, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if module == nil {
+		return nil, fmt.Errorf("$module: null is not a string.")
+	}
+
+	out := ast.NewMap()
+
+	for _, name := range publicMembers(module.Scope.Variables) {
+		out.Set(memberKey(name), module.Scope.Variables["$"+name])
+	}
+
+	return out, nil
+}
+
+func metaModuleFunctions(args *BuiltinArguments) (ast.Value, error) {
+	module, err := moduleArgument(args, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if module == nil {
+		return nil, fmt.Errorf("$module: null is not a string.")
+	}
+
+	out := ast.NewMap()
+
+	for _, name := range publicMembers(module.Scope.Functions) {
+		key := memberKey(name)
+		out.Set(key, &FunctionValue{Name: key.Value, Function: module.Scope.Functions[name]})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,33 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/c9s/c6/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInspectNestedLists(t *testing.T) {
+	num := func(v float64) *ast.Number { return ast.NewNumber(v, nil, nil) }
+
+	comma := ast.NewCommaSepList()
+	comma.Append(num(1))
+	comma.Append(num(2))
+
+	space := ast.NewSpaceSepList()
+	space.Append(comma)
+	space.Append(num(3))
+	assert.Equal(t, "(1, 2) 3", inspect(space))
+
+	outer := ast.NewCommaSepList()
+	outer.Append(space)
+	outer.Append(num(4))
+	assert.Equal(t, "(1, 2) 3, 4", inspect(outer))
+
+	single := ast.NewCommaSepList()
+	single.Append(num(1))
+	assert.Equal(t, "(1,)", inspect(single))
+
+	assert.Equal(t, "()", inspect(ast.NewSpaceSepList()))
+	assert.Equal(t, `"a"`, inspect(ast.NewString('"', "a", nil)))
+}
@@ -0,0 +1,18 @@
+package runtime
+
+import "github.com/c9s/c6/ast"
+
+// Mixin is a @mixin declaration together with the scope it has been
+// declared in, so that mixins of other modules still see the members
+// of their own module when they're included.
+type Mixin struct {
+	Decl  *ast.MixinStmt
+	Scope *Scope
+}
+
+func NewMixin(scope *Scope, decl *ast.MixinStmt) *Mixin {
+	return &Mixin{
+		Decl:  decl,
+		Scope: scope,
+	}
+}
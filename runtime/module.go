@@ -0,0 +1,310 @@
+package runtime
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/c9s/c6/ast"
+)
+
+// Module is a stylesheet loaded by @use. Every module is executed once
+// per compilation in its own global scope, the members declared there are
+// accessed through the namespace of the @use rule.
+type Module struct {
+	Path  string
+	Scope *Scope
+
+	// CSS is the output of the module, it's emitted by the first @use only
+	CSS *ast.StmtList
+}
+
+func NewModule(path string) *Module {
+	return &Module{
+		Path:  path,
+		Scope: NewScope(nil),
+	}
+}
+
+func (m *Module) Variable(name string) (ast.Value, bool) {
+	if v, ok := m.Scope.Variables[name]; ok {
+		return v, true
+	}
+
+	for _, fw := range m.Scope.Forwards {
+		if member, ok := fw.member(name); ok {
+			if v, ok := fw.Module.Variable(member); ok {
+				return v, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (m *Module) Function(name string) (*Function, bool) {
+	if fn, ok := m.Scope.Functions[name]; ok {
+		return fn, true
+	}
+
+	for _, fw := range m.Scope.Forwards {
+		if member, ok := fw.member(name); ok {
+			if fn, ok := fw.Module.Function(member); ok {
+				return fn, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (m *Module) Mixin(name string) (*Mixin, bool) {
+	if mixin, ok := m.Scope.Mixins[name]; ok {
+		return mixin, true
+	}
+
+	for _, fw := range m.Scope.Forwards {
+		if member, ok := fw.member(name); ok {
+			if mixin, ok := fw.Module.Mixin(member); ok {
+				return mixin, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Forward is a module re-exported by @forward as a part of another module
+type Forward struct {
+	Module *Module
+	Prefix string
+	Show   []string
+	Hide   []string
+}
+
+func NewForward(module *Module, stmt *ast.ForwardStmt) *Forward {
+	fw := &Forward{
+		Module: module,
+		Prefix: normalizeMemberName(stmt.Prefix),
+	}
+
+	for _, name := range stmt.Show {
+		fw.Show = append(fw.Show, normalizeMemberName(name))
+	}
+
+	for _, name := range stmt.Hide {
+		fw.Hide = append(fw.Hide, normalizeMemberName(name))
+	}
+
+	return fw
+}
+
+// member returns the name of the member in the forwarded module, the second
+// return value is false when the member is not forwarded
+func (fw *Forward) member(name string) (string, bool) {
+	if len(fw.Show) > 0 && !matchMemberNames(fw.Show, name) {
+		return "", false
+	}
+
+	if matchMemberNames(fw.Hide, name) {
+		return "", false
+	}
+
+	sigil := ""
+
+	if strings.HasPrefix(name, "$") {
+		sigil = "$"
+		name = name[1:]
+	}
+
+	if !strings.HasPrefix(name, fw.Prefix) {
+		return "", false
+	}
+
+	return sigil + strings.TrimPrefix(name, fw.Prefix), true
+}
+
+// matchMemberNames tells whether the name is in the list, the names ending
+// with '*' match all the names starting with them
+func matchMemberNames(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if pattern == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sass spec assumes that $var_name and $var-name mean the same
+func normalizeMemberName(name string) string {
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// ModuleNamespace returns the default namespace of the @use rule,
+// which is the last component of the url without the extension, e.g.
+// "src/_corners.scss" gives "corners" and "sass:math" gives "math"
+func ModuleNamespace(url string) string {
+	name := path.Base(strings.TrimPrefix(url, "sass:"))
+	name = strings.TrimPrefix(name, "_")
+
+	if ext := path.Ext(name); ext != "" {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	return name
+}
+
+// splitNamespace splits `ns.$name` or `ns.name` into the namespace and the
+// member name
+func splitNamespace(name string) (string, string, bool) {
+	return strings.Cut(name, ".")
+}
+
+// isPrivateMember tells whether the member starts with '-' or '_', such
+// members can't be used outside of the module
+func isPrivateMember(name string) bool {
+	name = strings.TrimPrefix(name, "$")
+	return strings.HasPrefix(name, "_") || strings.HasPrefix(name, "-")
+}
+
+// checkUseRules checks that the @use rules of the stylesheet are only
+// preceded by @forward, @charset and variable declarations
+func checkUseRules(stmts *ast.StmtList) error {
+	allowed := true
+
+	for _, stmt := range stmts.Stmts {
+		switch t := stmt.(type) {
+		case *ast.UseStmt:
+			if !allowed {
+				return fmt.Errorf("@use rules must be written before any other rules.")
+			}
+		case *ast.ForwardStmt, *ast.AssignStmt, *ast.CharsetStmt:
+		case *ast.AtRuleStmt:
+			// @charset is parsed as a plain css at-rule
+			if !strings.EqualFold(t.Name, "charset") {
+				allowed = false
+			}
+		default:
+			allowed = false
+		}
+	}
+
+	return nil
+}
+
+func (r *Runtime) executeUseStmt(scope *Scope, stmt *ast.UseStmt) (*ast.StmtList, error) {
+	namespace := stmt.Namespace
+
+	if namespace == "" {
+		namespace = ModuleNamespace(stmt.Path.Value)
+	}
+
+	namespace = normalizeMemberName(namespace)
+
+	if namespace != "*" {
+		if _, ok := scope.Modules[namespace]; ok {
+			return nil, fmt.Errorf("There's already a module with namespace \"%s\".", namespace)
+		}
+	}
+
+	var config *Configuration
+
+	if stmt.With != nil {
+		var err error
+
+		if config, err = EvaluateConfiguration(stmt.With, scope); err != nil {
+			return nil, err
+		}
+	}
+
+	module, out, err := r.loadModule(stmt.SourceFileName, stmt.Path.Value, config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Check(); err != nil {
+		return nil, err
+	}
+
+	if namespace == "*" {
+		scope.GlobalModules = append(scope.GlobalModules, module)
+	} else {
+		scope.Modules[namespace] = module
+	}
+
+	return out, nil
+}
+
+func (r *Runtime) executeForwardStmt(scope *Scope, stmt *ast.ForwardStmt) (*ast.StmtList, error) {
+	fw := NewForward(nil, stmt)
+
+	// the configuration of the module goes to the forwarded one as well
+	module, out, err := r.loadModule(stmt.SourceFileName, stmt.Path.Value, scope.Configuration.Forward(fw))
+
+	if err != nil {
+		return nil, err
+	}
+
+	fw.Module = module
+	scope.Forwards = append(scope.Forwards, fw)
+
+	return out, nil
+}
+
+// loadModule returns the module of the url, the module is executed only the
+// first time it's loaded. The output of the module is returned only then.
+func (r *Runtime) loadModule(source, url string, config *Configuration) (*Module, *ast.StmtList, error) {
+	if isBuiltinModule(url) {
+		return r.loadBuiltinModule(url, config)
+	}
+
+	if source == "" {
+		return nil, nil, fmt.Errorf("Unknown scss file to detect the module path.")
+	}
+
+	targetFname, err := r.GlobalParser.ResolveFileFname(source, url)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to find the module %s: %w", url, err)
+	}
+
+	if module, ok := r.Modules[targetFname]; ok {
+		if config != nil {
+			return nil, nil, fmt.Errorf("This module was already loaded, so it can't be configured using \"with\".")
+		}
+
+		return module, nil, nil
+	}
+
+	if _, ok := r.ExecutedPaths[targetFname]; ok {
+		return nil, nil, fmt.Errorf("Module loop: this module is already being loaded.")
+	}
+
+	r.ExecutedPaths[targetFname] = struct{}{}
+	defer delete(r.ExecutedPaths, targetFname)
+
+	stmts, err := r.GlobalParser.ParseFile(targetFname)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	module := NewModule(targetFname)
+	module.Scope.Configuration = config
+
+	module.CSS, err = r.ExecuteList(module.Scope, stmts)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.Modules[targetFname] = module
+
+	return module, module.CSS, nil
+}
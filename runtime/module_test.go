@@ -0,0 +1,48 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/c9s/c6/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleNamespace(t *testing.T) {
+	var data = map[string]string{
+		"colors":             "colors",
+		"lib/colors":         "colors",
+		"lib/_colors.scss":   "colors",
+		"../theme/dark-mode": "dark-mode",
+		"sass:math":          "math",
+	}
+
+	for url, expected := range data {
+		assert.Equal(t, expected, ModuleNamespace(url))
+	}
+}
+
+func TestForwardMember(t *testing.T) {
+	fw := NewForward(nil, &ast.ForwardStmt{
+		Prefix: "btn-",
+		Show:   []string{"btn-*", "$btn-color"},
+		Hide:   []string{"btn-secret"},
+	})
+
+	var data = []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{"btn_primary", "primary", true},
+		{"$btn_color", "$color", true},
+		{"$btn_size", "", false},
+		{"btn_secret", "", false},
+		{"primary", "", false},
+	}
+
+	for _, d := range data {
+		member, ok := fw.member(d.name)
+		assert.Equal(t, d.ok, ok, d.name)
+		assert.Equal(t, d.expected, member, d.name)
+	}
+}
@@ -6,63 +6,204 @@ import (
 	"github.com/c9s/c6/ast"
 )
 
+/*
+NumberComparable tells whether the numbers can be added or compared, the
+units must be compatible and numbers without units are compatible with any
+number:
+
+	1s, 200ms => true
+	1px, 1s => false
+	1px*px, 1in*px => true
+*/
 func NumberComparable(a *ast.Number, b *ast.Number) bool {
-	if a.Unit == nil && b.Unit == nil {
-		return true
+	_, ok := b.ValueIn(a)
+	return ok
+}
+
+func incompatibleUnitsError(a, b *ast.Number) error {
+	return fmt.Errorf("%s and %s have incompatible units.", a, b)
+}
+
+/*
+numberOperands returns the values of the numbers in the same unit, the
+value of b is converted to the unit of a unless a has no unit. The unit of
+the result is returned as a number of value 1:
+
+	1in, 10px => 1, 0.104..., 1in
+	1, 10px => 1, 10, 1px
+*/
+func numberOperands(a *ast.Number, b *ast.Number) (x, y float64, unit *ast.Number, err error) {
+	if a.IsUnitless() {
+		return a.Value, b.Value, b.WithValue(1), nil
 	}
-	if a.Unit != nil && b.Unit != nil && a.Unit.Type == b.Unit.Type {
-		return true
+
+	value, ok := b.ValueIn(a)
+
+	if !ok {
+		return 0, 0, nil, incompatibleUnitsError(a, b)
 	}
-	return true
+
+	return a.Value, value, a.WithValue(1), nil
 }
 
 func NumberSubNumber(a *ast.Number, b *ast.Number) (*ast.Number, error) {
-	if a.Unit != nil && b.Unit != nil && a.Unit.Type != b.Unit.Type {
-		return nil, fmt.Errorf("Incompatible unit %s != %s.  %v - %v \n", a.Unit, b.Unit, a, b)
+	x, y, unit, err := numberOperands(a, b)
+
+	if err != nil {
+		return nil, err
 	}
-	var result = a.Value - b.Value
-	return ast.NewNumber(result, a.Unit, nil), nil
+
+	return unit.WithValue(x - y), nil
 }
 
 func NumberAddNumber(a *ast.Number, b *ast.Number) (*ast.Number, error) {
-	if (a.Unit == nil && b.Unit == nil) || (a.Unit != nil && b.Unit != nil && a.Unit.Type == b.Unit.Type) {
-		return ast.NewNumber(a.Value+b.Value, a.Unit, nil), nil
+	x, y, unit, err := numberOperands(a, b)
+
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("Incompatible unit %s != %s.  %v + %v \n", a.Unit, b.Unit, a, b)
+
+	return unit.WithValue(x + y), nil
 }
 
 /*
-10px / 3, 10 / 3, 10px / 10px is allowed here
+The units of the numbers are divided and the compatible units are
+cancelled:
+
+	10px / 2 => 5px
+	10px / 2px => 5
+	1in / 48px => 2
+	10px / 2em => 5px/em
 */
 func NumberDivNumber(a *ast.Number, b *ast.Number) *ast.Number {
-	// for 10/2 and 10px/2px
-	if (a.Unit == nil && b.Unit == nil) || (a.Unit != nil && b.Unit != nil && a.Unit.Type == b.Unit.Type) {
-		return ast.NewNumber(a.Value/b.Value, nil, nil)
-	}
-	if a.Unit != nil && b.Unit == nil {
-		return ast.NewNumber(a.Value/b.Value, a.Unit, nil)
-	}
-	return nil
+	var nums = append(append([]*ast.Unit{}, a.Numerators...), b.Denominators...)
+	var dens = append(append([]*ast.Unit{}, a.Denominators...), b.Numerators...)
+	return ast.NewNumberWithUnits(a.Value/b.Value, nums, dens)
 }
 
 /*
-3 * 10px, 10px * 3, 10px * 10px is allowed here
+The units of the numbers are multiplied and the compatible units are
+cancelled:
+
+	3 * 10px => 30px
+	10px * 10px => 100px*px
+	2px/em * 3em => 6px
 */
 func NumberMulNumber(a *ast.Number, b *ast.Number) *ast.Number {
-	if a.Unit == nil && b.Unit == nil {
-		return ast.NewNumber(a.Value*b.Value, nil, nil)
+	var nums = append(append([]*ast.Unit{}, a.Numerators...), b.Numerators...)
+	var dens = append(append([]*ast.Unit{}, a.Denominators...), b.Denominators...)
+	return ast.NewNumberWithUnits(a.Value*b.Value, nums, dens)
+}
+
+/*
+isSlashSeparated tells whether the `/` of the expression separates the
+values instead of dividing them, like dart-sass does. The slash is a
+separator when the operands are literals and the expression is neither in
+parentheses nor part of another arithmetic expression:
+
+	font: 12px/1.5 => 12px/1.5
+	width: (8px/2) => 4px
+	width: 1px + 8px/2 => 5px
+	width: $width/2 => division
+*/
+func isSlashSeparated(expr ast.Expr) bool {
+	t, ok := expr.(*ast.BinaryExpr)
+
+	if !ok || t.Op.Type != ast.T_DIV || t.Grouped {
+		return false
 	}
 
-	if a.Unit == nil || b.Unit == nil || a.Unit.Type == b.Unit.Type {
-		var result = a.Value * b.Value
-		var unit *ast.Unit = nil
-		if a.Unit != nil {
-			unit = a.Unit
+	isOperand := func(operand ast.Expr) bool {
+		switch operand.(type) {
+		case *ast.Number, *ast.String:
+			return true
 		}
-		if b.Unit != nil {
-			unit = b.Unit
+		return isSlashSeparated(operand)
+	}
+
+	return isOperand(t.Left) && isOperand(t.Right)
+}
+
+/*
+evaluateSlash returns the value of a slash separated expression, the
+numbers keep their operands to be printed as they were written while the
+other values are returned as a slash separated list:
+
+	8px/2 => 4px printed as 8px/2
+	auto/auto => [auto, auto] separated by "/"
+*/
+func evaluateSlash(expr ast.Expr) ast.Value {
+	t, ok := expr.(*ast.BinaryExpr)
+
+	if !ok {
+		return expr.(ast.Value)
+	}
+
+	left, right := evaluateSlash(t.Left), evaluateSlash(t.Right)
+
+	if a, ok := left.(*ast.Number); ok {
+		if b, ok := right.(*ast.Number); ok {
+			return NumberDivNumber(a, b).WithSlash(a, b)
 		}
-		return ast.NewNumber(result, unit, nil)
 	}
-	return nil
+
+	return slashList(left, right)
+}
+
+// slashList returns the values separated by "/", the slash separated
+// lists on the left are extended: a/b/c is a single list
+func slashList(left, right ast.Value) *ast.List {
+	list := ast.NewList("/")
+
+	if l, ok := left.(*ast.List); ok && l.Separator == "/" && !l.Bracketed {
+		list.Exprs = append(list.Exprs, l.Exprs...)
+	} else {
+		list.Append(left)
+	}
+
+	list.Append(right)
+	return list
+}
+
+/*
+slashRecommendation returns the math.div() call replacing the slash
+separated number:
+
+	8px/2/2 => math.div(math.div(8px, 2), 2)
+*/
+func slashRecommendation(num *ast.Number) string {
+	if num.Slash == nil {
+		return num.String()
+	}
+
+	return fmt.Sprintf("math.div(%s, %s)", slashRecommendation(num.Slash.Left), slashRecommendation(num.Slash.Right))
+}
+
+/*
+withoutSlash returns the value with the slash separated number divided,
+which happens when the number is assigned to a variable, passed to a
+function or returned. A deprecation warning is printed in this case:
+
+	$ratio: 16px/2; => 8px
+*/
+func withoutSlash(v ast.Value, scope *Scope) ast.Value {
+	num, ok := v.(*ast.Number)
+
+	if !ok || num.Slash == nil {
+		return v
+	}
+
+	scope.Warn(fmt.Sprintf("DEPRECATION WARNING: Using / for division is deprecated.\n\nRecommendation: %s\n\nMore info: https://sass-lang.com/d/slash-div",
+		slashRecommendation(num)))
+
+	return num.WithoutSlash()
+}
+
+// warnSlashDivision prints the deprecation warning of a `/` evaluated
+// as a division
+func warnSlashDivision(expr *ast.BinaryExpr, scope *Scope) {
+	left, right := expr.Left.String(), expr.Right.String()
+
+	scope.Warn(fmt.Sprintf("DEPRECATION WARNING: Using / for division outside of calc() is deprecated.\n\nRecommendation: math.div(%s, %s) or calc(%s / %s)\n\nMore info: https://sass-lang.com/d/slash-div",
+		left, right, left, right))
 }
@@ -9,45 +9,233 @@ import (
 type Scope struct {
 	Parent    *Scope
 	Variables map[string]ast.Value
-	Mixins    map[string]*ast.MixinStmt
+	Mixins    map[string]*Mixin
+	Functions map[string]*Function
+
+	// Modules are the modules loaded by @use by their namespace,
+	// GlobalModules are the ones loaded with `@use "..." as *`
+	Modules       map[string]*Module
+	GlobalModules []*Module
+
+	// Forwards are the modules re-exported by @forward
+	Forwards []*Forward
+
+	// Configuration is set for the global scope of a module loaded with
+	// `@use ... with (...)`
+	Configuration *Configuration
+
+	// IsMixin is set for the scope a mixin body is executed in,
+	// Content is the block passed by the @include, if any
+	IsMixin bool
+	Content *Content
+
+	// IsFunction is set for the scope a function body is executed in
+	IsFunction bool
+
+	// Selectors are the resolved selectors of the style rule the scope
+	// belongs to, they're the value of `&`
+	Selectors *ast.ComplexSelectorList
+
+	// Keyframes is the @keyframes rule the scope belongs to, its blocks
+	// hide the selectors of the parent rule
+	Keyframes *ast.AtRuleStmt
+
+	// WarnPrinter prints the warnings raised while evaluating the
+	// expressions, it is set for the root scopes by the runtime
+	WarnPrinter Printer
+
+	// Precision is the number of digits after the decimal point of the
+	// numbers converted to strings, it is inherited from the parent scope
+	Precision int
 }
 
 func NewScope(parent *Scope) *Scope {
+	precision := ast.DefaultPrecision
+
+	if parent != nil {
+		precision = parent.Precision
+	}
+
 	return &Scope{
 		Parent:    parent,
 		Variables: make(map[string]ast.Value, 4),
-		Mixins:    make(map[string]*ast.MixinStmt, 4),
+		Mixins:    make(map[string]*Mixin, 4),
+		Functions: make(map[string]*Function, 4),
+		Modules:   make(map[string]*Module),
+		Precision: precision,
 	}
 }
 
 func (s *Scope) Lookup(name string) (ast.Value, error) {
+	if namespace, member, ok := splitNamespace(name); ok {
+		module, err := s.LookupModule(namespace, member)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if v, ok := module.Variable(member); ok {
+			return v, nil
+		}
+
+		return nil, fmt.Errorf("Undefined variable.")
+	}
+
 	if v, ok := s.Variables[name]; ok {
 		return v, nil
 	} else if s.Parent != nil {
 		return s.Parent.Lookup(name)
 	}
 
+	for _, module := range s.GlobalModules {
+		if v, ok := module.Variable(name); ok {
+			return v, nil
+		}
+	}
+
 	return nil, fmt.Errorf("Undefined variable.")
 }
 
+// Warn prints the warning with the printer of the closest scope having
+// one, the warning is dropped when there is none
+func (s *Scope) Warn(msg any) {
+	for scope := s; scope != nil; scope = scope.Parent {
+		if scope.WarnPrinter != nil {
+			scope.WarnPrinter(msg)
+			return
+		}
+	}
+}
+
 func (s *Scope) Insert(name string, obj ast.Value) {
 	s.Variables[name] = obj
 }
 
-func (s *Scope) LookupMixin(name string) (*ast.MixinStmt, error) {
+func (s *Scope) LookupMixin(name string) (*Mixin, error) {
+	if namespace, member, ok := splitNamespace(name); ok {
+		module, err := s.LookupModule(namespace, member)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if v, ok := module.Mixin(member); ok {
+			return v, nil
+		}
+
+		return nil, fmt.Errorf("Undefined mixin.")
+	}
+
 	if v, ok := s.Mixins[name]; ok {
 		return v, nil
 	} else if s.Parent != nil {
 		return s.Parent.LookupMixin(name)
 	}
 
+	for _, module := range s.GlobalModules {
+		if v, ok := module.Mixin(name); ok {
+			return v, nil
+		}
+	}
+
 	return nil, fmt.Errorf("Undefined mixin - [%s]", name)
 }
 
-func (s *Scope) InsertMixin(name string, obj *ast.MixinStmt) {
+func (s *Scope) InsertMixin(name string, obj *Mixin) {
 	s.Mixins[name] = obj
 }
 
+func (s *Scope) LookupFunction(name string) (*Function, error) {
+	if namespace, member, ok := splitNamespace(name); ok {
+		module, err := s.LookupModule(namespace, member)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if v, ok := module.Function(member); ok {
+			return v, nil
+		}
+
+		return nil, fmt.Errorf("Undefined function.")
+	}
+
+	if v, ok := s.Functions[name]; ok {
+		return v, nil
+	} else if s.Parent != nil {
+		return s.Parent.LookupFunction(name)
+	}
+
+	for _, module := range s.GlobalModules {
+		if v, ok := module.Function(name); ok {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Undefined function - [%s]", name)
+}
+
+func (s *Scope) InsertFunction(name string, obj *Function) {
+	s.Functions[name] = obj
+}
+
+// LookupModule returns the module loaded with the namespace, the member
+// is the name that is going to be accessed through the namespace
+func (s *Scope) LookupModule(namespace, member string) (*Module, error) {
+	if isPrivateMember(member) {
+		return nil, fmt.Errorf("Private members can't be accessed from outside their modules.")
+	}
+
+	if v, ok := s.Modules[namespace]; ok {
+		return v, nil
+	} else if s.Parent != nil {
+		return s.Parent.LookupModule(namespace, member)
+	}
+
+	return nil, fmt.Errorf("There is no module with the namespace \"%s\".", namespace)
+}
+
+// LookupContent returns the content block of the closest mixin, the
+// second return value is false when the scope is not within a mixin
+func (s *Scope) LookupContent() (*Content, bool) {
+	if s.IsMixin {
+		return s.Content, true
+	} else if s.Parent != nil {
+		return s.Parent.LookupContent()
+	}
+
+	return nil, false
+}
+
+// InFunction tells whether the scope is within a function body, the mixins
+// included by a function can't return from it
+func (s *Scope) InFunction() bool {
+	if s.IsFunction {
+		return true
+	} else if s.IsMixin || s.Parent == nil {
+		return false
+	}
+
+	return s.Parent.InFunction()
+}
+
+// LookupSelectors returns the selectors of the closest style rule, nil is
+// returned outside of style rules. The mixins see the selectors of the
+// rule they're included in.
+func (s *Scope) LookupSelectors() *ast.ComplexSelectorList {
+	if s.Selectors != nil || s.IsMixin {
+		if s.Selectors == nil || len(*s.Selectors) == 0 {
+			return nil
+		}
+
+		return s.Selectors
+	} else if s.Parent != nil {
+		return s.Parent.LookupSelectors()
+	}
+
+	return nil
+}
+
 func (s *Scope) GetGlobal() *Scope {
 	scope := s
 
@@ -0,0 +1,546 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/c9s/c6/ast"
+	"github.com/c9s/c6/parser"
+)
+
+func init() {
+	module := NewBuiltinModule("sass:selector",
+		NewBuiltinFunction("nest($selectors...)", selectorNest),
+		NewBuiltinFunction("append($selectors...)", selectorAppend),
+		NewBuiltinFunction("extend($selector, $extendee, $extender)", selectorExtend),
+		NewBuiltinFunction("replace($selector, $original, $replacement)", selectorReplace),
+		NewBuiltinFunction("unify($selector1, $selector2)", selectorUnify),
+		NewBuiltinFunction("is-superselector($super, $sub)", selectorIsSuperselector),
+		NewBuiltinFunction("simple-selectors($selector)", selectorSimpleSelectors),
+		NewBuiltinFunction("parse($selector)", selectorParse),
+	)
+
+	builtinModules["sass:selector"] = module
+
+	registerGlobalFunction(module, "nest", "selector-nest")
+	registerGlobalFunction(module, "append", "selector-append")
+	registerGlobalFunction(module, "extend", "selector-extend")
+	registerGlobalFunction(module, "replace", "selector-replace")
+	registerGlobalFunction(module, "unify", "selector-unify")
+	registerGlobalFunction(module, "is-superselector", "is-superselector")
+	registerGlobalFunction(module, "simple-selectors", "simple-selectors")
+	registerGlobalFunction(module, "parse", "selector-parse")
+}
+
+/*
+selectorValue returns the selectors as a sass value, which is a comma
+separated list of the complex selectors. Each complex selector is a space
+separated list of its compound selectors and combinators:
+
+	.a > .b, .c => ((".a", ">", ".b"), (".c"))
+
+A nil list is null, e.g. the value of `&` outside of style rules.
+*/
+func selectorValue(list *ast.ComplexSelectorList) ast.Value {
+	if list == nil {
+		return ast.NewNullWithToken(nil)
+	}
+
+	out := ast.NewCommaSepList()
+
+	for _, sel := range *list {
+		complex := ast.NewSpaceSepList()
+
+		for _, item := range sel.ComplexSelectorItems {
+			if !isDescendantCombinator(item.Combinator) {
+				complex.Append(ast.NewString(0, strings.TrimSpace(item.Combinator.String()), nil))
+			}
+
+			if item.CompoundSelector != nil {
+				complex.Append(ast.NewString(0, item.CompoundSelector.String(), nil))
+			}
+		}
+
+		out.Append(complex)
+	}
+
+	return out
+}
+
+// selectorText returns the text of a selector given as a string, a list
+// of strings or a comma separated list of lists of strings
+func selectorText(v ast.Value, nested bool) (string, bool) {
+	switch t := v.(type) {
+	case *ast.String:
+		return t.Value, true
+	case *ast.List:
+		if t.Separator == ", " && nested {
+			return "", false
+		}
+
+		var parts []string
+
+		for _, item := range t.Exprs {
+			if t.Separator == ", " {
+				str, ok := selectorText(item, true)
+
+				if !ok {
+					return "", false
+				}
+
+				parts = append(parts, str)
+				continue
+			}
+
+			str, ok := item.(*ast.String)
+
+			if !ok {
+				return "", false
+			}
+
+			parts = append(parts, str.Value)
+		}
+
+		return strings.Join(parts, t.Separator), true
+	}
+
+	return "", false
+}
+
+// parseSelectorList parses the text as the selectors of a style rule
+func parseSelectorList(text string) (*ast.ComplexSelectorList, bool) {
+	stmts, err := parser.NewParser(nil).ParseScss(text + " {}")
+
+	if err != nil || len(stmts.Stmts) != 1 {
+		return nil, false
+	}
+
+	rs, ok := stmts.Stmts[0].(*ast.RuleSet)
+
+	if !ok {
+		return nil, false
+	}
+
+	return rs.Selectors, true
+}
+
+// evaluateSelectorTemplate evaluates the interpolation in the selector and
+// parses the result
+func evaluateSelectorTemplate(tmpl ast.Expr, scope *Scope) (*ast.ComplexSelectorList, error) {
+	val, err := EvaluateExpr(tmpl, scope)
+
+	if err != nil {
+		return nil, err
+	}
+
+	selectors, ok := parseSelectorList(interpolate(val, scope.Precision))
+
+	if !ok || selectors == nil {
+		return nil, fmt.Errorf("Expected selector.")
+	}
+
+	return selectors, nil
+}
+
+func selectorTypeError(name string, v ast.Value) error {
+	return fmt.Errorf("%s: %s is not a valid selector: it must be a string,\na list of strings, or a list of lists of strings.", name, v)
+}
+
+// selectorArgument parses the value given as the named argument
+func selectorArgument(name string, v ast.Value) (*ast.ComplexSelectorList, error) {
+	text, ok := selectorText(v, false)
+
+	if !ok {
+		return nil, selectorTypeError(name, v)
+	}
+
+	list, ok := parseSelectorList(text)
+
+	if !ok {
+		return nil, fmt.Errorf("%s: expected selector.", name)
+	}
+
+	return list, nil
+}
+
+// Selector returns the argument parsed as a selector list
+func (args *BuiltinArguments) Selector(idx int) (*ast.ComplexSelectorList, error) {
+	return selectorArgument(args.Names[idx], args.Values[idx])
+}
+
+/*
+splitSelectorText splits the text of a selector list on the commas that
+are not nested in parentheses or brackets:
+
+	.a, :is(.b, .c) => .a and :is(.b, .c)
+*/
+func splitSelectorText(text string) []string {
+	var parts []string
+	var quote rune
+	depth, start := 0, 0
+
+	for idx, r := range text {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			depth--
+		case r == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(text[start:idx]))
+			start = idx + 1
+		}
+	}
+
+	return append(parts, strings.TrimSpace(text[start:]))
+}
+
+func selectorNest(args *BuiltinArguments) (ast.Value, error) {
+	items := args.List(0)
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("$selectors: At least one selector must be passed.")
+	}
+
+	result, err := selectorArgument("$selectors", items[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if hasParentSelector(result) {
+		return nil, fmt.Errorf("Parent selectors aren't allowed here.")
+	}
+
+	for _, item := range items[1:] {
+		child, err := selectorArgument("$selectors", item)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if result, err = joinSelectorLists(result, child); err != nil {
+			return nil, err
+		}
+	}
+
+	return selectorValue(result), nil
+}
+
+/*
+selectorAppend appends the selectors without any descendant combinator,
+every child selector is resolved as if it started with `&`:
+
+	selector.append(".a", "--active") => .a--active
+	selector.append(".a, .b", ":hover") => .a:hover, .b:hover
+*/
+func selectorAppend(args *BuiltinArguments) (ast.Value, error) {
+	items := args.List(0)
+
+	if len(items) == 0 {
+		return nil, fmt.Errorf("$selectors: At least one selector must be passed.")
+	}
+
+	result, err := selectorArgument("$selectors", items[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items[1:] {
+		text, ok := selectorText(item, false)
+
+		if !ok {
+			return nil, selectorTypeError("$selectors", item)
+		}
+
+		var children []string
+
+		for _, part := range splitSelectorText(text) {
+			if part == "" || strings.ContainsRune(">+~", rune(part[0])) {
+				return nil, fmt.Errorf("Can't append %s to %s.", part, result)
+			}
+
+			children = append(children, "&"+part)
+		}
+
+		child, ok := parseSelectorList(strings.Join(children, ", "))
+
+		if !ok {
+			return nil, fmt.Errorf("$selectors: expected selector.")
+		}
+
+		if result, err = joinSelectorLists(result, child); err != nil {
+			return nil, err
+		}
+	}
+
+	return selectorValue(result), nil
+}
+
+// selectorExtender returns the extender for the extensions of the targets
+// by the extender selectors, like `@extend` would do
+func selectorExtender(args *BuiltinArguments) (*ast.ComplexSelectorList, *Extender, error) {
+	var lists [3]*ast.ComplexSelectorList
+
+	for idx := range lists {
+		list, err := args.Selector(idx)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		lists[idx] = list
+	}
+
+	e := &Extender{}
+
+	if err := e.add(lists[2], &ast.ExtendStmt{Selectors: lists[1]}, ""); err != nil {
+		return nil, nil, err
+	}
+
+	return lists[0], e, nil
+}
+
+func selectorExtend(args *BuiltinArguments) (ast.Value, error) {
+	selectors, e, err := selectorExtender(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := e.extendSelectorList(selectors, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return selectorValue(out), nil
+}
+
+// selectorReplace is like selectorExtend, but the selectors which have
+// been extended are replaced by the extended ones
+func selectorReplace(args *BuiltinArguments) (ast.Value, error) {
+	selectors, e, err := selectorExtender(args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ast.ComplexSelectorList{}
+	seen := map[string]struct{}{}
+
+	add := func(sel *ast.ComplexSelector) {
+		if _, ok := seen[sel.String()]; !ok {
+			seen[sel.String()] = struct{}{}
+			out.Append(sel)
+		}
+	}
+
+	for _, sel := range *selectors {
+		replaced := false
+
+		for _, ext := range e.Extensions {
+			extended, matched := extendComplexSelector(sel, ext)
+
+			if !matched {
+				continue
+			}
+
+			replaced = true
+
+			for _, n := range extended {
+				add(n)
+			}
+		}
+
+		if !replaced {
+			add(sel)
+		}
+	}
+
+	return selectorValue(out), nil
+}
+
+/*
+selectorUnify returns the selectors matching the elements matched by both
+selectors, null is returned when there is no such selector:
+
+	selector.unify(".a .b", ".c") => .a .b.c
+	selector.unify("a", "span") => null
+*/
+func selectorUnify(args *BuiltinArguments) (ast.Value, error) {
+	list1, err := args.Selector(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list2, err := args.Selector(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	out := &ast.ComplexSelectorList{}
+
+	for _, a := range *list1 {
+		for _, b := range *list2 {
+			itemsA, itemsB := a.ComplexSelectorItems, b.ComplexSelectorItems
+			lastA, lastB := itemsA[len(itemsA)-1], itemsB[len(itemsB)-1]
+
+			if lastA.CompoundSelector == nil || lastB.CompoundSelector == nil {
+				continue
+			}
+
+			unified := unifyCompound(*lastB.CompoundSelector, *lastA.CompoundSelector)
+
+			if unified == nil {
+				continue
+			}
+
+			target := &ast.ComplexSelectorItem{CompoundSelector: unified}
+
+			for _, woven := range weave(itemsA, itemsB, target) {
+				out.Append(&ast.ComplexSelector{ComplexSelectorItems: woven})
+			}
+		}
+	}
+
+	if len(*out) == 0 {
+		return ast.NewNullWithToken(nil), nil
+	}
+
+	return selectorValue(out), nil
+}
+
+// compoundIsSuperselector tells whether every element matched by the
+// compound selector b is matched by a as well
+func compoundIsSuperselector(a, b *ast.ComplexSelectorItem) bool {
+	if a.CompoundSelector == nil || b.CompoundSelector == nil {
+		return false
+	}
+
+	for _, sel := range *a.CompoundSelector {
+		if _, ok := sel.(*ast.UniversalSelector); ok {
+			continue
+		}
+
+		if !compoundContains(*b.CompoundSelector, sel) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/*
+complexIsSuperselector tells whether every element matched by the complex
+selector sub is matched by super as well, the compound selectors of super
+are matched from the last one:
+
+	.a .c is a superselector of .a > .b .c
+*/
+func complexIsSuperselector(super, sub *ast.ComplexSelector) bool {
+	a, b := super.ComplexSelectorItems, sub.ComplexSelectorItems
+	i, j := len(a)-1, len(b)-1
+
+	if !compoundIsSuperselector(a[i], b[j]) {
+		return false
+	}
+
+	for i > 0 {
+		comb := a[i].Combinator
+		i--
+
+		if isDescendantCombinator(comb) {
+			// any ancestor could match
+			for j--; j >= 0 && !compoundIsSuperselector(a[i], b[j]); j-- {
+			}
+
+			if j < 0 {
+				return false
+			}
+
+			continue
+		}
+
+		if j == 0 || b[j].Combinator == nil || b[j].Combinator.String() != comb.String() {
+			return false
+		}
+
+		if j--; !compoundIsSuperselector(a[i], b[j]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func selectorIsSuperselector(args *BuiltinArguments) (ast.Value, error) {
+	super, err := args.Selector(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := args.Selector(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range *sub {
+		matched := false
+
+		for _, a := range *super {
+			if complexIsSuperselector(a, b) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return ast.NewBoolean(false), nil
+		}
+	}
+
+	return ast.NewBoolean(true), nil
+}
+
+func selectorSimpleSelectors(args *BuiltinArguments) (ast.Value, error) {
+	list, err := args.Selector(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := (*list)[0].ComplexSelectorItems
+
+	if len(*list) != 1 || len(items) != 1 || items[0].Combinator != nil || items[0].CompoundSelector == nil {
+		return nil, fmt.Errorf("$selector: %s is not a compound selector.", list)
+	}
+
+	out := ast.NewCommaSepList()
+
+	for _, sel := range *items[0].CompoundSelector {
+		out.Append(ast.NewString(0, sel.String(), nil))
+	}
+
+	return out, nil
+}
+
+func selectorParse(args *BuiltinArguments) (ast.Value, error) {
+	list, err := args.Selector(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return selectorValue(list), nil
+}
@@ -0,0 +1,25 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/c9s/c6/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSelectorText(t *testing.T) {
+	assert.Equal(t, []string{".a", ".b"}, splitSelectorText(".a, .b"))
+	assert.Equal(t, []string{".a", ":is(.b, .c)"}, splitSelectorText(".a,:is(.b, .c)"))
+	assert.Equal(t, []string{`[title="a, b"]`}, splitSelectorText(`[title="a, b"]`))
+}
+
+func TestSelectorValue(t *testing.T) {
+	list, ok := parseSelectorList(".a > .b, .c")
+	assert.True(t, ok)
+
+	val := selectorValue(list).(*ast.List)
+	assert.Equal(t, ".a > .b, .c", inspect(val))
+	assert.Len(t, val.Exprs, 2)
+	assert.Len(t, val.Exprs[0].(*ast.List).Exprs, 3)
+	assert.Equal(t, "null", inspect(selectorValue(nil)))
+}
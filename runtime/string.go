@@ -0,0 +1,266 @@
+package runtime
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/c9s/c6/ast"
+)
+
+func init() {
+	module := NewBuiltinModule("sass:string",
+		NewBuiltinFunction("quote($string)", stringQuote),
+		NewBuiltinFunction("unquote($string)", stringUnquote),
+		NewBuiltinFunction("length($string)", stringLength),
+		NewBuiltinFunction("index($string, $substring)", stringIndex),
+		NewBuiltinFunction("insert($string, $insert, $index)", stringInsert),
+		NewBuiltinFunction("slice($string, $start-at, $end-at: -1)", stringSlice),
+		NewBuiltinFunction("to-upper-case($string)", stringCase('a', 'z', 'A'-'a')),
+		NewBuiltinFunction("to-lower-case($string)", stringCase('A', 'Z', 'a'-'A')),
+		NewBuiltinFunction("unique-id()", stringUniqueId),
+		NewBuiltinFunction("split($string, $separator, $limit: null)", stringSplit),
+	)
+
+	builtinModules["sass:string"] = module
+
+	registerGlobalFunction(module, "quote", "quote")
+	registerGlobalFunction(module, "unquote", "unquote")
+	registerGlobalFunction(module, "length", "str-length")
+	registerGlobalFunction(module, "index", "str-index")
+	registerGlobalFunction(module, "insert", "str-insert")
+	registerGlobalFunction(module, "slice", "str-slice")
+	registerGlobalFunction(module, "to-upper-case", "to-upper-case")
+	registerGlobalFunction(module, "to-lower-case", "to-lower-case")
+	registerGlobalFunction(module, "unique-id", "unique-id")
+}
+
+/*
+codePointIndex converts a sass string index to the offset of a code point,
+sass indexes start at 1 and the negative ones count from the end:
+
+	"abcd", 1 => 0
+	"abcd", -1 => 3
+
+The offset is clamped to the length of the string, negative offsets are
+clamped to 0 unless allowNegative is set.
+*/
+func codePointIndex(idx, length int, allowNegative bool) int {
+	if idx == 0 {
+		return 0
+	}
+
+	if idx > 0 {
+		return min(idx-1, length)
+	}
+
+	offset := length + idx
+
+	if offset < 0 && !allowNegative {
+		return 0
+	}
+
+	return offset
+}
+
+func stringQuote(args *BuiltinArguments) (ast.Value, error) {
+	str, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if str.Quote != 0 {
+		return str, nil
+	}
+
+	return ast.NewString('"', str.Value, nil), nil
+}
+
+func stringUnquote(args *BuiltinArguments) (ast.Value, error) {
+	str, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewString(0, str.Value, nil), nil
+}
+
+func stringLength(args *BuiltinArguments) (ast.Value, error) {
+	str, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.NewNumber(float64(utf8.RuneCountInString(str.Value)), nil, nil), nil
+}
+
+func stringIndex(args *BuiltinArguments) (ast.Value, error) {
+	str, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	substr, err := args.String(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	offset := strings.Index(str.Value, substr.Value)
+
+	if offset < 0 {
+		return ast.NewNullWithToken(nil), nil
+	}
+
+	return ast.NewNumber(float64(utf8.RuneCountInString(str.Value[:offset])+1), nil, nil), nil
+}
+
+func stringInsert(args *BuiltinArguments) (ast.Value, error) {
+	str, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	insert, err := args.String(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := args.Int(2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(str.Value)
+
+	// -1 inserts after the last character, not before it
+	if idx < 0 {
+		idx = len(runes) + idx + 2
+	}
+
+	offset := codePointIndex(idx, len(runes), false)
+	value := string(runes[:offset]) + insert.Value + string(runes[offset:])
+
+	return ast.NewString(str.Quote, value, nil), nil
+}
+
+func stringSlice(args *BuiltinArguments) (ast.Value, error) {
+	str, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := args.Int(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := args.Int(2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(str.Value)
+
+	if end == 0 {
+		return ast.NewString(str.Quote, "", nil), nil
+	}
+
+	from := codePointIndex(start, len(runes), false)
+	to := codePointIndex(end, len(runes), true)
+
+	if to == len(runes) {
+		to--
+	}
+
+	if to < from {
+		return ast.NewString(str.Quote, "", nil), nil
+	}
+
+	return ast.NewString(str.Quote, string(runes[from:to+1]), nil), nil
+}
+
+// stringCase returns the builtin function shifting the ASCII letters
+// between from and to, sass leaves the other letters as they are
+func stringCase(from, to, shift rune) BuiltinFunc {
+	return func(args *BuiltinArguments) (ast.Value, error) {
+		str, err := args.String(0)
+
+		if err != nil {
+			return nil, err
+		}
+
+		value := strings.Map(func(r rune) rune {
+			if r >= from && r <= to {
+				return r + shift
+			}
+
+			return r
+		}, str.Value)
+
+		return ast.NewString(str.Quote, value, nil), nil
+	}
+}
+
+var lastUniqueId = rand.Int63n(36 * 36 * 36 * 36 * 36)
+
+// stringUniqueId returns an identifier that is unique within the
+// compilation, e.g. `u0a3x9f`
+func stringUniqueId(args *BuiltinArguments) (ast.Value, error) {
+	lastUniqueId += rand.Int63n(36) + 1
+	id := strconv.FormatInt(lastUniqueId, 36)
+
+	return ast.NewString(0, fmt.Sprintf("u%06s", id), nil), nil
+}
+
+// stringSplit returns the parts of the string in a bracketed comma
+// separated list, e.g. `["a", "b"]`
+func stringSplit(args *BuiltinArguments) (ast.Value, error) {
+	str, err := args.String(0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	separator, err := args.String(1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	limit := -1
+
+	if !args.IsNull(2) {
+		if limit, err = args.Int(2); err != nil {
+			return nil, err
+		}
+
+		if limit < 1 {
+			return nil, fmt.Errorf("$limit: Must be 1 or greater, was %d.", limit)
+		}
+
+		// strings.SplitN counts the parts, the limit counts the splits
+		limit++
+	}
+
+	list := ast.NewCommaSepList()
+	list.Bracketed = true
+
+	for _, part := range strings.SplitN(str.Value, separator.Value, limit) {
+		list.Append(ast.NewString(str.Quote, part, nil))
+	}
+
+	return list, nil
+}
@@ -0,0 +1,27 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodePointIndex(t *testing.T) {
+	var data = []struct {
+		idx, length   int
+		allowNegative bool
+		expected      int
+	}{
+		{1, 4, false, 0},
+		{4, 4, false, 3},
+		{10, 4, false, 4},
+		{0, 4, false, 0},
+		{-1, 4, false, 3},
+		{-10, 4, false, 0},
+		{-10, 4, true, -6},
+	}
+
+	for _, d := range data {
+		assert.Equal(t, d.expected, codePointIndex(d.idx, d.length, d.allowNegative))
+	}
+}